@@ -15,11 +15,18 @@ var (
 	ErrInvalidEmail    = errors.New("invalid email address")
 )
 
+// roles carried in the access token; staff can use the admin endpoints of the other services
+const (
+	RoleCustomer = "customer"
+	RoleStaff    = "staff"
+)
+
 type User struct {
 	ID        int64     `db:"id" json:"id"`
 	Name      string    `db:"name" json:"name"`
 	Email     string    `db:"email" json:"email"`
 	Password  string    `db:"password" json:"-"`
+	Role      string    `db:"role" json:"role"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
@@ -36,6 +43,7 @@ func NewUser(id int64, name, email, password string, createdAt time.Time) (*User
 		Name:      name,
 		Email:     email,
 		Password:  string(hashed),
+		Role:      RoleCustomer,
 		CreatedAt: createdAt,
 	}
 	err = u.Validate()
@@ -37,7 +37,7 @@ func (s *AuthServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginR
 		return nil, err
 	}
 
-	accessToken, err := webserver.MakeAccessToken(user.ID, user.Email, s.cfg.AccessTokenTTL, s.cfg.JWTSecret)
+	accessToken, err := webserver.MakeAccessToken(user.ID, user.Email, user.Role, s.cfg.AccessTokenTTL, s.cfg.JWTSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 type UserRepositoryInterface interface {
 	Create(user entity.User) (int64, error)
 	GetByEmail(email string) (*entity.User, error)
+	GetByID(id int64) (*entity.User, error)
 }
 
 type UserRepository struct {
@@ -25,7 +26,7 @@ func (ur *UserRepository) Create(user entity.User) (int64, error) {
 
 	var id int64
 
-	err := ur.DB.QueryRow("INSERT INTO users ( name,email, password) VALUES ($1,$2,$3) RETURNING id", user.Name, user.Email, user.Password).Scan(&id)
+	err := ur.DB.QueryRow("INSERT INTO users ( name,email, password, role) VALUES ($1,$2,$3,$4) RETURNING id", user.Name, user.Email, user.Password, user.Role).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -37,7 +38,7 @@ func (ur *UserRepository) Create(user entity.User) (int64, error) {
 func (ur *UserRepository) GetByEmail(email string) (*entity.User, error) {
 
 	var user entity.User
-	err := ur.DB.Get(&user, "select id,name,password,created_at from users where email = $1", email)
+	err := ur.DB.Get(&user, "select id,name,email,password,role,created_at from users where email = $1", email)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -45,3 +46,16 @@ func (ur *UserRepository) GetByEmail(email string) (*entity.User, error) {
 	}
 	return &user, nil
 }
+
+func (ur *UserRepository) GetByID(id int64) (*entity.User, error) {
+
+	var user entity.User
+	err := ur.DB.Get(&user, "select id,name,email,password,role,created_at from users where id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -22,6 +22,7 @@ func migrateDB() (*sqlx.DB, error) {
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL UNIQUE,
     password TEXT NOT NULL,
+    role VARCHAR(255) NOT NULL DEFAULT 'customer',
     created_at DATETIME
 );`)
 
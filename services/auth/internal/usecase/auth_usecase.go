@@ -72,3 +72,15 @@ func (uc *AuthUseCase) Signup(input SignupInput) (int64, error) {
 
 	return id, nil
 }
+
+func (uc *AuthUseCase) GetUser(id int64) (*entity.User, error) {
+
+	user, err := uc.UserRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, entity.ErrInvalidCredentials
+	}
+	return user, nil
+}
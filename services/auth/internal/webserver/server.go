@@ -154,7 +154,7 @@ func (s *Server) tokenHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// generate tokens
-		access, err := MakeAccessToken(user.ID, user.Email, s.cfg.AccessTokenTTL, s.cfg.JWTSecret)
+		access, err := MakeAccessToken(user.ID, user.Email, user.Role, s.cfg.AccessTokenTTL, s.cfg.JWTSecret)
 		if err != nil {
 			http.Error(w, "failed to create access token", http.StatusInternalServerError)
 			return
@@ -198,8 +198,14 @@ func (s *Server) tokenHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "invalid token", http.StatusInternalServerError)
 			return
 		}
+		// reload the user so the new token carries its current email and role
+		user, err := s.authUseCase.GetUser(uid)
+		if err != nil {
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+			return
+		}
 		// create new access token
-		access, err := MakeAccessToken(uid, "", s.cfg.AccessTokenTTL, s.cfg.JWTSecret)
+		access, err := MakeAccessToken(user.ID, user.Email, user.Role, s.cfg.AccessTokenTTL, s.cfg.JWTSecret)
 		if err != nil {
 			http.Error(w, "failed to create access token", http.StatusInternalServerError)
 			return
@@ -273,13 +279,14 @@ func (s *Server) jwtMiddleware(next http.Handler) http.Handler {
 }
 
 // ---------------- Helpers: JWT and refresh token ----------------
-func MakeAccessToken(userID int64, email string, accessTokenTTL time.Duration, jwtSecret string) (string, error) {
+func MakeAccessToken(userID int64, email, role string, accessTokenTTL time.Duration, jwtSecret string) (string, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":   fmt.Sprintf("%d", userID),
 		"iat":   now.Unix(),
 		"exp":   now.Add(accessTokenTTL).Unix(),
 		"email": email,
+		"role":  role,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString([]byte(jwtSecret))
@@ -3,5 +3,6 @@ CREATE TABLE IF NOT EXISTS users(
     name TEXT NOT NULL,
     email TEXT NOT NULL UNIQUE,
     password TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'customer',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
 );
\ No newline at end of file
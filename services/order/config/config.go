@@ -11,4 +11,13 @@ type Config struct {
 	RefreshTokenTTL time.Duration
 	GRPCServerPort  string
 	KafkaAddr       string
+	ProductAddr     string
+	// OutboxRelayInterval is how often the outbox is checked for events to publish
+	OutboxRelayInterval time.Duration
+	// CheckoutTimeout is how long a checkout may take before it is given up and compensated
+	CheckoutTimeout time.Duration
+	// CheckoutRecoveryInterval is how often checkouts left unfinished are looked for
+	CheckoutRecoveryInterval time.Duration
+	// PaymentWebhookSecret signs the webhooks of the payment gateway
+	PaymentWebhookSecret string
 }
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceToken(t *testing.T) {
+
+	tok, err := ServiceToken("secret", "order-service")(context.Background())
+	assert.Nil(t, err)
+
+	p, msg := ParseBearer("secret", "Bearer "+tok)
+	assert.Empty(t, msg)
+	assert.Equal(t, Principal{Email: "order-service", Role: RoleService}, p)
+	assert.False(t, p.IsStaff())
+
+	_, msg = ParseBearer("other", "Bearer "+tok)
+	assert.Equal(t, "invalid token", msg)
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	RoleStaff = "staff"
+	// RoleService is the role of the tokens services sign with the shared secret to call on their own behalf
+	RoleService = "service"
+)
+
+// Principal is the caller identified by the access token issued by the auth service
+type Principal struct {
+	UserID int64
+	Email  string
+	Role   string
+}
+
+func (p Principal) IsStaff() bool {
+	return p.Role == RoleStaff
+}
+
+// ParseBearer reads an authorization value of the form "Bearer <token>", returning why it was rejected.
+// The HTTP API and the gRPC server share it so a token means the same on both.
+func ParseBearer(secret, auth string) (Principal, string) {
+
+	if auth == "" {
+		return Principal{}, "missing auth"
+	}
+	var tok string
+	fmt.Sscanf(auth, "Bearer %s", &tok)
+	if tok == "" {
+		return Principal{}, "invalid auth header"
+	}
+
+	claims := jwt.MapClaims{}
+	parsed, err := jwt.ParseWithClaims(tok, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !parsed.Valid {
+		return Principal{}, "invalid token"
+	}
+
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		return Principal{}, "invalid token subject"
+	}
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+
+	return Principal{UserID: userID, Email: email, Role: role}, ""
+}
@@ -0,0 +1,158 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidCancelReason     = errors.New("invalid cancel reason")
+	ErrCancelNotAllowed        = errors.New("order cannot be cancelled in its status")
+	ErrPartialCancelNotAllowed = errors.New("order lines can only be cancelled once the order is paid")
+	ErrOrderLineNotFound       = errors.New("order line not found")
+	ErrCancelQuantityTooHigh   = errors.New("cannot cancel more than what is left of the line")
+	ErrDuplicateCancelLine     = errors.New("line cancelled more than once")
+)
+
+type CancelReason string
+
+const (
+	CancelCustomerRequest CancelReason = "customer_request"
+	CancelOutOfStock      CancelReason = "out_of_stock"
+	CancelPaymentIssue    CancelReason = "payment_issue"
+	CancelFraudSuspected  CancelReason = "fraud_suspected"
+	CancelDuplicateOrder  CancelReason = "duplicate_order"
+	CancelOther           CancelReason = "other"
+)
+
+func (r CancelReason) Valid() bool {
+
+	switch r {
+	case CancelCustomerRequest, CancelOutOfStock, CancelPaymentIssue, CancelFraudSuspected, CancelDuplicateOrder, CancelOther:
+		return true
+	}
+	return false
+}
+
+// CancelLine is a quantity of an order line to cancel
+type CancelLine struct {
+	LineID    int64
+	ProductID int64
+	Quantity  int
+}
+
+// Cancellation cancels an order, all of it or some of its lines
+type Cancellation struct {
+	ID      int64
+	OrderID int64
+	Reason  CancelReason
+	Note    string
+	// From is the status of the order when it was cancelled
+	From OrderStatus
+	// Full tells whether nothing is left of the order
+	Full  bool
+	Lines []CancelLine
+	// Refund is what the customer gets back, nil when nothing was paid
+	Refund      *Refund
+	CancelledBy int64
+	CreatedAt   time.Time
+}
+
+// DuringCheckout tells whether the order was cancelled before its checkout completed; the checkout then
+// releases the stock and voids the payment itself
+func (c *Cancellation) DuringCheckout() bool {
+	return c.From == OrderPending || c.From == OrderAwaitingPayment
+}
+
+// Cancel cancels lines of the order, or all that is left of it when lines is empty. Customers may cancel
+// until the order is paid, staff until it ships; lines can only be cancelled apart once the order is paid,
+// so by staff alone.
+// The order moves to cancelled, with the status change returned, when nothing is left of it.
+func (o *Order) Cancel(reason CancelReason, note string, lines []CancelLine, by int64, staff bool, now time.Time) (*Cancellation, *StatusChange, error) {
+
+	if !reason.Valid() {
+		return nil, nil, ErrInvalidCancelReason
+	}
+	if len(note) > maxReasonLength {
+		return nil, nil, ErrReasonTooLong
+	}
+	paid := o.Status == OrderPaid || o.Status == OrderFulfilling
+	if !o.Status.CanMoveTo(OrderCancelled) || (paid && !staff) {
+		return nil, nil, ErrCancelNotAllowed
+	}
+	if len(lines) > 0 && !paid {
+		return nil, nil, ErrPartialCancelNotAllowed
+	}
+
+	if len(lines) == 0 {
+		for _, l := range o.Lines {
+			if l.Remaining() > 0 {
+				lines = append(lines, CancelLine{LineID: l.ID, Quantity: l.Remaining()})
+			}
+		}
+	}
+
+	seen := make(map[int64]bool, len(lines))
+	for i := range lines {
+		cl := &lines[i]
+		l := o.line(cl.LineID)
+		if l == nil {
+			return nil, nil, ErrOrderLineNotFound
+		}
+		if seen[cl.LineID] {
+			return nil, nil, ErrDuplicateCancelLine
+		}
+		seen[cl.LineID] = true
+		if cl.Quantity <= 0 {
+			return nil, nil, ErrQuantityIsRequired
+		}
+		if cl.Quantity > l.Remaining() {
+			return nil, nil, ErrCancelQuantityTooHigh
+		}
+		cl.ProductID = l.ProductID
+	}
+
+	c := &Cancellation{OrderID: o.ID, Reason: reason, Note: note, From: o.Status, Lines: lines, CancelledBy: by, CreatedAt: now}
+
+	var goods int64
+	for _, cl := range lines {
+		l := o.line(cl.LineID)
+		l.CancelledQuantity += cl.Quantity
+		goods += l.UnitPrice.Amount * int64(cl.Quantity)
+	}
+	c.Full = true
+	for _, l := range o.Lines {
+		if l.Remaining() > 0 {
+			c.Full = false
+		}
+	}
+
+	if paid {
+		amount := o.refundFor(goods, c.Full)
+		o.RefundedTotal.Amount += amount.Amount
+		if !amount.IsZero() {
+			c.Refund = NewRefund(o.ID, amount, "cancellation", now, RefundLease)
+		}
+	}
+
+	var sc *StatusChange
+	if c.Full {
+		var err error
+		if sc, err = o.Transition(OrderCancelled, by, string(reason), now); err != nil {
+			return nil, nil, err
+		}
+	}
+	o.UpdatedAt = now
+
+	return c, sc, nil
+}
+
+func (o *Order) line(id int64) *OrderLine {
+
+	for i := range o.Lines {
+		if o.Lines[i].ID == id {
+			return &o.Lines[i]
+		}
+	}
+	return nil
+}
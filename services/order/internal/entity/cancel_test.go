@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// paidOrder is 2 x 10.00 and 1 x 30.00 with 10% tax and 5.00 shipping, 60.00 in total
+func paidOrder(t *testing.T) *Order {
+
+	a, _ := NewOrderLine(1, "A", 2, usd(1000), nil)
+	b, _ := NewOrderLine(2, "B", 1, usd(3000), nil)
+	a.ID, b.ID = 11, 12
+	policy := PricingPolicy{TaxRate: 1000, ShippingFees: map[string]money.Money{"USD": usd(500)}}
+
+	o, err := NewOrder(7, "USD", []OrderLine{a, b}, policy, time.Now())
+	assert.Nil(t, err)
+	o.ID, o.Status = 5, OrderPaid
+	assert.Equal(t, usd(6000), o.Total)
+	return o
+}
+
+func usd(amount int64) money.Money {
+	return money.Money{Amount: amount, Currency: "USD"}
+}
+
+func TestCancelLines(t *testing.T) {
+
+	o := paidOrder(t)
+	now := time.Now()
+
+	c, sc, err := o.Cancel(CancelOutOfStock, "", []CancelLine{{LineID: 11, Quantity: 1}}, 9, true, now)
+	assert.Nil(t, err)
+	assert.Nil(t, sc)
+	assert.False(t, c.Full)
+	assert.False(t, c.DuringCheckout())
+	assert.Equal(t, []CancelLine{{LineID: 11, ProductID: 1, Quantity: 1}}, c.Lines)
+	// 10.00 and its 1.00 of tax
+	assert.Equal(t, usd(1100), c.Refund.Amount)
+	assert.Equal(t, usd(1100), o.RefundedTotal)
+	assert.Equal(t, 1, o.Lines[0].Remaining())
+	assert.Equal(t, OrderPaid, o.Status)
+
+	_, _, err = o.Cancel(CancelOutOfStock, "", []CancelLine{{LineID: 11, Quantity: 2}}, 9, true, now)
+	assert.Equal(t, ErrCancelQuantityTooHigh, err)
+
+	// what is left, shipping included
+	c, sc, err = o.Cancel(CancelOutOfStock, "", nil, 9, true, now)
+	assert.Nil(t, err)
+	assert.True(t, c.Full)
+	assert.Equal(t, []CancelLine{{LineID: 11, ProductID: 1, Quantity: 1}, {LineID: 12, ProductID: 2, Quantity: 1}}, c.Lines)
+	assert.Equal(t, usd(4900), c.Refund.Amount)
+	assert.Equal(t, o.Total, o.RefundedTotal)
+	assert.Equal(t, &StatusChange{OrderID: 5, From: OrderPaid, To: OrderCancelled, ChangedBy: 9, Reason: "out_of_stock", ChangedAt: now}, sc)
+	assert.Equal(t, OrderCancelled, o.Status)
+
+	_, _, err = o.Cancel(CancelOther, "", nil, 9, true, now)
+	assert.Equal(t, ErrCancelNotAllowed, err)
+}
+
+func TestCancelRules(t *testing.T) {
+
+	now := time.Now()
+
+	o := paidOrder(t)
+	o.Status = OrderPending
+	_, _, err := o.Cancel(CancelCustomerRequest, "", []CancelLine{{LineID: 11, Quantity: 1}}, 7, false, now)
+	assert.Equal(t, ErrPartialCancelNotAllowed, err)
+
+	// nothing was paid yet
+	c, sc, err := o.Cancel(CancelDuplicateOrder, "", nil, 7, false, now)
+	assert.Nil(t, err)
+	assert.NotNil(t, sc)
+	assert.True(t, c.DuringCheckout())
+	assert.Nil(t, c.Refund)
+	assert.True(t, o.RefundedTotal.IsZero())
+
+	// once paid, only staff may cancel
+	o = paidOrder(t)
+	_, _, err = o.Cancel(CancelCustomerRequest, "", nil, 7, false, now)
+	assert.Equal(t, ErrCancelNotAllowed, err)
+	assert.True(t, o.RefundedTotal.IsZero())
+
+	o.Status = OrderFulfilling
+	_, _, err = o.Cancel(CancelCustomerRequest, "", nil, 7, false, now)
+	assert.Equal(t, ErrCancelNotAllowed, err)
+
+	for _, tc := range []struct {
+		reason CancelReason
+		lines  []CancelLine
+		err    error
+	}{
+		{"changed_mind", nil, ErrInvalidCancelReason},
+		{CancelOther, []CancelLine{{LineID: 99, Quantity: 1}}, ErrOrderLineNotFound},
+		{CancelOther, []CancelLine{{LineID: 11, Quantity: 0}}, ErrQuantityIsRequired},
+		{CancelOther, []CancelLine{{LineID: 11, Quantity: 1}, {LineID: 11, Quantity: 1}}, ErrDuplicateCancelLine},
+	} {
+		_, _, err := o.Cancel(tc.reason, "", tc.lines, 9, true, now)
+		assert.Equal(t, tc.err, err, tc.reason)
+	}
+
+	o.Status = OrderShipped
+	_, _, err = o.Cancel(CancelOther, "", nil, 9, true, now)
+	assert.Equal(t, ErrCancelNotAllowed, err)
+}
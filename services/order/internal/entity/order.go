@@ -1,40 +1,169 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
 
 var (
 	ErrProductIdIsRequired = errors.New("product id is required")
 	ErrQuantityIsRequired  = errors.New("quantity is required")
+	ErrNegativeTotal       = errors.New("total must not be negative")
+	ErrProductNotFound     = errors.New("product not found")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrUserIdIsRequired    = errors.New("user id is required")
+	ErrOrderNeedsLines     = errors.New("order needs at least one line")
+	ErrDuplicateLine       = errors.New("product ordered on more than one line")
+	ErrMixedCurrencies     = errors.New("order lines must share the order currency")
+	ErrOrderNotFound       = errors.New("order not found")
 )
 
+// Order is the header of an order placed by a customer; what was bought is in its lines.
+// Total is Subtotal - DiscountTotal + TaxTotal + ShippingTotal; RefundedTotal is what was given back of it.
 type Order struct {
-	ID        int64
-	ProductID int64
-	Quantity  int
-	Total     float64
+	ID            int64
+	UserID        int64
+	Status        OrderStatus
+	Currency      string
+	Subtotal      money.Money
+	DiscountTotal money.Money
+	TaxTotal      money.Money
+	ShippingTotal money.Money
+	Total         money.Money
+	RefundedTotal money.Money
+	Lines         []OrderLine
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
-func NewOrder(id int64, productID int64, qt int, total float64) (*Order, error) {
+// OrderLine is a product of an order, with its price as it was when the order was placed.
+// Discount is what the line saves against the regular price of the product, e.g. during a sale.
+// CancelledQuantity is how much of Quantity was cancelled since, ReturningQuantity how much the customer asked
+// to return and ReturnedQuantity how much came back.
+type OrderLine struct {
+	ID                int64
+	OrderID           int64
+	ProductID         int64
+	SKU               string
+	Quantity          int
+	CancelledQuantity int
+	ReturningQuantity int
+	ReturnedQuantity  int
+	UnitPrice         money.Money
+	Discount          money.Money
+	LineTotal         money.Money
+}
 
-	o := &Order{id, productID, qt, total}
+// NewOrderLine prices quantity units of a product at unitPrice; regularPrice is the price without a sale,
+// nil when the product is not on sale
+func NewOrderLine(productID int64, sku string, qt int, unitPrice money.Money, regularPrice *money.Money) (OrderLine, error) {
 
-	err := o.Validate()
-	if err != nil {
-		return nil, err
+	l := OrderLine{ProductID: productID, SKU: sku, Quantity: qt, UnitPrice: unitPrice, Discount: money.Money{Currency: unitPrice.Currency}}
+
+	if err := l.Validate(); err != nil {
+		return OrderLine{}, err
 	}
 
-	return o, nil
+	l.LineTotal = unitPrice.Mul(int64(qt))
+	if regularPrice != nil && regularPrice.Currency == unitPrice.Currency && regularPrice.Amount > unitPrice.Amount {
+		l.Discount = money.Money{Amount: (regularPrice.Amount - unitPrice.Amount) * int64(qt), Currency: unitPrice.Currency}
+	}
+	return l, nil
+}
 
+// Remaining is the quantity of the line that was not cancelled
+func (l *OrderLine) Remaining() int {
+	return l.Quantity - l.CancelledQuantity
 }
 
-func (o *Order) Validate() error {
+// Kept is the quantity of the line the customer has, neither cancelled nor returned
+func (l *OrderLine) Kept() int {
+	return l.Remaining() - l.ReturnedQuantity
+}
 
-	if o.ProductID == 0 {
+// Returnable is the quantity of the line the customer may still ask to return
+func (l *OrderLine) Returnable() int {
+	return l.Kept() - l.ReturningQuantity
+}
+
+func (l *OrderLine) Validate() error {
+
+	if l.ProductID == 0 {
 		return ErrProductIdIsRequired
 	}
-	if o.Quantity == 0 {
+	if l.Quantity <= 0 {
 		return ErrQuantityIsRequired
 	}
+	if err := l.UnitPrice.Validate(); err != nil {
+		return err
+	}
+	if l.UnitPrice.IsNegative() {
+		return ErrNegativeTotal
+	}
+	return nil
+}
+
+// NewOrder places a pending order of lines for a user and works out its totals with policy
+func NewOrder(userID int64, currency string, lines []OrderLine, policy PricingPolicy, now time.Time) (*Order, error) {
+
+	o := &Order{UserID: userID, Status: OrderPending, Currency: currency, Lines: lines, RefundedTotal: money.Money{Currency: currency},
+		CreatedAt: now, UpdatedAt: now}
+
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	var goods int64
+	o.DiscountTotal = money.Money{Currency: currency}
+	for _, l := range o.Lines {
+		goods += l.LineTotal.Amount
+		o.DiscountTotal.Amount += l.Discount.Amount
+	}
+	discounted := money.Money{Amount: goods, Currency: currency}
+
+	o.Subtotal = money.Money{Amount: goods + o.DiscountTotal.Amount, Currency: currency}
+	o.TaxTotal = policy.Tax(discounted)
+	o.ShippingTotal = policy.Shipping(discounted)
+	o.Total = money.Money{Amount: goods + o.TaxTotal.Amount + o.ShippingTotal.Amount, Currency: currency}
+
+	return o, nil
+}
+
+func (o *Order) Validate() error {
+
+	if o.UserID == 0 {
+		return ErrUserIdIsRequired
+	}
+	if len(o.Lines) == 0 {
+		return ErrOrderNeedsLines
+	}
+
+	seen := make(map[int64]bool, len(o.Lines))
+	for i := range o.Lines {
+		l := &o.Lines[i]
+		if err := l.Validate(); err != nil {
+			return err
+		}
+		if l.UnitPrice.Currency != o.Currency {
+			return ErrMixedCurrencies
+		}
+		if seen[l.ProductID] {
+			return ErrDuplicateLine
+		}
+		seen[l.ProductID] = true
+	}
 
 	return nil
 }
+
+// Quantities is how many units of each product the order holds
+func (o *Order) Quantities() map[int64]int64 {
+
+	q := make(map[int64]int64, len(o.Lines))
+	for _, l := range o.Lines {
+		q[l.ProductID] += int64(l.Quantity)
+	}
+	return q
+}
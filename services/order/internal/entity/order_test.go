@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOrder(t *testing.T) {
+
+	var policy PricingPolicy
+
+	mug, err := NewOrderLine(1, "MUG", 2, money.Money{Amount: 1000, Currency: "USD"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, money.Money{Amount: 2000, Currency: "USD"}, mug.LineTotal)
+
+	tea, _ := NewOrderLine(2, "TEA", 1, money.Money{Amount: 550, Currency: "USD"}, nil)
+
+	o, err := NewOrder(7, "USD", []OrderLine{mug, tea}, policy, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, OrderPending, o.Status)
+	assert.Equal(t, money.Money{Amount: 2550, Currency: "USD"}, o.Total)
+	assert.Equal(t, map[int64]int64{1: 2, 2: 1}, o.Quantities())
+
+	_, err = NewOrderLine(1, "MUG", 0, money.Money{Amount: 1000, Currency: "USD"}, nil)
+	assert.Equal(t, ErrQuantityIsRequired, err)
+
+	_, err = NewOrder(0, "USD", []OrderLine{mug}, policy, time.Now())
+	assert.Equal(t, ErrUserIdIsRequired, err)
+
+	_, err = NewOrder(7, "USD", nil, policy, time.Now())
+	assert.Equal(t, ErrOrderNeedsLines, err)
+
+	_, err = NewOrder(7, "USD", []OrderLine{mug, mug}, policy, time.Now())
+	assert.Equal(t, ErrDuplicateLine, err)
+
+	eur, _ := NewOrderLine(3, "CUP", 1, money.Money{Amount: 100, Currency: "EUR"}, nil)
+	_, err = NewOrder(7, "USD", []OrderLine{mug, eur}, policy, time.Now())
+	assert.Equal(t, ErrMixedCurrencies, err)
+}
+
+func TestOrderTotals(t *testing.T) {
+
+	policy := PricingPolicy{
+		TaxRate:          1000,
+		ShippingFees:     map[string]money.Money{"USD": {Amount: 500, Currency: "USD"}},
+		FreeShippingOver: map[string]money.Money{"USD": {Amount: 5000, Currency: "USD"}},
+	}
+	assert.Nil(t, policy.Validate())
+
+	// on sale at 8.00 instead of 10.00
+	mug, _ := NewOrderLine(1, "MUG", 2, money.Money{Amount: 800, Currency: "USD"}, &money.Money{Amount: 1000, Currency: "USD"})
+	assert.Equal(t, money.Money{Amount: 400, Currency: "USD"}, mug.Discount)
+
+	o, err := NewOrder(7, "USD", []OrderLine{mug}, policy, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2000), o.Subtotal.Amount)
+	assert.Equal(t, int64(400), o.DiscountTotal.Amount)
+	assert.Equal(t, int64(160), o.TaxTotal.Amount)
+	assert.Equal(t, int64(500), o.ShippingTotal.Amount)
+	assert.Equal(t, int64(2260), o.Total.Amount)
+
+	tv, _ := NewOrderLine(2, "TV", 1, money.Money{Amount: 5000, Currency: "USD"}, nil)
+	o, _ = NewOrder(7, "USD", []OrderLine{tv}, policy, time.Now())
+	assert.Zero(t, o.ShippingTotal.Amount)
+
+	// no fee for the currency
+	eur, _ := NewOrderLine(3, "CUP", 1, money.Money{Amount: 100, Currency: "EUR"}, nil)
+	o, _ = NewOrder(7, "EUR", []OrderLine{eur}, policy, time.Now())
+	assert.Equal(t, money.Money{Currency: "EUR"}, o.ShippingTotal)
+
+	assert.Equal(t, ErrInvalidTaxRate, PricingPolicy{TaxRate: 10001}.Validate())
+}
+
+func TestOrderCursor(t *testing.T) {
+
+	c := OrderCursor{CreatedAt: time.Date(2025, 3, 1, 10, 30, 0, 123456000, time.UTC), ID: 42}
+
+	parsed, err := ParseCursor(c.String())
+	assert.Nil(t, err)
+	assert.Equal(t, c, *parsed)
+
+	for _, token := range []string{"", "not base64!", "MTIz"} {
+		_, err = ParseCursor(token)
+		assert.Equal(t, ErrInvalidPageToken, err, token)
+	}
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+const (
+	outboxFirstRetry = time.Second
+	outboxMaxRetry   = 5 * time.Minute
+)
+
+// OutboxMessage is an event stored with the change it announces, in the same transaction, and relayed
+// to Kafka afterwards; a change is never saved without its event nor announced without being saved
+type OutboxMessage struct {
+	ID int64
+	// Key is the Kafka message key, the order id: the events of an order are relayed in order
+	Key       string
+	EventType string
+	Value     []byte
+	// Attempts counts the relays tried so far
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// RetryDelay is how long to wait before relaying a message again after its attempts-th failure,
+// doubling from a second up to five minutes
+func RetryDelay(attempts int) time.Duration {
+
+	d := outboxFirstRetry
+	for i := 1; i < attempts && d < outboxMaxRetry; i++ {
+		d *= 2
+	}
+	return min(d, outboxMaxRetry)
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+
+	assert.Equal(t, time.Second, RetryDelay(1))
+	assert.Equal(t, 2*time.Second, RetryDelay(2))
+	assert.Equal(t, 64*time.Second, RetryDelay(7))
+	assert.Equal(t, 256*time.Second, RetryDelay(9))
+	assert.Equal(t, 5*time.Minute, RetryDelay(10))
+	assert.Equal(t, 5*time.Minute, RetryDelay(1000))
+}
@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var (
+	ErrPaymentMethodRequired = errors.New("payment method is required")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrPaymentChanged        = errors.New("payment changed meanwhile, try again")
+	ErrInvalidPaymentState   = errors.New("payment cannot do that in its status")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds what is left of the captured amount")
+)
+
+type PaymentStatus string
+
+const (
+	// PaymentPending is an intent not sent to the gateway yet
+	PaymentPending PaymentStatus = "pending"
+	// PaymentRequiresAction waits for the customer, e.g. for 3-D Secure; the gateway tells how it ended
+	// through a webhook
+	PaymentRequiresAction PaymentStatus = "requires_action"
+	PaymentAuthorized     PaymentStatus = "authorized"
+	PaymentCaptured       PaymentStatus = "captured"
+	PaymentVoided         PaymentStatus = "voided"
+	PaymentFailed         PaymentStatus = "failed"
+	// PaymentRefunded is a captured payment given back in full
+	PaymentRefunded PaymentStatus = "refunded"
+)
+
+// PaymentIntent is the payment of an order through a gateway: authorized at checkout, captured when the
+// order is confirmed and refunded, in part or in full, afterwards. Version counts the updates, so
+// concurrent ones are told apart.
+type PaymentIntent struct {
+	ID      int64
+	OrderID int64
+	Gateway string
+	// Reference identifies the payment to the gateway, once it was sent there
+	Reference string
+	// PaymentMethod is the token of the card or wallet to charge, as issued by the gateway
+	PaymentMethod  string
+	Status         PaymentStatus
+	Amount         money.Money
+	CapturedAmount money.Money
+	RefundedAmount money.Money
+	// NextActionURL is where the customer completes a payment that requires action
+	NextActionURL string
+	FailureReason string
+	Version       int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func NewPaymentIntent(orderID int64, gateway, paymentMethod string, amount money.Money, now time.Time) (*PaymentIntent, error) {
+
+	if paymentMethod == "" {
+		return nil, ErrPaymentMethodRequired
+	}
+	zero := money.Money{Currency: amount.Currency}
+	return &PaymentIntent{
+		OrderID:        orderID,
+		Gateway:        gateway,
+		PaymentMethod:  paymentMethod,
+		Status:         PaymentPending,
+		Amount:         amount,
+		CapturedAmount: zero,
+		RefundedAmount: zero,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// Key identifies the intent to the gateway, so a retried authorization holds the money once
+func (p *PaymentIntent) Key() string {
+	return fmt.Sprintf("intent-%d", p.ID)
+}
+
+// move changes the status of p to to, provided it is one of from
+func (p *PaymentIntent) move(to PaymentStatus, now time.Time, from ...PaymentStatus) error {
+
+	for _, s := range from {
+		if p.Status == s {
+			p.Status, p.UpdatedAt = to, now
+			return nil
+		}
+	}
+	return ErrInvalidPaymentState
+}
+
+// RequireAction records that the gateway waits for the customer at url
+func (p *PaymentIntent) RequireAction(reference, url string, now time.Time) error {
+
+	if err := p.move(PaymentRequiresAction, now, PaymentPending); err != nil {
+		return err
+	}
+	p.Reference, p.NextActionURL = reference, url
+	return nil
+}
+
+func (p *PaymentIntent) Authorize(reference string, now time.Time) error {
+
+	if err := p.move(PaymentAuthorized, now, PaymentPending, PaymentRequiresAction); err != nil {
+		return err
+	}
+	p.Reference, p.NextActionURL = reference, ""
+	return nil
+}
+
+// Fail records that the gateway refused the payment
+func (p *PaymentIntent) Fail(reason string, now time.Time) error {
+
+	if err := p.move(PaymentFailed, now, PaymentPending, PaymentRequiresAction); err != nil {
+		return err
+	}
+	p.FailureReason, p.NextActionURL = reason, ""
+	return nil
+}
+
+func (p *PaymentIntent) Capture(now time.Time) error {
+
+	if err := p.move(PaymentCaptured, now, PaymentAuthorized); err != nil {
+		return err
+	}
+	p.CapturedAmount = p.Amount
+	return nil
+}
+
+// Void releases the money held without taking it
+func (p *PaymentIntent) Void(now time.Time) error {
+	return p.move(PaymentVoided, now, PaymentAuthorized, PaymentRequiresAction)
+}
+
+// Refundable is what is left to refund of the captured amount
+func (p *PaymentIntent) Refundable() money.Money {
+	return money.Money{Amount: p.CapturedAmount.Amount - p.RefundedAmount.Amount, Currency: p.Amount.Currency}
+}
+
+// Refund records that amount of the captured money was given back
+func (p *PaymentIntent) Refund(amount money.Money, now time.Time) error {
+
+	if p.Status != PaymentCaptured {
+		return ErrInvalidPaymentState
+	}
+	if amount.Currency != p.Amount.Currency {
+		return money.ErrCurrencyMismatch
+	}
+	if amount.Amount <= 0 || amount.Amount > p.Refundable().Amount {
+		return ErrRefundExceedsCaptured
+	}
+
+	p.RefundedAmount.Amount += amount.Amount
+	p.UpdatedAt = now
+	if p.Refundable().IsZero() {
+		p.Status = PaymentRefunded
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentIntent(t *testing.T) {
+
+	now := time.Now()
+
+	_, err := NewPaymentIntent(5, "fake", "", usd(6000), now)
+	assert.Equal(t, ErrPaymentMethodRequired, err)
+
+	p, err := NewPaymentIntent(5, "fake", "4000000000003220", usd(6000), now)
+	assert.Nil(t, err)
+	assert.Equal(t, PaymentPending, p.Status)
+
+	assert.Nil(t, p.RequireAction("fake-intent-1", "http://localhost/3ds", now))
+	assert.Equal(t, PaymentRequiresAction, p.Status)
+	assert.Equal(t, ErrInvalidPaymentState, p.Capture(now))
+
+	assert.Nil(t, p.Authorize("fake-intent-1", now))
+	assert.Empty(t, p.NextActionURL)
+	assert.Equal(t, ErrInvalidPaymentState, p.Refund(usd(100), now))
+
+	assert.Nil(t, p.Capture(now))
+	assert.Equal(t, usd(6000), p.CapturedAmount)
+	assert.Equal(t, ErrInvalidPaymentState, p.Void(now))
+
+	assert.Nil(t, p.Refund(usd(1100), now))
+	assert.Equal(t, usd(4900), p.Refundable())
+	assert.Equal(t, ErrRefundExceedsCaptured, p.Refund(usd(5000), now))
+
+	assert.Nil(t, p.Refund(usd(4900), now))
+	assert.Equal(t, PaymentRefunded, p.Status)
+}
+
+func TestPaymentIntentFails(t *testing.T) {
+
+	now := time.Now()
+	p, _ := NewPaymentIntent(5, "fake", "4000000000000002", usd(6000), now)
+
+	assert.Nil(t, p.Fail("card declined", now))
+	assert.Equal(t, PaymentFailed, p.Status)
+	assert.Equal(t, "card declined", p.FailureReason)
+	assert.Equal(t, ErrInvalidPaymentState, p.Authorize("fake-intent-1", now))
+	assert.Equal(t, ErrInvalidPaymentState, p.Void(now))
+}
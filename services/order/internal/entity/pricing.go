@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var ErrInvalidTaxRate = errors.New("tax rate must be between 0 and 10000 basis points")
+
+// PricingPolicy holds what the order service charges on top of the goods
+type PricingPolicy struct {
+	// TaxRate is charged on the discounted subtotal, in basis points (825 is 8.25%)
+	TaxRate int64
+	// ShippingFees is the flat shipping fee per currency; orders in a currency without one ship free
+	ShippingFees map[string]money.Money
+	// FreeShippingOver waives the fee of orders whose discounted subtotal reaches it, per currency
+	FreeShippingOver map[string]money.Money
+}
+
+func (p PricingPolicy) Validate() error {
+
+	if p.TaxRate < 0 || p.TaxRate > 10000 {
+		return ErrInvalidTaxRate
+	}
+	for currency, fee := range p.ShippingFees {
+		if fee.Currency != currency || fee.IsNegative() {
+			return money.ErrInvalidAmount
+		}
+	}
+	for currency, over := range p.FreeShippingOver {
+		if over.Currency != currency || over.IsNegative() {
+			return money.ErrInvalidAmount
+		}
+	}
+	return nil
+}
+
+// Tax is the tax on amount, rounded half up to the minor unit
+func (p PricingPolicy) Tax(amount money.Money) money.Money {
+	return money.Money{Amount: (amount.Amount*p.TaxRate + 5000) / 10000, Currency: amount.Currency}
+}
+
+// Shipping is the shipping fee of an order whose discounted subtotal is amount
+func (p PricingPolicy) Shipping(amount money.Money) money.Money {
+
+	fee, ok := p.ShippingFees[amount.Currency]
+	if !ok {
+		return money.Money{Currency: amount.Currency}
+	}
+	if over, ok := p.FreeShippingOver[amount.Currency]; ok && amount.Amount >= over.Amount {
+		return money.Money{Currency: amount.Currency}
+	}
+	return fee
+}
+
+// ParseAmounts reads one amount per currency from a spec such as "USD:5.00,EUR:4.50"
+func ParseAmounts(spec string) (map[string]money.Money, error) {
+
+	amounts := make(map[string]money.Money)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		currency, value, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q, want CURRENCY:AMOUNT", item)
+		}
+		m, err := money.Parse(value, currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount %q: %w", item, err)
+		}
+		amounts[m.Currency] = m
+	}
+	return amounts, nil
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// ProductUpdateLease bounds how long an instance may take to tell the product service before another one
+// retries it
+const ProductUpdateLease = time.Minute
+
+type ProductUpdateKind string
+
+const (
+	// UpdateCommitStock takes the stock reserved for a shipped order out of stock
+	UpdateCommitStock ProductUpdateKind = "commit_stock"
+	// UpdateRecordPurchase lets the customer of a delivered order review its products
+	UpdateRecordPurchase ProductUpdateKind = "record_purchase"
+)
+
+// ProductUpdate is what the product service must learn of a status change of an order. It is saved with
+// the change and due until DoneAt is set; like a refund, one whose lease ran out is retried by another
+// instance.
+type ProductUpdate struct {
+	ID      int64
+	OrderID int64
+	Kind    ProductUpdateKind
+	// LockedUntil is the lease of the instance telling the product service
+	LockedUntil time.Time
+	Attempts    int
+	DoneAt      *time.Time
+	CreatedAt   time.Time
+}
+
+// ProductUpdateFor returns the update the product service needs once c is applied, nil when there is none
+func ProductUpdateFor(c *StatusChange, lease time.Duration) *ProductUpdate {
+
+	var kind ProductUpdateKind
+	switch c.To {
+	case OrderShipped:
+		kind = UpdateCommitStock
+	case OrderDelivered:
+		kind = UpdateRecordPurchase
+	default:
+		return nil
+	}
+	return &ProductUpdate{OrderID: c.OrderID, Kind: kind, LockedUntil: c.ChangedAt.Add(lease), CreatedAt: c.ChangedAt}
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidPageToken   = errors.New("invalid page token")
+	ErrInvalidOrderStatus = errors.New("invalid order status")
+)
+
+// OrderQuery filters an order listing, newest first
+type OrderQuery struct {
+	// UserID restricts the listing to one customer, zero lists every customer's orders
+	UserID int64
+	Status OrderStatus
+	// From and To bound the time orders were placed, To excluded
+	From *time.Time
+	To   *time.Time
+	// ProductID matches orders with a line of the product
+	ProductID int64
+	// After continues a listing after the last order of the previous page
+	After *OrderCursor
+	Limit int
+}
+
+func (q *OrderQuery) Validate() error {
+
+	if q.Status != "" && !q.Status.Valid() {
+		return ErrInvalidOrderStatus
+	}
+	return nil
+}
+
+// OrderCursor is the position of an order in a listing. Orders placed while a customer pages
+// through the listing do not shift the pages, unlike an offset.
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// CursorOf is the position after o
+func CursorOf(o *Order) OrderCursor {
+	return OrderCursor{CreatedAt: o.CreatedAt, ID: o.ID}
+}
+
+// String encodes the cursor as an opaque page token
+func (c OrderCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", c.CreatedAt.UnixNano(), c.ID))
+}
+
+// ParseCursor reads a page token made by OrderCursor.String
+func ParseCursor(token string) (*OrderCursor, error) {
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var nanos, id int64
+	if n, err := fmt.Sscanf(string(raw), "%d.%d", &nanos, &id); err != nil || n != 2 || id <= 0 {
+		return nil, ErrInvalidPageToken
+	}
+	return &OrderCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
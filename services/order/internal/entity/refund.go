@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// RefundLease bounds how long an instance may take to issue a refund before another one retries it
+const RefundLease = time.Minute
+
+// Refund is money given back to the customer of an order, e.g. for cancelled lines. It is due until
+// RefundedAt is set; like a checkout, a refund whose lease ran out is retried by another instance.
+type Refund struct {
+	ID      int64
+	OrderID int64
+	Amount  money.Money
+	// Reason tells what the refund is for, e.g. "cancellation"
+	Reason string
+	// LockedUntil is the lease of the instance issuing the refund
+	LockedUntil time.Time
+	Attempts    int
+	RefundedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+func NewRefund(orderID int64, amount money.Money, reason string, now time.Time, lease time.Duration) *Refund {
+	return &Refund{OrderID: orderID, Amount: amount, Reason: reason, LockedUntil: now.Add(lease), CreatedAt: now}
+}
+
+// Key identifies the refund to the payment provider, so a retried refund is not paid twice
+func (r *Refund) Key() string {
+	return fmt.Sprintf("refund-%d", r.ID)
+}
+
+// RefundAll moves the order to refunded, returning the refund of all that was not refunded yet, nil when
+// nothing is left to give back, with the status change
+func (o *Order) RefundAll(by int64, reason string, now time.Time) (*Refund, *StatusChange, error) {
+
+	c, err := o.Transition(OrderRefunded, by, reason, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	amount := o.refundFor(0, true)
+	o.RefundedTotal.Amount += amount.Amount
+	if amount.IsZero() {
+		return nil, c, nil
+	}
+	return NewRefund(o.ID, amount, "refund", now, RefundLease), c, nil
+}
+
+// refundFor is what giving back goods worth goods of o is worth: their price plus their share of the
+// tax. When nothing is left of the order, all that was not refunded yet is, shipping included.
+func (o *Order) refundFor(goods int64, nothingLeft bool) money.Money {
+
+	left := o.Total.Amount - o.RefundedTotal.Amount
+	if nothingLeft {
+		return money.Money{Amount: left, Currency: o.Currency}
+	}
+
+	var tax int64
+	if net := o.Total.Amount - o.TaxTotal.Amount - o.ShippingTotal.Amount; net > 0 {
+		tax = o.TaxTotal.Amount * goods / net
+	}
+	return money.Money{Amount: min(goods+tax, left), Currency: o.Currency}
+}
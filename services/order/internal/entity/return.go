@@ -0,0 +1,254 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var (
+	ErrInvalidReturnReason     = errors.New("invalid return reason")
+	ErrReturnNotAllowed        = errors.New("only delivered orders can be returned")
+	ErrReturnNeedsLines        = errors.New("return needs at least one line")
+	ErrReturnQuantityTooHigh   = errors.New("cannot return more than what is left of the line")
+	ErrDuplicateReturnLine     = errors.New("line returned more than once")
+	ErrReturnNotFound          = errors.New("return not found")
+	ErrInvalidReturnTransition = errors.New("return cannot move to that status")
+	ErrReturnChanged           = errors.New("return was changed meanwhile")
+)
+
+type ReturnReason string
+
+const (
+	ReturnDamaged        ReturnReason = "damaged"
+	ReturnDefective      ReturnReason = "defective"
+	ReturnWrongItem      ReturnReason = "wrong_item"
+	ReturnNotAsDescribed ReturnReason = "not_as_described"
+	ReturnNoLongerNeeded ReturnReason = "no_longer_needed"
+	ReturnOther          ReturnReason = "other"
+)
+
+func (r ReturnReason) Valid() bool {
+
+	switch r {
+	case ReturnDamaged, ReturnDefective, ReturnWrongItem, ReturnNotAsDescribed, ReturnNoLongerNeeded, ReturnOther:
+		return true
+	}
+	return false
+}
+
+// SellerAtFault tells whether the goods come back through the fault of the seller rather than the
+// customer changing their mind
+func (r ReturnReason) SellerAtFault() bool {
+	return r == ReturnDamaged || r == ReturnDefective || r == ReturnWrongItem || r == ReturnNotAsDescribed
+}
+
+// ReturnStatus is where a return stands: requested by the customer, approved or rejected by staff, then
+// received back, which refunds it
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "requested"
+	ReturnApproved  ReturnStatus = "approved"
+	ReturnRejected  ReturnStatus = "rejected"
+	ReturnReceived  ReturnStatus = "received"
+)
+
+// ReturnLine is a quantity of an order line to send back. Restock tells whether the goods received can be
+// sold again, damaged ones cannot.
+type ReturnLine struct {
+	LineID    int64
+	ProductID int64
+	Quantity  int
+	Restock   bool
+}
+
+// Return is a request of the customer to send back lines of a delivered order, an RMA
+type Return struct {
+	ID      int64
+	OrderID int64
+	Status  ReturnStatus
+	Reason  ReturnReason
+	Note    string
+	Lines   []ReturnLine
+	// RequestedBy is the customer, ReviewedBy the staff member who last moved the return on
+	RequestedBy int64
+	ReviewedBy  int64
+	ReviewNote  string
+	// Refund is what the customer gets back once the goods are received, nil before
+	Refund *Refund
+	// RestockedAt is when the goods received went back to stock, nil until then
+	RestockedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Restocks returns the quantity of each product that goes back to stock
+func (r *Return) Restocks() map[int64]int64 {
+
+	quantities := make(map[int64]int64)
+	for _, l := range r.Lines {
+		if l.Restock {
+			quantities[l.ProductID] += int64(l.Quantity)
+		}
+	}
+	return quantities
+}
+
+// RequestReturn asks to send back lines of the order once it is delivered. The quantities stay held by the
+// return, so they cannot be asked for twice, until it is rejected or received.
+func (o *Order) RequestReturn(reason ReturnReason, note string, lines []ReturnLine, by int64, now time.Time) (*Return, error) {
+
+	if !reason.Valid() {
+		return nil, ErrInvalidReturnReason
+	}
+	if len(note) > maxReasonLength {
+		return nil, ErrReasonTooLong
+	}
+	if o.Status != OrderDelivered {
+		return nil, ErrReturnNotAllowed
+	}
+	if len(lines) == 0 {
+		return nil, ErrReturnNeedsLines
+	}
+
+	seen := make(map[int64]bool, len(lines))
+	for i := range lines {
+		rl := &lines[i]
+		l := o.line(rl.LineID)
+		if l == nil {
+			return nil, ErrOrderLineNotFound
+		}
+		if seen[rl.LineID] {
+			return nil, ErrDuplicateReturnLine
+		}
+		seen[rl.LineID] = true
+		if rl.Quantity <= 0 {
+			return nil, ErrQuantityIsRequired
+		}
+		if rl.Quantity > l.Returnable() {
+			return nil, ErrReturnQuantityTooHigh
+		}
+		rl.ProductID, rl.Restock = l.ProductID, false
+	}
+
+	for _, rl := range lines {
+		o.line(rl.LineID).ReturningQuantity += rl.Quantity
+	}
+	o.UpdatedAt = now
+
+	return &Return{OrderID: o.ID, Status: ReturnRequested, Reason: reason, Note: note, Lines: lines, RequestedBy: by, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// Approve lets the customer send the goods back
+func (r *Return) Approve(by int64, note string, now time.Time) error {
+
+	if r.Status != ReturnRequested {
+		return ErrInvalidReturnTransition
+	}
+	if len(note) > maxReasonLength {
+		return ErrReasonTooLong
+	}
+	r.Status, r.ReviewedBy, r.ReviewNote, r.UpdatedAt = ReturnApproved, by, note, now
+	return nil
+}
+
+// RejectReturn turns r down, before or after approving it, e.g. when the goods never came back. Its
+// quantities may be asked for again.
+func (o *Order) RejectReturn(r *Return, by int64, note string, now time.Time) error {
+
+	if r.Status != ReturnRequested && r.Status != ReturnApproved {
+		return ErrInvalidReturnTransition
+	}
+	if len(note) > maxReasonLength {
+		return ErrReasonTooLong
+	}
+	for _, rl := range r.Lines {
+		if l := o.line(rl.LineID); l != nil {
+			l.ReturningQuantity -= rl.Quantity
+		}
+	}
+	r.Status, r.ReviewedBy, r.ReviewNote, r.UpdatedAt = ReturnRejected, by, note, now
+	o.UpdatedAt = now
+	return nil
+}
+
+// ReceiveReturn records the goods of an approved return as back, restocking the lines of restock, and
+// refunds them. The order moves to refunded, with the status change returned, once the customer kept
+// nothing of it.
+func (o *Order) ReceiveReturn(r *Return, restock map[int64]bool, by int64, note string, now time.Time) (*StatusChange, error) {
+
+	if r.Status != ReturnApproved {
+		return nil, ErrInvalidReturnTransition
+	}
+	if len(note) > maxReasonLength {
+		return nil, ErrReasonTooLong
+	}
+	for id := range restock {
+		if !r.hasLine(id) {
+			return nil, ErrOrderLineNotFound
+		}
+	}
+
+	var goods int64
+	for i := range r.Lines {
+		rl := &r.Lines[i]
+		l := o.line(rl.LineID)
+		if l == nil {
+			return nil, ErrOrderLineNotFound
+		}
+		l.ReturningQuantity -= rl.Quantity
+		l.ReturnedQuantity += rl.Quantity
+		rl.Restock = restock[rl.LineID]
+		goods += l.UnitPrice.Amount * int64(rl.Quantity)
+	}
+	nothingLeft := true
+	for _, l := range o.Lines {
+		if l.Kept() > 0 {
+			nothingLeft = false
+		}
+	}
+
+	amount := o.returnRefund(goods, nothingLeft, r.Reason.SellerAtFault())
+	o.RefundedTotal.Amount += amount.Amount
+	if !amount.IsZero() {
+		r.Refund = NewRefund(o.ID, amount, "return", now, RefundLease)
+	}
+	if len(r.Restocks()) == 0 {
+		r.RestockedAt = &now
+	}
+	r.Status, r.ReviewedBy, r.ReviewNote, r.UpdatedAt = ReturnReceived, by, note, now
+
+	var sc *StatusChange
+	if nothingLeft {
+		var err error
+		if sc, err = o.Transition(OrderRefunded, by, string(r.Reason), now); err != nil {
+			return nil, err
+		}
+	}
+	o.UpdatedAt = now
+
+	return sc, nil
+}
+
+// returnRefund is what receiving back goods worth goods of o is worth. Shipping is only refunded when the
+// whole order comes back through the fault of the seller; otherwise the customer got the delivery they paid for.
+func (o *Order) returnRefund(goods int64, nothingLeft, sellerAtFault bool) money.Money {
+
+	m := o.refundFor(goods, nothingLeft)
+	if nothingLeft && !sellerAtFault {
+		m.Amount = max(m.Amount-o.ShippingTotal.Amount, 0)
+	}
+	return m
+}
+
+func (r *Return) hasLine(id int64) bool {
+
+	for _, l := range r.Lines {
+		if l.LineID == id {
+			return true
+		}
+	}
+	return false
+}
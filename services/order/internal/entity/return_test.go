@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func deliveredOrder(t *testing.T) *Order {
+
+	o := paidOrder(t)
+	o.Status = OrderDelivered
+	return o
+}
+
+func TestReturnLines(t *testing.T) {
+
+	o := deliveredOrder(t)
+	now := time.Now()
+
+	r, err := o.RequestReturn(ReturnNoLongerNeeded, "", []ReturnLine{{LineID: 11, Quantity: 1}}, 7, now)
+	assert.Nil(t, err)
+	assert.Equal(t, ReturnRequested, r.Status)
+	assert.Equal(t, []ReturnLine{{LineID: 11, ProductID: 1, Quantity: 1}}, r.Lines)
+	assert.Equal(t, 1, o.Lines[0].Returnable())
+
+	// the quantity is held by the open return
+	_, err = o.RequestReturn(ReturnDamaged, "", []ReturnLine{{LineID: 11, Quantity: 2}}, 7, now)
+	assert.Equal(t, ErrReturnQuantityTooHigh, err)
+
+	_, err = o.ReceiveReturn(r, nil, 9, "", now)
+	assert.Equal(t, ErrInvalidReturnTransition, err)
+	assert.Nil(t, r.Approve(9, "ok", now))
+
+	sc, err := o.ReceiveReturn(r, map[int64]bool{11: true}, 9, "", now)
+	assert.Nil(t, err)
+	assert.Nil(t, sc)
+	assert.Equal(t, ReturnReceived, r.Status)
+	// 10.00 and its 1.00 of tax
+	assert.Equal(t, usd(1100), r.Refund.Amount)
+	assert.Equal(t, usd(1100), o.RefundedTotal)
+	assert.Equal(t, map[int64]int64{1: 1}, r.Restocks())
+	assert.Nil(t, r.RestockedAt)
+	assert.Equal(t, 1, o.Lines[0].Kept())
+	assert.Equal(t, OrderDelivered, o.Status)
+}
+
+func TestReturnEverything(t *testing.T) {
+
+	now := time.Now()
+	lines := []ReturnLine{{LineID: 11, Quantity: 2}, {LineID: 12, Quantity: 1}}
+
+	// the customer changed their mind: all but shipping
+	o := deliveredOrder(t)
+	r, err := o.RequestReturn(ReturnNoLongerNeeded, "", append([]ReturnLine(nil), lines...), 7, now)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Approve(9, "", now))
+	sc, err := o.ReceiveReturn(r, nil, 9, "", now)
+	assert.Nil(t, err)
+	assert.Equal(t, usd(5500), r.Refund.Amount)
+	assert.NotNil(t, r.RestockedAt)
+	assert.Equal(t, &StatusChange{OrderID: 5, From: OrderDelivered, To: OrderRefunded, ChangedBy: 9, Reason: "no_longer_needed", ChangedAt: now}, sc)
+
+	// the goods were damaged: shipping too
+	o = deliveredOrder(t)
+	r, err = o.RequestReturn(ReturnDamaged, "", append([]ReturnLine(nil), lines...), 7, now)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Approve(9, "", now))
+	_, err = o.ReceiveReturn(r, nil, 9, "", now)
+	assert.Nil(t, err)
+	assert.Equal(t, usd(6000), r.Refund.Amount)
+	assert.Equal(t, OrderRefunded, o.Status)
+}
+
+func TestRejectReturn(t *testing.T) {
+
+	o := deliveredOrder(t)
+	now := time.Now()
+
+	r, err := o.RequestReturn(ReturnDefective, "", []ReturnLine{{LineID: 12, Quantity: 1}}, 7, now)
+	assert.Nil(t, err)
+	assert.Nil(t, o.RejectReturn(r, 9, "out of warranty", now))
+	assert.Equal(t, ReturnRejected, r.Status)
+	assert.Equal(t, 1, o.Lines[1].Returnable())
+	assert.Equal(t, ErrInvalidReturnTransition, r.Approve(9, "", now))
+}
+
+func TestRequestReturnErrors(t *testing.T) {
+
+	o := paidOrder(t)
+	now := time.Now()
+
+	_, err := o.RequestReturn(ReturnDamaged, "", []ReturnLine{{LineID: 11, Quantity: 1}}, 7, now)
+	assert.Equal(t, ErrReturnNotAllowed, err)
+
+	o.Status = OrderDelivered
+	for _, c := range []struct {
+		reason ReturnReason
+		lines  []ReturnLine
+		err    error
+	}{
+		{"lost", []ReturnLine{{LineID: 11, Quantity: 1}}, ErrInvalidReturnReason},
+		{ReturnOther, nil, ErrReturnNeedsLines},
+		{ReturnOther, []ReturnLine{{LineID: 99, Quantity: 1}}, ErrOrderLineNotFound},
+		{ReturnOther, []ReturnLine{{LineID: 11, Quantity: 1}, {LineID: 11, Quantity: 1}}, ErrDuplicateReturnLine},
+		{ReturnOther, []ReturnLine{{LineID: 11, Quantity: 0}}, ErrQuantityIsRequired},
+		{ReturnOther, []ReturnLine{{LineID: 12, Quantity: 2}}, ErrReturnQuantityTooHigh},
+	} {
+		_, err := o.RequestReturn(c.reason, "", c.lines, 7, now)
+		assert.Equal(t, c.err, err, c.reason)
+	}
+	assert.Equal(t, 0, o.Lines[0].ReturningQuantity)
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrSagaLeaseLost is returned to an instance whose lease on a saga ran out and was claimed by another one
+	ErrSagaLeaseLost = errors.New("checkout was taken over by another instance")
+)
+
+// SagaStep is how far a checkout got. Forward steps run in order; on a failure the saga compensates what
+// was done so far instead.
+type SagaStep string
+
+const (
+	SagaStarted       SagaStep = "started"
+	SagaStockReserved SagaStep = "stock_reserved"
+	// SagaPaymentPending waits for the customer to complete the payment, e.g. for 3-D Secure; the payment
+	// webhook carries the checkout on
+	SagaPaymentPending    SagaStep = "payment_pending"
+	SagaPaymentAuthorized SagaStep = "payment_authorized"
+	// SagaCompleted is a checkout whose order is paid
+	SagaCompleted SagaStep = "completed"
+	// SagaCompensating undoes the steps of a failed checkout: voids the payment, releases the stock and
+	// cancels the order
+	SagaCompensating SagaStep = "compensating"
+	SagaCompensated  SagaStep = "compensated"
+)
+
+// CheckoutSaga is the persisted state of the checkout of an order, so a checkout interrupted by a crash
+// is carried on by the next instance
+type CheckoutSaga struct {
+	OrderID int64
+	Step    SagaStep
+	// PaymentReference identifies the authorization to void, once there is one
+	PaymentReference string
+	// FailureReason tells why a compensating saga failed
+	FailureReason string
+	// Deadline is when a checkout that has not completed is given up
+	Deadline time.Time
+	// LockedUntil is the lease of the instance running the saga
+	LockedUntil time.Time
+	Attempts    int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func NewCheckoutSaga(orderID int64, now time.Time, timeout, lease time.Duration) *CheckoutSaga {
+	return &CheckoutSaga{OrderID: orderID, Step: SagaStarted, Deadline: now.Add(timeout), LockedUntil: now.Add(lease), CreatedAt: now, UpdatedAt: now}
+}
+
+// IsFinished tells whether nothing is left to do
+func (s *CheckoutSaga) IsFinished() bool {
+	return s.Step == SagaCompleted || s.Step == SagaCompensated
+}
+
+// IsCompensating tells whether the saga is undoing its steps
+func (s *CheckoutSaga) IsCompensating() bool {
+	return s.Step == SagaCompensating || s.Step == SagaCompensated
+}
+
+// Fail turns the saga to compensation, keeping the first reason it failed
+func (s *CheckoutSaga) Fail(reason string) {
+
+	if s.IsCompensating() {
+		return
+	}
+	s.Step, s.FailureReason = SagaCompensating, reason
+}
+
+// Expired tells whether a saga still moving forward at now ran out of time
+func (s *CheckoutSaga) Expired(now time.Time) bool {
+	return !s.IsFinished() && !s.IsCompensating() && !now.Before(s.Deadline)
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckoutSaga(t *testing.T) {
+
+	now := time.Now()
+	s := NewCheckoutSaga(5, now, 15*time.Minute, time.Minute)
+
+	assert.Equal(t, SagaStarted, s.Step)
+	assert.False(t, s.Expired(now))
+	assert.True(t, s.Expired(now.Add(15*time.Minute)))
+
+	s.Step = SagaStockReserved
+	s.Fail("payment declined")
+	assert.Equal(t, SagaCompensating, s.Step)
+	assert.False(t, s.Expired(now.Add(time.Hour)))
+
+	// the first failure is the one that counts
+	s.Fail("timed out")
+	assert.Equal(t, "payment declined", s.FailureReason)
+
+	s.Step = SagaCompensated
+	assert.True(t, s.IsFinished())
+}
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTransition  = errors.New("order cannot move to that status")
+	ErrManualTransition   = errors.New("order status is set by its checkout, not by hand")
+	ErrOrderStatusChanged = errors.New("order status changed meanwhile, try again")
+	ErrReasonTooLong      = errors.New("reason must be at most 500 characters")
+)
+
+const maxReasonLength = 500
+
+type OrderStatus string
+
+const (
+	// OrderPending is the status of an order that was just placed
+	OrderPending         OrderStatus = "pending"
+	OrderAwaitingPayment OrderStatus = "awaiting_payment"
+	OrderPaid            OrderStatus = "paid"
+	OrderFulfilling      OrderStatus = "fulfilling"
+	OrderShipped         OrderStatus = "shipped"
+	OrderDelivered       OrderStatus = "delivered"
+	OrderCancelled       OrderStatus = "cancelled"
+	// OrderRefunded is an order whose payment was given back
+	OrderRefunded OrderStatus = "refunded"
+)
+
+// transitions lists the statuses an order can move to from each status; cancelled and refunded are final
+var transitions = map[OrderStatus][]OrderStatus{
+	OrderPending:         {OrderAwaitingPayment, OrderCancelled},
+	OrderAwaitingPayment: {OrderPaid, OrderCancelled},
+	OrderPaid:            {OrderFulfilling, OrderCancelled, OrderRefunded},
+	OrderFulfilling:      {OrderShipped, OrderCancelled},
+	OrderShipped:         {OrderDelivered},
+	OrderDelivered:       {OrderRefunded},
+	OrderCancelled:       nil,
+	OrderRefunded:        nil,
+}
+
+func (s OrderStatus) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanMoveTo tells whether an order in status s can move to status to
+func (s OrderStatus) CanMoveTo(to OrderStatus) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal tells whether no status follows s
+func (s OrderStatus) IsFinal() bool {
+	return s.Valid() && len(transitions[s]) == 0
+}
+
+// StatusChange is an entry of the status history of an order. ChangedBy is the user who made the change,
+// zero for the system.
+type StatusChange struct {
+	ID        int64
+	OrderID   int64
+	From      OrderStatus
+	To        OrderStatus
+	ChangedBy int64
+	Reason    string
+	ChangedAt time.Time
+}
+
+// Transition moves the order to status to, returning the change to record
+func (o *Order) Transition(to OrderStatus, changedBy int64, reason string, now time.Time) (*StatusChange, error) {
+
+	if !to.Valid() {
+		return nil, ErrInvalidOrderStatus
+	}
+	if !o.Status.CanMoveTo(to) {
+		return nil, ErrInvalidTransition
+	}
+	if len(reason) > maxReasonLength {
+		return nil, ErrReasonTooLong
+	}
+
+	c := &StatusChange{OrderID: o.ID, From: o.Status, To: to, ChangedBy: changedBy, Reason: reason, ChangedAt: now}
+	o.Status, o.UpdatedAt = to, now
+
+	return c, nil
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderTransition(t *testing.T) {
+
+	o := &Order{ID: 3, Status: OrderPending}
+	now := time.Now()
+
+	for _, to := range []OrderStatus{OrderAwaitingPayment, OrderPaid, OrderFulfilling, OrderShipped, OrderDelivered, OrderRefunded} {
+		from := o.Status
+		c, err := o.Transition(to, 9, "", now)
+		assert.Nil(t, err, to)
+		assert.Equal(t, &StatusChange{OrderID: 3, From: from, To: to, ChangedBy: 9, ChangedAt: now}, c)
+		assert.Equal(t, to, o.Status)
+	}
+	assert.True(t, o.Status.IsFinal())
+
+	_, err := o.Transition(OrderPaid, 9, "", now)
+	assert.Equal(t, ErrInvalidTransition, err)
+
+	o = &Order{Status: OrderShipped}
+	_, err = o.Transition(OrderCancelled, 9, "", now)
+	assert.Equal(t, ErrInvalidTransition, err)
+
+	_, err = o.Transition("lost", 9, "", now)
+	assert.Equal(t, ErrInvalidOrderStatus, err)
+	assert.Equal(t, OrderShipped, o.Status)
+}
+
+func TestRefundAll(t *testing.T) {
+
+	now := time.Now()
+	o := paidOrder(t)
+	_, _, err := o.Cancel(CancelOutOfStock, "", []CancelLine{{LineID: 11, Quantity: 1}}, 1, true, now)
+	assert.Nil(t, err)
+	assert.Equal(t, usd(1100), o.RefundedTotal)
+
+	f, c, err := o.RefundAll(9, "goodwill", now)
+	assert.Nil(t, err)
+	assert.Equal(t, usd(4900), f.Amount)
+	assert.Equal(t, &StatusChange{OrderID: 5, From: OrderPaid, To: OrderRefunded, ChangedBy: 9, Reason: "goodwill", ChangedAt: now}, c)
+	assert.Equal(t, o.Total, o.RefundedTotal)
+
+	_, _, err = o.RefundAll(9, "", now)
+	assert.Equal(t, ErrInvalidTransition, err)
+
+	o = paidOrder(t)
+	o.Status = OrderShipped
+	_, _, err = o.RefundAll(9, "", now)
+	assert.Equal(t, ErrInvalidTransition, err)
+	assert.True(t, o.RefundedTotal.IsZero())
+}
@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/auth"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type principalKey struct{}
+
+// authInterceptor identifies the caller from the "authorization" metadata, the same bearer token the HTTP
+// API takes. Every order belongs to someone, so calls without a valid token are rejected.
+func authInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		var value string
+		if values := md.Get("authorization"); len(values) > 0 {
+			value = values[0]
+		}
+		p, msg := auth.ParseBearer(secret, value)
+		if msg != "" {
+			return nil, status.Error(codes.Unauthenticated, msg)
+		}
+		return handler(context.WithValue(ctx, principalKey{}, p), req)
+	}
+}
+
+// principal returns the caller set by authInterceptor
+func principal(ctx context.Context) auth.Principal {
+	p, _ := ctx.Value(principalKey{}).(auth.Principal)
+	return p
+}
+
+// requireStaff returns the caller when they are staff
+func requireStaff(ctx context.Context) (auth.Principal, error) {
+
+	p := principal(ctx)
+	if !p.IsStaff() {
+		return p, status.Error(codes.PermissionDenied, "staff only")
+	}
+	return p, nil
+}
+
+// canSee tells whether the caller may read o: its customer and staff may. Others are told it does not
+// exist, as over HTTP.
+func canSee(ctx context.Context, o *entity.Order) error {
+
+	if p := principal(ctx); o.UserID != p.UserID && !p.IsStaff() {
+		return entity.ErrOrderNotFound
+	}
+	return nil
+}
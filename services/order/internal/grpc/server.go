@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/order/pb"
+	productpb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type OrderServer struct {
+	pb.UnimplementedOrderServiceServer
+	OrderUseCase usecase.OrderUseCase
+	grpcServer   *grpc.Server
+}
+
+func NewOrderServer(uc usecase.OrderUseCase, jwtSecret string) *OrderServer {
+
+	s := &OrderServer{
+		OrderUseCase: uc,
+		grpcServer:   grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(jwtSecret))),
+	}
+
+	pb.RegisterOrderServiceServer(s.grpcServer, s)
+	reflection.Register(s.grpcServer)
+
+	return s
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+
+	id, err := strconv.ParseInt(req.Id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.OrderUseCase.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canSee(ctx, o); err != nil {
+		return nil, err
+	}
+	return toPBOrder(o), nil
+}
+
+// ListOrders lists the orders of the caller
+func (s *OrderServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+
+	q, err := fromPBFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	q.UserID = principal(ctx).UserID
+
+	return s.listOrders(ctx, q)
+}
+
+func (s *OrderServer) SearchOrders(ctx context.Context, req *pb.SearchOrdersRequest) (*pb.ListOrdersResponse, error) {
+
+	if _, err := requireStaff(ctx); err != nil {
+		return nil, err
+	}
+	q, err := fromPBFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId != "" {
+		if q.UserID, err = strconv.ParseInt(req.UserId, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if req.ProductId != "" {
+		if q.ProductID, err = strconv.ParseInt(req.ProductId, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.listOrders(ctx, q)
+}
+
+func (s *OrderServer) listOrders(ctx context.Context, q entity.OrderQuery) (*pb.ListOrdersResponse, error) {
+
+	list, next, err := s.OrderUseCase.ListOrders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListOrdersResponse{Orders: make([]*pb.Order, len(list))}
+	for i := range list {
+		resp.Orders[i] = toPBOrder(&list[i])
+	}
+	if next != nil {
+		resp.NextPageToken = next.String()
+	}
+	return resp, nil
+}
+
+func (s *OrderServer) StartGRPCServer(port string) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+
+	log.Printf("Order gRPC server running on :%s", port)
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop waits for in-flight calls to finish, cancelling them once ctx is done
+func (s *OrderServer) Stop(ctx context.Context) {
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
+func fromPBFilter(f *pb.OrderFilter) (entity.OrderQuery, error) {
+
+	var q entity.OrderQuery
+	if f == nil {
+		return q, nil
+	}
+
+	q.Status = entity.OrderStatus(f.Status)
+	q.Limit = int(f.Limit)
+	if f.From != nil {
+		t := f.From.AsTime()
+		q.From = &t
+	}
+	if f.To != nil {
+		t := f.To.AsTime()
+		q.To = &t
+	}
+	if f.PageToken != "" {
+		c, err := entity.ParseCursor(f.PageToken)
+		if err != nil {
+			return q, err
+		}
+		q.After = c
+	}
+	return q, nil
+}
+
+func toPBOrder(o *entity.Order) *pb.Order {
+
+	resp := &pb.Order{
+		Id:            strconv.FormatInt(o.ID, 10),
+		UserId:        strconv.FormatInt(o.UserID, 10),
+		Status:        string(o.Status),
+		Currency:      o.Currency,
+		Subtotal:      toPBMoney(o.Subtotal),
+		DiscountTotal: toPBMoney(o.DiscountTotal),
+		TaxTotal:      toPBMoney(o.TaxTotal),
+		ShippingTotal: toPBMoney(o.ShippingTotal),
+		Total:         toPBMoney(o.Total),
+		RefundedTotal: toPBMoney(o.RefundedTotal),
+		Lines:         make([]*pb.OrderLine, len(o.Lines)),
+		CreatedAt:     timestamppb.New(o.CreatedAt),
+		UpdatedAt:     timestamppb.New(o.UpdatedAt),
+	}
+	for i, l := range o.Lines {
+		resp.Lines[i] = &pb.OrderLine{
+			Id:                strconv.FormatInt(l.ID, 10),
+			ProductId:         strconv.FormatInt(l.ProductID, 10),
+			Sku:               l.SKU,
+			Quantity:          int64(l.Quantity),
+			CancelledQuantity: int64(l.CancelledQuantity),
+			ReturningQuantity: int64(l.ReturningQuantity),
+			ReturnedQuantity:  int64(l.ReturnedQuantity),
+			UnitPrice:         toPBMoney(l.UnitPrice),
+			Discount:          toPBMoney(l.Discount),
+			LineTotal:         toPBMoney(l.LineTotal),
+		}
+	}
+	return resp
+}
+
+func toPBMoney(m money.Money) *productpb.Money {
+
+	units, nanos := m.UnitsNanos()
+	return &productpb.Money{CurrencyCode: m.Currency, Units: units, Nanos: nanos}
+}
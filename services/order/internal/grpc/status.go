@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/order/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TransitionOrder moves an order along its fulfilment, for staff; the change is recorded as theirs
+func (s *OrderServer) TransitionOrder(ctx context.Context, req *pb.TransitionOrderRequest) (*pb.Order, error) {
+
+	id, err := strconv.ParseInt(req.OrderId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	p, err := requireStaff(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.OrderUseCase.TransitionOrder(ctx, id, usecase.TransitionInput{
+		Status:    entity.OrderStatus(req.Status),
+		ChangedBy: p.UserID,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toPBOrder(o), nil
+}
+
+func (s *OrderServer) ListOrderStatusHistory(ctx context.Context, req *pb.ListOrderStatusHistoryRequest) (*pb.ListOrderStatusHistoryResponse, error) {
+
+	id, err := strconv.ParseInt(req.OrderId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.OrderUseCase.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canSee(ctx, o); err != nil {
+		return nil, err
+	}
+
+	list, err := s.OrderUseCase.ListStatusHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListOrderStatusHistoryResponse{Changes: make([]*pb.OrderStatusChange, len(list))}
+	for i, c := range list {
+		resp.Changes[i] = &pb.OrderStatusChange{
+			From:      string(c.From),
+			To:        string(c.To),
+			ChangedBy: strconv.FormatInt(c.ChangedBy, 10),
+			Reason:    c.Reason,
+			ChangedAt: timestamppb.New(c.ChangedAt),
+		}
+	}
+	return resp, nil
+}
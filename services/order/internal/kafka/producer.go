@@ -2,28 +2,201 @@ package producer
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"strconv"
+	"time"
 
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
 	"github.com/segmentio/kafka-go"
 )
 
+const OrdersTopic = "orders"
+
+// event types, also sent in the "event-type" header
+const (
+	EventOrderCreated       = "order_created"
+	EventOrderStatusChanged = "order_status_changed"
+	EventOrderCancelled     = "order_cancelled"
+	EventReturnRequested    = "order_return_requested"
+	EventReturnApproved     = "order_return_approved"
+	EventReturnRejected     = "order_return_rejected"
+	EventReturnReceived     = "order_return_received"
+)
+
+// MessageWriter sends messages to Kafka, a *kafka.Writer outside the tests; the outbox relay depends on
+// this rather than on the writer
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// NewProducer writes to the orders topic; messages are keyed by order id, so hashing keeps
+// the events of an order on one partition in the order they happened
 func NewProducer(broker string) *kafka.Writer {
 
 	return &kafka.Writer{
 		Addr:     kafka.TCP(broker),
-		Topic:    "orders",
-		Balancer: &kafka.LeastBytes{},
+		Topic:    OrdersTopic,
+		Balancer: &kafka.Hash{},
+	}
+
+}
+
+// OrderCreated is the message published when an order is placed
+type OrderCreated struct {
+	Event   string `json:"event"`
+	OrderID string `json:"order_id"`
+	UserID  string `json:"user_id"`
+	Total   string `json:"total"`
+}
+
+func NewOrderCreated(o *entity.Order) (entity.OutboxMessage, error) {
+
+	return newMessage(o.ID, EventOrderCreated, OrderCreated{
+		Event:   EventOrderCreated,
+		OrderID: strconv.FormatInt(o.ID, 10),
+		UserID:  strconv.FormatInt(o.UserID, 10),
+		Total:   o.Total.String(),
+	})
+}
+
+// OrderStatusChanged is the message published when an order moves to another status
+type OrderStatusChanged struct {
+	Event     string    `json:"event"`
+	OrderID   string    `json:"order_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedBy int64     `json:"changed_by"`
+	Reason    string    `json:"reason,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func NewOrderStatusChanged(c *entity.StatusChange) (entity.OutboxMessage, error) {
+
+	return newMessage(c.OrderID, EventOrderStatusChanged, OrderStatusChanged{
+		Event:     EventOrderStatusChanged,
+		OrderID:   strconv.FormatInt(c.OrderID, 10),
+		From:      string(c.From),
+		To:        string(c.To),
+		ChangedBy: c.ChangedBy,
+		Reason:    c.Reason,
+		ChangedAt: c.ChangedAt,
+	})
+}
+
+type CancelledLine struct {
+	LineID    string `json:"line_id"`
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// OrderCancelled is the message published when an order, or some of its lines, is cancelled. Full tells
+// whether nothing is left of the order, which also moves to cancelled.
+type OrderCancelled struct {
+	Event          string          `json:"event"`
+	OrderID        string          `json:"order_id"`
+	CancellationID string          `json:"cancellation_id"`
+	Reason         string          `json:"reason"`
+	Full           bool            `json:"full"`
+	Lines          []CancelledLine `json:"lines"`
+	Refund         string          `json:"refund,omitempty"`
+	CancelledBy    int64           `json:"cancelled_by"`
+	CancelledAt    time.Time       `json:"cancelled_at"`
+}
+
+func NewOrderCancelled(c *entity.Cancellation) (entity.OutboxMessage, error) {
+
+	e := OrderCancelled{
+		Event:          EventOrderCancelled,
+		OrderID:        strconv.FormatInt(c.OrderID, 10),
+		CancellationID: strconv.FormatInt(c.ID, 10),
+		Reason:         string(c.Reason),
+		Full:           c.Full,
+		Lines:          make([]CancelledLine, len(c.Lines)),
+		CancelledBy:    c.CancelledBy,
+		CancelledAt:    c.CreatedAt,
+	}
+	for i, l := range c.Lines {
+		e.Lines[i] = CancelledLine{LineID: strconv.FormatInt(l.LineID, 10), ProductID: strconv.FormatInt(l.ProductID, 10), Quantity: l.Quantity}
 	}
+	if c.Refund != nil {
+		e.Refund = c.Refund.Amount.String()
+	}
+	return newMessage(c.OrderID, EventOrderCancelled, e)
+}
+
+type ReturnedLine struct {
+	LineID    string `json:"line_id"`
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	Restock   bool   `json:"restock"`
+}
+
+// OrderReturn is the message published each time a return moves: requested, approved, rejected or received.
+// Refund is set once the goods are received.
+type OrderReturn struct {
+	Event       string         `json:"event"`
+	OrderID     string         `json:"order_id"`
+	ReturnID    string         `json:"return_id"`
+	Status      string         `json:"status"`
+	Reason      string         `json:"reason"`
+	Lines       []ReturnedLine `json:"lines"`
+	Refund      string         `json:"refund,omitempty"`
+	RequestedBy int64          `json:"requested_by"`
+	ReviewedBy  int64          `json:"reviewed_by,omitempty"`
+	ReviewNote  string         `json:"review_note,omitempty"`
+	ChangedAt   time.Time      `json:"changed_at"`
+}
+
+var returnEvents = map[entity.ReturnStatus]string{
+	entity.ReturnRequested: EventReturnRequested,
+	entity.ReturnApproved:  EventReturnApproved,
+	entity.ReturnRejected:  EventReturnRejected,
+	entity.ReturnReceived:  EventReturnReceived,
+}
 
+func NewOrderReturn(r *entity.Return) (entity.OutboxMessage, error) {
+
+	e := OrderReturn{
+		Event:       returnEvents[r.Status],
+		OrderID:     strconv.FormatInt(r.OrderID, 10),
+		ReturnID:    strconv.FormatInt(r.ID, 10),
+		Status:      string(r.Status),
+		Reason:      string(r.Reason),
+		Lines:       make([]ReturnedLine, len(r.Lines)),
+		RequestedBy: r.RequestedBy,
+		ReviewedBy:  r.ReviewedBy,
+		ReviewNote:  r.ReviewNote,
+		ChangedAt:   r.UpdatedAt,
+	}
+	for i, l := range r.Lines {
+		e.Lines[i] = ReturnedLine{LineID: strconv.FormatInt(l.LineID, 10), ProductID: strconv.FormatInt(l.ProductID, 10), Quantity: l.Quantity, Restock: l.Restock}
+	}
+	if r.Refund != nil {
+		e.Refund = r.Refund.Amount.String()
+	}
+	return newMessage(r.OrderID, e.Event, e)
 }
 
-func PublishOrderCreated(ctx context.Context, writer *kafka.Writer, orderID int64) error {
+func newMessage(orderID int64, eventType string, event any) (entity.OutboxMessage, error) {
 
-	msg := kafka.Message{
-		Key:   []byte(strconv.Itoa(int(orderID))),
-		Value: []byte(fmt.Sprintf(`{"order_id": "%d"}`, orderID)),
+	value, err := json.Marshal(event)
+	if err != nil {
+		return entity.OutboxMessage{}, err
 	}
+	return entity.OutboxMessage{Key: strconv.FormatInt(orderID, 10), EventType: eventType, Value: value}, nil
+}
 
-	return writer.WriteMessages(ctx, msg)
+// ToKafka is the Kafka message relaying m. Relays are at least once, consumers drop redeliveries by
+// the event-id header.
+func ToKafka(m entity.OutboxMessage) kafka.Message {
+
+	return kafka.Message{
+		Key:   []byte(m.Key),
+		Value: m.Value,
+		Headers: []kafka.Header{
+			{Key: "event-id", Value: []byte(strconv.FormatInt(m.ID, 10))},
+			{Key: "event-type", Value: []byte(m.EventType)},
+			{Key: "content-type", Value: []byte("application/json")},
+		},
+	}
 }
@@ -0,0 +1,267 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var ErrUnknownPayment = errors.New("unknown payment")
+
+// CardBehavior is how the fake gateway answers for a card
+type CardBehavior string
+
+const (
+	CardApproved CardBehavior = "approve"
+	CardDeclined CardBehavior = "decline"
+	// CardRequires3DS needs the customer to complete 3-D Secure, after which the payment is authorized
+	CardRequires3DS CardBehavior = "3ds"
+)
+
+// TestCards are the cards the fake gateway knows unless told otherwise
+func TestCards() map[string]CardBehavior {
+
+	return map[string]CardBehavior{
+		"4242424242424242": CardApproved,
+		"4000000000000002": CardDeclined,
+		"4000000000003220": CardRequires3DS,
+	}
+}
+
+// ParseCards reads cards from a spec such as "4242424242424242:approve,4000000000000002:decline"
+func ParseCards(spec string) (map[string]CardBehavior, error) {
+
+	cards := make(map[string]CardBehavior)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		card, behavior, ok := strings.Cut(part, ":")
+		b := CardBehavior(strings.TrimSpace(behavior))
+		if !ok || strings.TrimSpace(card) == "" || (b != CardApproved && b != CardDeclined && b != CardRequires3DS) {
+			return nil, fmt.Errorf("invalid card %q", part)
+		}
+		cards[strings.TrimSpace(card)] = b
+	}
+	return cards, nil
+}
+
+type fakePayment struct {
+	amount   money.Money
+	status   entity.PaymentStatus
+	captured int64
+	refunded int64
+}
+
+// FakeGateway is a deterministic PaymentGateway held in memory, for local development and tests. Cards
+// behave as configured and unknown ones are declined. A payment requiring 3-D Secure is completed with
+// CompleteAction, or at the page Handler serves under actionURL, which sends the signed webhook a real
+// gateway would send to webhookURL.
+type FakeGateway struct {
+	cards      map[string]CardBehavior
+	actionURL  string
+	webhookURL string
+	secret     []byte
+	client     *http.Client
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	refunds  map[string]bool
+	events   int
+}
+
+func NewFakeGateway(cards map[string]CardBehavior, actionURL, webhookURL string, secret []byte) *FakeGateway {
+
+	return &FakeGateway{
+		cards:      cards,
+		actionURL:  strings.TrimSuffix(actionURL, "/"),
+		webhookURL: webhookURL,
+		secret:     secret,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		payments:   make(map[string]*fakePayment),
+		refunds:    make(map[string]bool),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error) {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// the reference follows from the key, so a retry finds the payment it made
+	reference := "fake_" + req.Key
+	p, ok := g.payments[reference]
+	if !ok {
+		p = &fakePayment{amount: req.Amount}
+		switch g.cards[req.PaymentMethod] {
+		case CardApproved:
+			p.status = entity.PaymentAuthorized
+		case CardRequires3DS:
+			p.status = entity.PaymentRequiresAction
+		default:
+			p.status = entity.PaymentFailed
+		}
+		g.payments[reference] = p
+	}
+
+	switch p.status {
+	case entity.PaymentFailed:
+		return nil, fmt.Errorf("%w: card declined", entity.ErrPaymentDeclined)
+	case entity.PaymentRequiresAction:
+		return &Authorization{Reference: reference, RequiresAction: true, ActionURL: g.actionURL + "/3ds/" + reference}, nil
+	default:
+		return &Authorization{Reference: reference}, nil
+	}
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount money.Money) error {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if p.status == entity.PaymentCaptured && p.captured == amount.Amount {
+		return nil
+	}
+	if p.status != entity.PaymentAuthorized || amount.Currency != p.amount.Currency || amount.Amount > p.amount.Amount {
+		return entity.ErrInvalidPaymentState
+	}
+	p.status, p.captured = entity.PaymentCaptured, amount.Amount
+	return nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string) error {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	switch p.status {
+	case entity.PaymentAuthorized, entity.PaymentRequiresAction:
+		p.status = entity.PaymentVoided
+	case entity.PaymentVoided, entity.PaymentFailed:
+	default:
+		return entity.ErrInvalidPaymentState
+	}
+	return nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference, key string, amount money.Money) error {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.refunds[key] {
+		return nil
+	}
+	p, ok := g.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if p.status != entity.PaymentCaptured || amount.Currency != p.amount.Currency {
+		return entity.ErrInvalidPaymentState
+	}
+	if amount.Amount <= 0 || p.refunded+amount.Amount > p.captured {
+		return entity.ErrRefundExceedsCaptured
+	}
+	p.refunded += amount.Amount
+	g.refunds[key] = true
+	return nil
+}
+
+// CompleteAction ends the 3-D Secure of a payment as the customer would, approving or failing it, and
+// sends the webhook telling so
+func (g *FakeGateway) CompleteAction(ctx context.Context, reference string, approve bool) error {
+
+	g.mu.Lock()
+	p, ok := g.payments[reference]
+	if !ok {
+		g.mu.Unlock()
+		return ErrUnknownPayment
+	}
+	if p.status != entity.PaymentRequiresAction {
+		g.mu.Unlock()
+		return entity.ErrInvalidPaymentState
+	}
+	g.events++
+	e := Event{ID: fmt.Sprintf("evt_fake_%d", g.events), Reference: reference}
+	if approve {
+		p.status, e.Type = entity.PaymentAuthorized, EventAuthorizationSucceeded
+	} else {
+		p.status, e.Type, e.Reason = entity.PaymentFailed, EventAuthorizationFailed, "3-D Secure failed"
+	}
+	g.mu.Unlock()
+
+	return g.send(ctx, e)
+}
+
+func (g *FakeGateway) send(ctx context.Context, e Event) error {
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(g.secret, body, time.Now()))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Handler serves the 3-D Secure pages of the payments: POST /3ds/{reference}/approve or /decline
+func (g *FakeGateway) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /3ds/{reference}/{result}", func(w http.ResponseWriter, r *http.Request) {
+
+		result := r.PathValue("result")
+		if result != "approve" && result != "decline" {
+			http.Error(w, "result must be approve or decline", http.StatusNotFound)
+			return
+		}
+
+		err := g.CompleteAction(r.Context(), r.PathValue("reference"), result == "approve")
+		switch {
+		case errors.Is(err, ErrUnknownPayment):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, entity.ErrInvalidPaymentState):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	return mux
+}
@@ -0,0 +1,95 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCards(t *testing.T) {
+
+	cards, err := ParseCards("4242424242424242:approve, 4000000000003220:3ds")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]CardBehavior{"4242424242424242": CardApproved, "4000000000003220": CardRequires3DS}, cards)
+
+	_, err = ParseCards("4242424242424242:maybe")
+	assert.NotNil(t, err)
+}
+
+func TestFakeGateway(t *testing.T) {
+
+	ctx := context.Background()
+	g := NewFakeGateway(TestCards(), "http://localhost/fake-gateway", "http://localhost/payments/webhook", []byte("whsec"))
+	amount := money.Money{Amount: 6000, Currency: "USD"}
+
+	a, err := g.Authorize(ctx, AuthorizeRequest{Key: "intent-1", Amount: amount, PaymentMethod: "4242424242424242"})
+	assert.Nil(t, err)
+	assert.False(t, a.RequiresAction)
+
+	// a retry finds the same payment
+	again, err := g.Authorize(ctx, AuthorizeRequest{Key: "intent-1", Amount: amount, PaymentMethod: "4242424242424242"})
+	assert.Nil(t, err)
+	assert.Equal(t, a, again)
+
+	assert.Nil(t, g.Capture(ctx, a.Reference, amount))
+	assert.Equal(t, entity.ErrInvalidPaymentState, g.Void(ctx, a.Reference))
+
+	assert.Nil(t, g.Refund(ctx, a.Reference, "refund-1", money.Money{Amount: 1100, Currency: "USD"}))
+	assert.Nil(t, g.Refund(ctx, a.Reference, "refund-1", money.Money{Amount: 1100, Currency: "USD"}))
+	assert.Equal(t, entity.ErrRefundExceedsCaptured, g.Refund(ctx, a.Reference, "refund-2", amount))
+
+	for _, card := range []string{"4000000000000002", "5555555555554444"} {
+		_, err = g.Authorize(ctx, AuthorizeRequest{Key: "intent-" + card, Amount: amount, PaymentMethod: card})
+		assert.True(t, errors.Is(err, entity.ErrPaymentDeclined), card)
+	}
+}
+
+func TestFakeGateway3DS(t *testing.T) {
+
+	secret := []byte("whsec")
+	events := make(chan Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifySignature(secret, r.Header.Get(SignatureHeader), body, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var e Event
+		json.Unmarshal(body, &e)
+		events <- e
+	}))
+	defer webhook.Close()
+
+	ctx := context.Background()
+	g := NewFakeGateway(TestCards(), "http://localhost/fake-gateway/", webhook.URL, secret)
+	amount := money.Money{Amount: 6000, Currency: "USD"}
+
+	a, err := g.Authorize(ctx, AuthorizeRequest{Key: "intent-2", Amount: amount, PaymentMethod: "4000000000003220"})
+	assert.Nil(t, err)
+	assert.True(t, a.RequiresAction)
+	assert.Equal(t, "http://localhost/fake-gateway/3ds/fake_intent-2", a.ActionURL)
+	assert.Equal(t, entity.ErrInvalidPaymentState, g.Capture(ctx, a.Reference, amount))
+
+	// the customer approves on the 3-D Secure page
+	page := httptest.NewServer(g.Handler())
+	defer page.Close()
+	resp, err := http.Post(page.URL+"/3ds/"+a.Reference+"/approve", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	e := <-events
+	assert.Equal(t, EventAuthorizationSucceeded, e.Type)
+	assert.Equal(t, a.Reference, e.Reference)
+	assert.Nil(t, g.Capture(ctx, a.Reference, amount))
+
+	assert.Equal(t, entity.ErrInvalidPaymentState, g.CompleteAction(ctx, a.Reference, false))
+}
@@ -0,0 +1,38 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// AuthorizeRequest asks a gateway to hold Amount on PaymentMethod. Key identifies the request: the gateway
+// answers a repeated key with the payment it made the first time.
+type AuthorizeRequest struct {
+	Key           string
+	OrderID       int64
+	Amount        money.Money
+	PaymentMethod string
+}
+
+// Authorization is the answer of a gateway to an AuthorizeRequest
+type Authorization struct {
+	// Reference identifies the payment in later calls and in webhooks
+	Reference string
+	// RequiresAction tells that the customer must complete the payment at ActionURL, e.g. for 3-D Secure;
+	// the gateway tells how it ended through a webhook
+	RequiresAction bool
+	ActionURL      string
+}
+
+// PaymentGateway is a payment provider. Authorize returns entity.ErrPaymentDeclined when the payment is
+// refused; other errors are worth retrying. Capture takes the money held, Void releases it. Refunds give
+// back captured money and are keyed, so a retried refund is paid once; voiding twice is harmless.
+type PaymentGateway interface {
+	// Name is stored with the payments made through the gateway
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error)
+	Capture(ctx context.Context, reference string, amount money.Money) error
+	Void(ctx context.Context, reference string) error
+	Refund(ctx context.Context, reference, key string, amount money.Money) error
+}
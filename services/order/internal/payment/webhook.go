@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// SignatureHeader carries the signature of a webhook: "t=<unix seconds>,v1=<hex HMAC-SHA256 of t.body>"
+const SignatureHeader = "Payment-Signature"
+
+// signatureTolerance is how old a signed webhook may be, which bounds replays of a captured one
+const signatureTolerance = 5 * time.Minute
+
+// webhook event types
+const (
+	EventAuthorizationSucceeded = "authorization.succeeded"
+	EventAuthorizationFailed    = "authorization.failed"
+)
+
+// Event is a callback of a gateway about a payment
+type Event struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	// Reason tells why an authorization failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// Sign is the signature header of body sent at now
+func Sign(secret []byte, body []byte, now time.Time) string {
+
+	t := strconv.FormatInt(now.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, body)
+}
+
+// VerifySignature checks that header signs body with secret and was made at most signatureTolerance
+// before now
+func VerifySignature(secret []byte, header string, body []byte, now time.Time) error {
+
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			v1 = v
+		}
+	}
+
+	ts, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return fmt.Errorf("%w: expired", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(v1), []byte(signature(secret, t, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signature(secret []byte, t string, body []byte) string {
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
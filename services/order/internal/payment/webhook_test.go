@@ -0,0 +1,26 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+
+	secret := []byte("whsec")
+	body := []byte(`{"id":"evt_1","type":"authorization.succeeded","reference":"fake_intent-1"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign(secret, body, now)
+
+	assert.Nil(t, VerifySignature(secret, header, body, now.Add(time.Minute)))
+
+	assert.Equal(t, ErrInvalidSignature, VerifySignature([]byte("other"), header, body, now))
+	assert.Equal(t, ErrInvalidSignature, VerifySignature(secret, header, append(body, ' '), now))
+	assert.Equal(t, ErrInvalidSignature, VerifySignature(secret, "", body, now))
+
+	err := VerifySignature(secret, header, body, now.Add(10*time.Minute))
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+}
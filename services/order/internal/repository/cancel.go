@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+)
+
+// Cancel saves the cancellation c of o: the quantities cancelled, the refund due, the status change sc of a
+// full cancellation and the events announcing them. It fails with entity.ErrOrderStatusChanged when the order
+// was changed meanwhile, e.g. by another cancellation.
+func (r *OrderRepository) Cancel(ctx context.Context, o *entity.Order, c *entity.Cancellation, sc *entity.StatusChange,
+	event func(*entity.Cancellation) (entity.OutboxMessage, error), events ...entity.OutboxMessage) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// what was refunded before c tells whether another cancellation came first
+	refundedBefore := o.RefundedTotal
+	if c.Refund != nil {
+		refundedBefore.Amount -= c.Refund.Amount.Amount
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE orders SET status = $1, refunded_total = $2, updated_at = $3
+		WHERE id = $4 AND status = $5 AND refunded_total = $6`,
+		o.Status, o.RefundedTotal.Decimal(), o.UpdatedAt, o.ID, c.From, refundedBefore.Decimal())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return orderChanged(ctx, tx, o.ID)
+	}
+
+	for _, l := range c.Lines {
+		res, err := tx.ExecContext(ctx, `UPDATE order_lines SET cancelled_quantity = cancelled_quantity + $1
+			WHERE id = $2 AND order_id = $3 AND quantity - cancelled_quantity >= $1`,
+			l.Quantity, l.LineID, o.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return entity.ErrOrderStatusChanged
+		}
+	}
+
+	var refundID *int64
+	if c.Refund != nil {
+		if err := insertRefund(ctx, tx, c.Refund); err != nil {
+			return err
+		}
+		refundID = &c.Refund.ID
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO order_cancellations (order_id, reason, note, from_status, full_cancel, refund_id, cancelled_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		c.OrderID, c.Reason, c.Note, c.From, c.Full, refundID, c.CancelledBy, c.CreatedAt).Scan(&c.ID)
+	if err != nil {
+		return err
+	}
+	for _, l := range c.Lines {
+		_, err := tx.ExecContext(ctx, "INSERT INTO order_cancellation_lines (cancellation_id, order_line_id, quantity) VALUES ($1, $2, $3)",
+			c.ID, l.LineID, l.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sc != nil {
+		if err := insertStatusChange(ctx, tx, sc); err != nil {
+			return err
+		}
+	}
+
+	m, err := event(c)
+	if err != nil {
+		return err
+	}
+	if err := insertOutbox(ctx, tx, append([]entity.OutboxMessage{m}, events...)...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+	"github.com/stretchr/testify/suite"
+)
+
+type CancelRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestCancelRepositorySuite(t *testing.T) {
+	suite.Run(t, new(CancelRepositoryTestSuite))
+}
+
+func (suite *CancelRepositoryTestSuite) SetupTest() {
+	dbConn, err := migrateOrderDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *CancelRepositoryTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+func (suite *CancelRepositoryTestSuite) TestCancelLines() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderPaid)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	c, sc, err := o.Cancel(entity.CancelOutOfStock, "", []entity.CancelLine{{LineID: o.Lines[0].ID, Quantity: 1}}, 9, true, now)
+	suite.Require().NoError(err)
+	suite.Nil(repo.Cancel(ctx, o, c, sc, producer.NewOrderCancelled))
+	suite.NotZero(c.ID)
+	suite.NotZero(c.Refund.ID)
+
+	got, err := repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(entity.OrderPaid, got.Status)
+	suite.Equal(usd(1100), got.RefundedTotal)
+	suite.Equal(1, got.Lines[0].CancelledQuantity)
+
+	// the rest, shipping included, by staff
+	c, sc, err = got.Cancel(entity.CancelOutOfStock, "", nil, 9, true, now)
+	suite.Require().NoError(err)
+	suite.Nil(repo.Cancel(ctx, got, c, sc, producer.NewOrderCancelled))
+
+	got, err = repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(entity.OrderCancelled, got.Status)
+	suite.Equal(usd(6000), got.RefundedTotal)
+
+	history, err := repo.ListStatusHistory(ctx, o.ID)
+	suite.Nil(err)
+	suite.Len(history, 1)
+	suite.Equal(entity.OrderCancelled, history[0].To)
+}
+
+func (suite *CancelRepositoryTestSuite) TestCancelOfAStaleOrder() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderPaid)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	// two cancellations read the order at the same time
+	first, err := repo.GetByID(ctx, o.ID)
+	suite.Require().NoError(err)
+	second, err := repo.GetByID(ctx, o.ID)
+	suite.Require().NoError(err)
+
+	c, sc, err := first.Cancel(entity.CancelOutOfStock, "", []entity.CancelLine{{LineID: o.Lines[0].ID, Quantity: 1}}, 9, true, now)
+	suite.Require().NoError(err)
+	suite.Nil(repo.Cancel(ctx, first, c, sc, producer.NewOrderCancelled))
+
+	c, sc, err = second.Cancel(entity.CancelOutOfStock, "", []entity.CancelLine{{LineID: o.Lines[1].ID, Quantity: 1}}, 9, true, now)
+	suite.Require().NoError(err)
+	suite.Equal(entity.ErrOrderStatusChanged, repo.Cancel(ctx, second, c, sc, producer.NewOrderCancelled))
+
+	got, err := repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(usd(1100), got.RefundedTotal)
+	suite.Equal(0, got.Lines[1].CancelledQuantity)
+
+	missing := *o
+	missing.ID = o.ID + 1
+	c, sc, err = missing.Cancel(entity.CancelOutOfStock, "", nil, 9, true, now)
+	suite.Require().NoError(err)
+	suite.Equal(entity.ErrOrderNotFound, repo.Cancel(ctx, &missing, c, sc, producer.NewOrderCancelled))
+}
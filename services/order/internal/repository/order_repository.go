@@ -1,8 +1,14 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 )
 
 type OrderRepository struct {
@@ -13,8 +19,199 @@ func NewOrderRepository(db *sqlx.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) Create(order *entity.Order) error {
-	_, err := r.db.Exec("INSERT INTO orders (product_id, quantity, total) VALUES ($1, $2, $3)",
-		order.ProductID, order.Quantity, order.Total)
-	return err
+// skipLocked ends the subquery picking the rows a claim leases, so concurrent claims take different rows.
+// SQLite, which the tests run on, has no row locks; it runs one writer at a time instead.
+func (r *OrderRepository) skipLocked() string {
+
+	if r.db.DriverName() == "sqlite3" {
+		return ""
+	}
+	return "FOR UPDATE SKIP LOCKED"
+}
+
+// Create stores the order and its lines in one transaction, setting their ids, together with its checkout,
+// its payment and the event announcing the order
+func (r *OrderRepository) Create(ctx context.Context, order *entity.Order, saga *entity.CheckoutSaga, payment *entity.PaymentIntent,
+	event func(*entity.Order) (entity.OutboxMessage, error)) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO orders (user_id, status, currency, subtotal, discount_total, tax_total, shipping_total, total, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+		order.UserID, order.Status, order.Currency, order.Subtotal.Decimal(), order.DiscountTotal.Decimal(), order.TaxTotal.Decimal(),
+		order.ShippingTotal.Decimal(), order.Total.Decimal(), order.CreatedAt, order.UpdatedAt).Scan(&order.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range order.Lines {
+		l := &order.Lines[i]
+		l.OrderID = order.ID
+		err = tx.QueryRowContext(ctx, `INSERT INTO order_lines (order_id, product_id, sku, quantity, unit_price, discount, line_total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			l.OrderID, l.ProductID, l.SKU, l.Quantity, l.UnitPrice.Decimal(), l.Discount.Decimal(), l.LineTotal.Decimal()).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	saga.OrderID = order.ID
+	if err := insertSaga(ctx, tx, saga); err != nil {
+		return err
+	}
+	payment.OrderID = order.ID
+	if err := insertPayment(ctx, tx, payment); err != nil {
+		return err
+	}
+
+	m, err := event(order)
+	if err != nil {
+		return err
+	}
+	if err := insertOutbox(ctx, tx, m); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// orderColumns lists the columns read by scanOrder, in order
+const orderColumns = "o.id, o.user_id, o.status, o.currency, o.subtotal, o.discount_total, o.tax_total, o.shipping_total, o.total, o.refunded_total, o.created_at, o.updated_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrder(row scanner) (*entity.Order, error) {
+
+	var o entity.Order
+	var subtotal, discount, tax, shipping, total, refunded string
+	err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.Currency, &subtotal, &discount, &tax, &shipping, &total, &refunded, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range []struct {
+		dest  *money.Money
+		value string
+	}{{&o.Subtotal, subtotal}, {&o.DiscountTotal, discount}, {&o.TaxTotal, tax}, {&o.ShippingTotal, shipping}, {&o.Total, total}, {&o.RefundedTotal, refunded}} {
+		if *f.dest, err = money.Parse(f.value, o.Currency); err != nil {
+			return nil, err
+		}
+	}
+	return &o, nil
+}
+
+// GetByID returns the order with its lines, nil when there is none
+func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*entity.Order, error) {
+
+	o, err := scanOrder(r.db.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM orders o WHERE o.id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, []*entity.Order{o}); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// List returns the orders matching q with their lines, newest first
+func (r *OrderRepository) List(ctx context.Context, q entity.OrderQuery) ([]entity.Order, error) {
+
+	var afterAt *time.Time
+	var afterID int64
+	if q.After != nil {
+		afterAt, afterID = &q.After.CreatedAt, q.After.ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+orderColumns+` FROM orders o
+		WHERE ($1 = 0 OR o.user_id = $1)
+		AND ($2 = '' OR o.status = $2)
+		AND ($3::timestamptz IS NULL OR o.created_at >= $3)
+		AND ($4::timestamptz IS NULL OR o.created_at < $4)
+		AND ($5 = 0 OR EXISTS (SELECT 1 FROM order_lines l WHERE l.order_id = o.id AND l.product_id = $5))
+		AND ($6 = 0 OR (o.created_at, o.id) < ($7, $6))
+		ORDER BY o.created_at DESC, o.id DESC LIMIT $8`,
+		q.UserID, q.Status, q.From, q.To, q.ProductID, afterID, afterAt, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*entity.Order, len(list))
+	for i := range list {
+		orders[i] = &list[i]
+	}
+	if err := r.loadLines(ctx, orders); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// loadLines fills the lines of orders in one query
+func (r *OrderRepository) loadLines(ctx context.Context, orders []*entity.Order) error {
+
+	if len(orders) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(orders))
+	byID := make(map[int64]*entity.Order, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+		byID[o.ID] = o
+	}
+
+	query, args, err := sqlx.In(`SELECT id, order_id, product_id, sku, quantity, cancelled_quantity, returning_quantity, returned_quantity,
+		unit_price, discount, line_total
+		FROM order_lines WHERE order_id IN (?) ORDER BY order_id, id`, ids)
+	if err != nil {
+		return err
+	}
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l entity.OrderLine
+		var unitPrice, discount, lineTotal string
+		err := rows.Scan(&l.ID, &l.OrderID, &l.ProductID, &l.SKU, &l.Quantity, &l.CancelledQuantity, &l.ReturningQuantity, &l.ReturnedQuantity,
+			&unitPrice, &discount, &lineTotal)
+		if err != nil {
+			return err
+		}
+		o := byID[l.OrderID]
+		if l.UnitPrice, err = money.Parse(unitPrice, o.Currency); err != nil {
+			return err
+		}
+		if l.Discount, err = money.Parse(discount, o.Currency); err != nil {
+			return err
+		}
+		if l.LineTotal, err = money.Parse(lineTotal, o.Currency); err != nil {
+			return err
+		}
+		o.Lines = append(o.Lines, l)
+	}
+	return rows.Err()
 }
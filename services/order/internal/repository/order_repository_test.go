@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/suite"
+)
+
+// migrateOrderDB creates the tables of the migrations in an in-memory SQLite database
+func migrateOrderDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// every connection would open a database of its own
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE orders (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    subtotal NUMERIC NOT NULL DEFAULT 0,
+    discount_total NUMERIC NOT NULL DEFAULT 0,
+    tax_total NUMERIC NOT NULL DEFAULT 0,
+    shipping_total NUMERIC NOT NULL DEFAULT 0,
+    total NUMERIC NOT NULL DEFAULT 0,
+    refunded_total NUMERIC NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE order_lines (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL,
+    product_id integer NOT NULL,
+    sku TEXT NOT NULL,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    cancelled_quantity integer NOT NULL DEFAULT 0 CHECK (cancelled_quantity >= 0 AND cancelled_quantity <= quantity),
+    returning_quantity integer NOT NULL DEFAULT 0 CHECK (returning_quantity >= 0),
+    returned_quantity integer NOT NULL DEFAULT 0 CHECK (returned_quantity >= 0),
+    unit_price NUMERIC NOT NULL,
+    discount NUMERIC NOT NULL DEFAULT 0,
+    line_total NUMERIC NOT NULL,
+    UNIQUE (order_id, product_id),
+    CHECK (cancelled_quantity + returning_quantity + returned_quantity <= quantity)
+);
+CREATE TABLE order_status_history (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_by integer NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE checkout_sagas (
+    order_id integer PRIMARY KEY,
+    step TEXT NOT NULL DEFAULT 'started',
+    payment_reference TEXT NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    deadline DATETIME NOT NULL,
+    locked_until DATETIME NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE payment_intents (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL UNIQUE,
+    gateway TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    payment_method TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    amount NUMERIC NOT NULL,
+    captured_amount NUMERIC NOT NULL DEFAULT 0,
+    refunded_amount NUMERIC NOT NULL DEFAULT 0,
+    next_action_url TEXT NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE order_outbox (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    value BLOB NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at DATETIME
+);
+CREATE TABLE order_refunds (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL,
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    locked_until DATETIME NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    refunded_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE order_product_updates (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL,
+    kind TEXT NOT NULL,
+    locked_until DATETIME NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    done_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, kind)
+);
+CREATE TABLE order_cancellations (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL,
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    from_status TEXT NOT NULL,
+    full_cancel BOOLEAN NOT NULL DEFAULT false,
+    refund_id integer,
+    cancelled_by integer NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE order_cancellation_lines (
+    cancellation_id integer NOT NULL,
+    order_line_id integer NOT NULL,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (cancellation_id, order_line_id)
+);
+CREATE TABLE order_returns (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    order_id integer NOT NULL,
+    status TEXT NOT NULL DEFAULT 'requested',
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    requested_by integer NOT NULL,
+    reviewed_by integer NOT NULL DEFAULT 0,
+    review_note TEXT NOT NULL DEFAULT '',
+    refund_id integer,
+    restocked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE order_return_lines (
+    return_id integer NOT NULL,
+    order_line_id integer NOT NULL,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    restock BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (return_id, order_line_id)
+);`)
+
+	return db, err
+}
+
+func usd(amount int64) money.Money {
+	return money.Money{Amount: amount, Currency: "USD"}
+}
+
+// createOrder stores an order at status of two lines, 2 x 10.00 and 1 x 30.00; with 10% tax and 5.00 of
+// shipping it totals 60.00
+func createOrder(s *suite.Suite, repo *OrderRepository, status entity.OrderStatus) *entity.Order {
+
+	a, _ := entity.NewOrderLine(1, "A", 2, usd(1000), nil)
+	b, _ := entity.NewOrderLine(2, "B", 1, usd(3000), nil)
+	policy := entity.PricingPolicy{TaxRate: 1000, ShippingFees: map[string]money.Money{"USD": usd(500)}}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	o, err := entity.NewOrder(7, "USD", []entity.OrderLine{a, b}, policy, now)
+	s.Require().NoError(err)
+	o.Status = status
+	p, err := entity.NewPaymentIntent(0, "fake", "pm_card", o.Total, now)
+	s.Require().NoError(err)
+
+	err = repo.Create(context.Background(), o, entity.NewCheckoutSaga(0, now, 15*time.Minute, time.Minute), p, producer.NewOrderCreated)
+	s.Require().NoError(err)
+	return o
+}
+
+type OrderRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestOrderRepositorySuite(t *testing.T) {
+	suite.Run(t, new(OrderRepositoryTestSuite))
+}
+
+func (suite *OrderRepositoryTestSuite) SetupTest() {
+	dbConn, err := migrateOrderDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *OrderRepositoryTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+func (suite *OrderRepositoryTestSuite) TestCreate() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+
+	o := createOrder(&suite.Suite, repo, entity.OrderPending)
+	suite.NotZero(o.ID)
+
+	got, err := repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(usd(6000), got.Total)
+	suite.Len(got.Lines, 2)
+	suite.Equal(usd(1000), got.Lines[0].UnitPrice)
+
+	saga, err := repo.GetSaga(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(entity.SagaStarted, saga.Step)
+
+	missing, err := repo.GetByID(ctx, o.ID+1)
+	suite.Nil(err)
+	suite.Nil(missing)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+)
+
+// SetStatus applies c to the order and records it in the status history with the update the product
+// service needs, if any, and the events announcing it, provided the order still has the status c moves it
+// from; otherwise it fails with entity.ErrOrderStatusChanged
+func (r *OrderRepository) SetStatus(ctx context.Context, c *entity.StatusChange, u *entity.ProductUpdate, events ...entity.OutboxMessage) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4",
+		c.To, c.ChangedAt, c.OrderID, c.From)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return orderChanged(ctx, tx, c.OrderID)
+	}
+
+	if err := insertStatusChange(ctx, tx, c); err != nil {
+		return err
+	}
+	if u != nil {
+		if err := insertProductUpdate(ctx, tx, u); err != nil {
+			return err
+		}
+	}
+	if err := insertOutbox(ctx, tx, events...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// orderChanged tells why a conditional update of order id matched no row
+func orderChanged(ctx context.Context, tx *sqlx.Tx, id int64) error {
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return entity.ErrOrderNotFound
+	}
+	return entity.ErrOrderStatusChanged
+}
+
+func insertStatusChange(ctx context.Context, tx *sqlx.Tx, c *entity.StatusChange) error {
+
+	return tx.QueryRowContext(ctx, `INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		c.OrderID, c.From, c.To, c.ChangedBy, c.Reason, c.ChangedAt).Scan(&c.ID)
+}
+
+// ListStatusHistory returns the status changes of an order, oldest first
+func (r *OrderRepository) ListStatusHistory(ctx context.Context, orderID int64) ([]entity.StatusChange, error) {
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, order_id, from_status, to_status, changed_by, reason, changed_at
+		FROM order_status_history WHERE order_id = $1 ORDER BY changed_at, id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.StatusChange
+	for rows.Next() {
+		var c entity.StatusChange
+		if err := rows.Scan(&c.ID, &c.OrderID, &c.From, &c.To, &c.ChangedBy, &c.Reason, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
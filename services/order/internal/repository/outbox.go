@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+)
+
+// insertOutbox stores messages in tx, so they are relayed only if tx commits
+func insertOutbox(ctx context.Context, tx *sqlx.Tx, messages ...entity.OutboxMessage) error {
+
+	for _, m := range messages {
+		_, err := tx.ExecContext(ctx, "INSERT INTO order_outbox (key, event_type, value) VALUES ($1, $2, $3)",
+			m.Key, m.EventType, m.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimOutbox leases up to limit messages due at now to the caller until now+lease, after which another relay
+// may claim them again. Only the oldest unsent message of each key is claimed, so a message is never
+// relayed before an earlier one of the same order.
+func (r *OrderRepository) ClaimOutbox(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.OutboxMessage, error) {
+
+	rows, err := r.db.QueryContext(ctx, `UPDATE order_outbox SET attempts = attempts + 1, next_attempt_at = $1
+		WHERE id IN (
+			SELECT o.id FROM order_outbox o
+			WHERE o.sent_at IS NULL AND o.next_attempt_at <= $2
+			AND NOT EXISTS (SELECT 1 FROM order_outbox e WHERE e.key = o.key AND e.sent_at IS NULL AND e.id < o.id)
+			ORDER BY o.id LIMIT $3
+			`+r.skipLocked()+`
+		)
+		RETURNING id, key, event_type, value, attempts, created_at`,
+		now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.OutboxMessage
+	for rows.Next() {
+		var m entity.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.Key, &m.EventType, &m.Value, &m.Attempts, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING has no order
+	slices.SortFunc(list, func(a, b entity.OutboxMessage) int { return cmp.Compare(a.ID, b.ID) })
+	return list, nil
+}
+
+func (r *OrderRepository) MarkOutboxSent(ctx context.Context, ids []int64, now time.Time) error {
+
+	query, args, err := sqlx.In("UPDATE order_outbox SET sent_at = ?, last_error = '' WHERE id IN (?)", now, ids)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+// MarkOutboxFailed schedules the next relay of a message
+func (r *OrderRepository) MarkOutboxFailed(ctx context.Context, id int64, next time.Time, reason string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE order_outbox SET next_attempt_at = $1, last_error = $2 WHERE id = $3", next, reason, id)
+	return err
+}
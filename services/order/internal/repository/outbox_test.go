@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestOutboxRepositorySuite(t *testing.T) {
+	suite.Run(t, new(OutboxRepositoryTestSuite))
+}
+
+func (suite *OutboxRepositoryTestSuite) SetupTest() {
+	dbConn, err := migrateOrderDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *OutboxRepositoryTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+func (suite *OutboxRepositoryTestSuite) insert(messages ...entity.OutboxMessage) {
+
+	tx := suite.DB.MustBegin()
+	suite.Require().NoError(insertOutbox(context.Background(), tx, messages...))
+	suite.Require().NoError(tx.Commit())
+}
+
+func outboxMessage(key, eventType string) entity.OutboxMessage {
+	return entity.OutboxMessage{Key: key, EventType: eventType, Value: []byte("{}")}
+}
+
+func claimedTypes(list []entity.OutboxMessage) []string {
+
+	types := make([]string, len(list))
+	for i, m := range list {
+		types[i] = m.EventType
+	}
+	return types
+}
+
+func (suite *OutboxRepositoryTestSuite) TestClaimOutboxOldestPerKey() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	suite.insert(outboxMessage("1", "created"), outboxMessage("2", "created"), outboxMessage("1", "paid"))
+	now := time.Now().UTC()
+
+	// "paid" of order 1 waits for its "created"
+	list, err := repo.ClaimOutbox(ctx, now, 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Equal([]string{"created", "created"}, claimedTypes(list))
+	suite.Equal("1", list[0].Key)
+	suite.Equal(1, list[0].Attempts)
+
+	suite.Nil(repo.MarkOutboxSent(ctx, []int64{list[0].ID}, now))
+
+	// the message of order 2 is still leased
+	list, err = repo.ClaimOutbox(ctx, now, 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Equal([]string{"paid"}, claimedTypes(list))
+	suite.Equal("1", list[0].Key)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestClaimOutboxAgainAfterLease() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	suite.insert(outboxMessage("1", "created"))
+	now := time.Now().UTC()
+
+	first, err := repo.ClaimOutbox(ctx, now, 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Len(first, 1)
+
+	// the relay holding it crashed before marking it
+	list, err := repo.ClaimOutbox(ctx, now.Add(29*time.Second), 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Empty(list)
+
+	list, err = repo.ClaimOutbox(ctx, now.Add(30*time.Second), 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Len(list, 1)
+	suite.Equal(first[0].ID, list[0].ID)
+	suite.Equal(2, list[0].Attempts)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestMarkOutboxFailed() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	suite.insert(outboxMessage("1", "created"))
+	now := time.Now().UTC()
+
+	list, err := repo.ClaimOutbox(ctx, now, 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Nil(repo.MarkOutboxFailed(ctx, list[0].ID, now.Add(time.Second), "broker down"))
+
+	var lastError string
+	suite.Nil(suite.DB.Get(&lastError, "SELECT last_error FROM order_outbox WHERE id = $1", list[0].ID))
+	suite.Equal("broker down", lastError)
+
+	// rescheduled before the lease would have run out
+	list, err = repo.ClaimOutbox(ctx, now.Add(time.Second), 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Len(list, 1)
+
+	suite.Nil(repo.MarkOutboxSent(ctx, []int64{list[0].ID}, now))
+	list, err = repo.ClaimOutbox(ctx, now.Add(time.Hour), 30*time.Second, 10)
+	suite.Nil(err)
+	suite.Empty(list)
+}
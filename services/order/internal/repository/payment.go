@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// paymentColumns lists the columns read by scanPayment, in order
+const paymentColumns = `p.id, p.order_id, p.gateway, p.reference, p.payment_method, p.status, o.currency, p.amount, p.captured_amount,
+	p.refunded_amount, p.next_action_url, p.failure_reason, p.version, p.created_at, p.updated_at`
+
+func scanPayment(row scanner) (*entity.PaymentIntent, error) {
+
+	var p entity.PaymentIntent
+	var currency, amount, captured, refunded string
+	err := row.Scan(&p.ID, &p.OrderID, &p.Gateway, &p.Reference, &p.PaymentMethod, &p.Status, &currency, &amount, &captured,
+		&refunded, &p.NextActionURL, &p.FailureReason, &p.Version, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range []struct {
+		dest  *money.Money
+		value string
+	}{{&p.Amount, amount}, {&p.CapturedAmount, captured}, {&p.RefundedAmount, refunded}} {
+		if *f.dest, err = money.Parse(f.value, currency); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+func insertPayment(ctx context.Context, tx *sqlx.Tx, p *entity.PaymentIntent) error {
+
+	return tx.QueryRowContext(ctx, `INSERT INTO payment_intents (order_id, gateway, payment_method, status, amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		p.OrderID, p.Gateway, p.PaymentMethod, p.Status, p.Amount.Decimal(), p.CreatedAt, p.UpdatedAt).Scan(&p.ID)
+}
+
+// GetPayment returns the payment of an order, nil when there is none
+func (r *OrderRepository) GetPayment(ctx context.Context, orderID int64) (*entity.PaymentIntent, error) {
+
+	p, err := scanPayment(r.db.QueryRowContext(ctx, "SELECT "+paymentColumns+` FROM payment_intents p
+		JOIN orders o ON o.id = p.order_id WHERE p.order_id = $1`, orderID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+// GetPaymentByReference returns the payment the gateway knows by reference, nil when there is none
+func (r *OrderRepository) GetPaymentByReference(ctx context.Context, gateway, reference string) (*entity.PaymentIntent, error) {
+
+	p, err := scanPayment(r.db.QueryRowContext(ctx, "SELECT "+paymentColumns+` FROM payment_intents p
+		JOIN orders o ON o.id = p.order_id WHERE p.gateway = $1 AND p.reference = $2`, gateway, reference))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+// UpdatePayment saves p, provided nobody else did since it was read; otherwise it fails with
+// entity.ErrPaymentChanged
+func (r *OrderRepository) UpdatePayment(ctx context.Context, p *entity.PaymentIntent) error {
+	return updatePayment(ctx, r.db, p)
+}
+
+// RecordRefund saves p, refunded by refund f, and marks f as issued
+func (r *OrderRepository) RecordRefund(ctx context.Context, p *entity.PaymentIntent, f *entity.Refund, now time.Time) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updatePayment(ctx, tx, p); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE order_refunds SET refunded_at = $1, locked_until = $1 WHERE id = $2", now, f.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func updatePayment(ctx context.Context, db sqlx.ExtContext, p *entity.PaymentIntent) error {
+
+	res, err := db.ExecContext(ctx, `UPDATE payment_intents SET reference = $1, status = $2, captured_amount = $3, refunded_amount = $4,
+		next_action_url = $5, failure_reason = $6, version = version + 1, updated_at = $7 WHERE id = $8 AND version = $9`,
+		p.Reference, p.Status, p.CapturedAmount.Decimal(), p.RefundedAmount.Decimal(), p.NextActionURL, p.FailureReason,
+		p.UpdatedAt, p.ID, p.Version)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return entity.ErrPaymentChanged
+	}
+	p.Version++
+	return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+)
+
+func insertProductUpdate(ctx context.Context, tx *sqlx.Tx, u *entity.ProductUpdate) error {
+
+	return tx.QueryRowContext(ctx, `INSERT INTO order_product_updates (order_id, kind, locked_until, created_at)
+		VALUES ($1, $2, $3, $4) RETURNING id`,
+		u.OrderID, u.Kind, u.LockedUntil, u.CreatedAt).Scan(&u.ID)
+}
+
+// ClaimDueProductUpdates leases to the caller, until now+lease, up to limit product updates not done yet
+// whose lease ran out: their instance crashed or the product service failed
+func (r *OrderRepository) ClaimDueProductUpdates(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.ProductUpdate, error) {
+
+	rows, err := r.db.QueryContext(ctx, `UPDATE order_product_updates SET locked_until = $1, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM order_product_updates
+			WHERE done_at IS NULL AND locked_until <= $2
+			ORDER BY locked_until LIMIT $3
+			`+r.skipLocked()+`
+		)
+		RETURNING id, order_id, kind, locked_until, attempts, created_at`,
+		now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.ProductUpdate
+	for rows.Next() {
+		var u entity.ProductUpdate
+		if err := rows.Scan(&u.ID, &u.OrderID, &u.Kind, &u.LockedUntil, &u.Attempts, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, rows.Err()
+}
+
+// MarkProductUpdateDone records the product service learnt of update id
+func (r *OrderRepository) MarkProductUpdateDone(ctx context.Context, id int64, now time.Time) error {
+
+	_, err := r.db.ExecContext(ctx, "UPDATE order_product_updates SET done_at = $1 WHERE id = $2 AND done_at IS NULL", now, id)
+	return err
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProductUpdateRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestProductUpdateRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ProductUpdateRepositoryTestSuite))
+}
+
+func (suite *ProductUpdateRepositoryTestSuite) SetupTest() {
+	dbConn, err := migrateOrderDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *ProductUpdateRepositoryTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+// ship moves o to shipped, saving the update that commits its stock
+func (suite *ProductUpdateRepositoryTestSuite) ship(repo *OrderRepository, o *entity.Order, now time.Time) *entity.ProductUpdate {
+
+	c, err := o.Transition(entity.OrderShipped, 0, "", now)
+	suite.Require().NoError(err)
+	u := entity.ProductUpdateFor(c, time.Minute)
+	suite.Require().NoError(repo.SetStatus(context.Background(), c, u))
+	return u
+}
+
+func (suite *ProductUpdateRepositoryTestSuite) TestSetStatusSavesTheUpdate() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderFulfilling)
+	now := time.Now().UTC()
+
+	u := suite.ship(repo, o, now)
+	suite.NotZero(u.ID)
+	suite.Equal(entity.UpdateCommitStock, u.Kind)
+
+	// leased to the instance that shipped the order
+	list, err := repo.ClaimDueProductUpdates(ctx, now, time.Minute, 10)
+	suite.Nil(err)
+	suite.Empty(list)
+
+	list, err = repo.ClaimDueProductUpdates(ctx, now.Add(time.Minute), time.Minute, 10)
+	suite.Nil(err)
+	suite.Len(list, 1)
+	suite.Equal(o.ID, list[0].OrderID)
+	suite.Equal(entity.UpdateCommitStock, list[0].Kind)
+	suite.Equal(1, list[0].Attempts)
+}
+
+func (suite *ProductUpdateRepositoryTestSuite) TestMarkProductUpdateDone() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderFulfilling)
+	now := time.Now().UTC()
+
+	u := suite.ship(repo, o, now)
+	suite.Nil(repo.MarkProductUpdateDone(ctx, u.ID, now))
+
+	list, err := repo.ClaimDueProductUpdates(ctx, now.Add(time.Hour), time.Minute, 10)
+	suite.Nil(err)
+	suite.Empty(list)
+}
+
+func (suite *ProductUpdateRepositoryTestSuite) TestOtherStatusesSaveNoUpdate() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderPaid)
+	now := time.Now().UTC()
+
+	c, err := o.Transition(entity.OrderFulfilling, 0, "", now)
+	suite.Require().NoError(err)
+	suite.Nil(entity.ProductUpdateFor(c, time.Minute))
+	suite.Nil(repo.SetStatus(ctx, c, nil))
+
+	list, err := repo.ClaimDueProductUpdates(ctx, now.Add(time.Hour), time.Minute, 10)
+	suite.Nil(err)
+	suite.Empty(list)
+}
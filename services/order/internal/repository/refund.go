@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+func insertRefund(ctx context.Context, tx *sqlx.Tx, f *entity.Refund) error {
+
+	return tx.QueryRowContext(ctx, `INSERT INTO order_refunds (order_id, amount, reason, locked_until, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		f.OrderID, f.Amount.Decimal(), f.Reason, f.LockedUntil, f.CreatedAt).Scan(&f.ID)
+}
+
+// RefundOrder saves o, moved to refunded by c, with its refund f, if any, and the events announcing it. It
+// fails with entity.ErrOrderStatusChanged when the order was changed meanwhile, e.g. by a cancellation.
+func (r *OrderRepository) RefundOrder(ctx context.Context, o *entity.Order, c *entity.StatusChange, f *entity.Refund, events ...entity.OutboxMessage) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	refundedBefore := o.RefundedTotal
+	if f != nil {
+		refundedBefore.Amount -= f.Amount.Amount
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE orders SET status = $1, refunded_total = $2, updated_at = $3
+		WHERE id = $4 AND status = $5 AND refunded_total = $6`,
+		o.Status, o.RefundedTotal.Decimal(), o.UpdatedAt, o.ID, c.From, refundedBefore.Decimal())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return orderChanged(ctx, tx, o.ID)
+	}
+
+	if err := insertStatusChange(ctx, tx, c); err != nil {
+		return err
+	}
+	if f != nil {
+		if err := insertRefund(ctx, tx, f); err != nil {
+			return err
+		}
+	}
+	if err := insertOutbox(ctx, tx, events...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDueRefunds leases to the caller, until now+lease, up to limit refunds not issued yet whose lease
+// ran out: their instance crashed or the payment provider failed
+func (r *OrderRepository) ClaimDueRefunds(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.Refund, error) {
+
+	rows, err := r.db.QueryContext(ctx, `UPDATE order_refunds f SET locked_until = $2, attempts = attempts + 1
+		FROM orders o
+		WHERE o.id = f.order_id AND f.id IN (
+			SELECT id FROM order_refunds
+			WHERE refunded_at IS NULL AND locked_until <= $1
+			ORDER BY locked_until LIMIT $3
+			`+r.skipLocked()+`
+		)
+		RETURNING f.id, f.order_id, f.amount, o.currency, f.reason, f.locked_until, f.attempts, f.created_at`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.Refund
+	for rows.Next() {
+		var f entity.Refund
+		var amount, currency string
+		if err := rows.Scan(&f.ID, &f.OrderID, &amount, &currency, &f.Reason, &f.LockedUntil, &f.Attempts, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		if f.Amount, err = money.Parse(amount, currency); err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
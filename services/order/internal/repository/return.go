@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// returnColumns lists the columns read by scanReturn, in order
+const returnColumns = `r.id, r.order_id, r.status, r.reason, r.note, r.requested_by, r.reviewed_by, r.review_note, r.restocked_at,
+	r.created_at, r.updated_at, f.id, f.amount, f.reason, f.refunded_at, f.created_at, o.currency`
+
+const returnFrom = ` FROM order_returns r JOIN orders o ON o.id = r.order_id LEFT JOIN order_refunds f ON f.id = r.refund_id`
+
+func scanReturn(row scanner) (*entity.Return, error) {
+
+	var rt entity.Return
+	var refundID sql.NullInt64
+	var amount, reason sql.NullString
+	var refundedAt, refundCreatedAt sql.NullTime
+	var currency string
+	err := row.Scan(&rt.ID, &rt.OrderID, &rt.Status, &rt.Reason, &rt.Note, &rt.RequestedBy, &rt.ReviewedBy, &rt.ReviewNote, &rt.RestockedAt,
+		&rt.CreatedAt, &rt.UpdatedAt, &refundID, &amount, &reason, &refundedAt, &refundCreatedAt, &currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if refundID.Valid {
+		f := &entity.Refund{ID: refundID.Int64, OrderID: rt.OrderID, Reason: reason.String, CreatedAt: refundCreatedAt.Time}
+		if f.Amount, err = money.Parse(amount.String, currency); err != nil {
+			return nil, err
+		}
+		if refundedAt.Valid {
+			f.RefundedAt = &refundedAt.Time
+		}
+		rt.Refund = f
+	}
+	return &rt, nil
+}
+
+// CreateReturn saves the return r of o, holding its quantities on the lines, and the event announcing it.
+// It fails with entity.ErrOrderStatusChanged when the order was changed meanwhile, e.g. by another return.
+func (r *OrderRepository) CreateReturn(ctx context.Context, o *entity.Order, rt *entity.Return, event func(*entity.Return) (entity.OutboxMessage, error)) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE orders SET updated_at = $1 WHERE id = $2 AND status = $3", o.UpdatedAt, o.ID, entity.OrderDelivered)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return orderChanged(ctx, tx, o.ID)
+	}
+
+	for _, l := range rt.Lines {
+		res, err := tx.ExecContext(ctx, `UPDATE order_lines SET returning_quantity = returning_quantity + $1
+			WHERE id = $2 AND order_id = $3 AND quantity - cancelled_quantity - returning_quantity - returned_quantity >= $1`,
+			l.Quantity, l.LineID, o.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return entity.ErrOrderStatusChanged
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO order_returns (order_id, status, reason, note, requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		rt.OrderID, rt.Status, rt.Reason, rt.Note, rt.RequestedBy, rt.CreatedAt, rt.UpdatedAt).Scan(&rt.ID)
+	if err != nil {
+		return err
+	}
+	for _, l := range rt.Lines {
+		_, err := tx.ExecContext(ctx, "INSERT INTO order_return_lines (return_id, order_line_id, quantity) VALUES ($1, $2, $3)",
+			rt.ID, l.LineID, l.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertReturnEvent(ctx, tx, rt, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReviewReturn saves the approval or rejection of rt, which stood at from, and the event announcing it.
+// A rejection gives the quantities it held back to the lines. It fails with entity.ErrReturnChanged when
+// the return was moved on meanwhile.
+func (r *OrderRepository) ReviewReturn(ctx context.Context, rt *entity.Return, from entity.ReturnStatus, event func(*entity.Return) (entity.OutboxMessage, error)) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateReturn(ctx, tx, rt, from); err != nil {
+		return err
+	}
+	if rt.Status == entity.ReturnRejected {
+		for _, l := range rt.Lines {
+			_, err := tx.ExecContext(ctx, "UPDATE order_lines SET returning_quantity = returning_quantity - $1 WHERE id = $2",
+				l.Quantity, l.LineID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := insertReturnEvent(ctx, tx, rt, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReceiveReturn saves the receipt of rt: the quantities returned and restocked, the refund due, the status
+// change sc of an order returned whole and the events announcing them. It fails with entity.ErrReturnChanged
+// when the return was moved on meanwhile, entity.ErrOrderStatusChanged when the order was.
+func (r *OrderRepository) ReceiveReturn(ctx context.Context, o *entity.Order, rt *entity.Return, sc *entity.StatusChange,
+	event func(*entity.Return) (entity.OutboxMessage, error), events ...entity.OutboxMessage) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// what was refunded before rt tells whether another refund came first
+	refundedBefore := o.RefundedTotal
+	if rt.Refund != nil {
+		refundedBefore.Amount -= rt.Refund.Amount.Amount
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE orders SET status = $1, refunded_total = $2, updated_at = $3
+		WHERE id = $4 AND status = $5 AND refunded_total = $6`,
+		o.Status, o.RefundedTotal.Decimal(), o.UpdatedAt, o.ID, entity.OrderDelivered, refundedBefore.Decimal())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return orderChanged(ctx, tx, o.ID)
+	}
+
+	if rt.Refund != nil {
+		if err := insertRefund(ctx, tx, rt.Refund); err != nil {
+			return err
+		}
+	}
+	if err := updateReturn(ctx, tx, rt, entity.ReturnApproved); err != nil {
+		return err
+	}
+	for _, l := range rt.Lines {
+		_, err := tx.ExecContext(ctx, `UPDATE order_lines SET returning_quantity = returning_quantity - $1, returned_quantity = returned_quantity + $1
+			WHERE id = $2`, l.Quantity, l.LineID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE order_return_lines SET restock = $1 WHERE return_id = $2 AND order_line_id = $3",
+			l.Restock, rt.ID, l.LineID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sc != nil {
+		if err := insertStatusChange(ctx, tx, sc); err != nil {
+			return err
+		}
+	}
+
+	m, err := event(rt)
+	if err != nil {
+		return err
+	}
+	if err := insertOutbox(ctx, tx, append([]entity.OutboxMessage{m}, events...)...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateReturn saves the review of rt provided it still stands at from
+func updateReturn(ctx context.Context, tx *sqlx.Tx, rt *entity.Return, from entity.ReturnStatus) error {
+
+	var refundID *int64
+	if rt.Refund != nil {
+		refundID = &rt.Refund.ID
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE order_returns SET status = $1, reviewed_by = $2, review_note = $3, refund_id = $4,
+		restocked_at = $5, updated_at = $6 WHERE id = $7 AND status = $8`,
+		rt.Status, rt.ReviewedBy, rt.ReviewNote, refundID, rt.RestockedAt, rt.UpdatedAt, rt.ID, from)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return entity.ErrReturnChanged
+	}
+	return nil
+}
+
+func insertReturnEvent(ctx context.Context, tx *sqlx.Tx, rt *entity.Return, event func(*entity.Return) (entity.OutboxMessage, error)) error {
+
+	m, err := event(rt)
+	if err != nil {
+		return err
+	}
+	return insertOutbox(ctx, tx, m)
+}
+
+// GetReturn returns a return with its lines, nil when there is none
+func (r *OrderRepository) GetReturn(ctx context.Context, id int64) (*entity.Return, error) {
+
+	rt, err := scanReturn(r.db.QueryRowContext(ctx, "SELECT "+returnColumns+returnFrom+" WHERE r.id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadReturnLines(ctx, []*entity.Return{rt}); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// ListReturns returns the returns of an order with their lines, oldest first
+func (r *OrderRepository) ListReturns(ctx context.Context, orderID int64) ([]entity.Return, error) {
+	return r.listReturns(ctx, "SELECT "+returnColumns+returnFrom+" WHERE r.order_id = $1 ORDER BY r.created_at, r.id", orderID)
+}
+
+// ListUnrestocked returns up to limit returns received before before whose goods are not back in stock
+// yet: their instance crashed or the product service failed
+func (r *OrderRepository) ListUnrestocked(ctx context.Context, before time.Time, limit int) ([]entity.Return, error) {
+	return r.listReturns(ctx, "SELECT "+returnColumns+returnFrom+` WHERE r.status = $1 AND r.restocked_at IS NULL AND r.updated_at <= $2
+		ORDER BY r.updated_at LIMIT $3`, entity.ReturnReceived, before, limit)
+}
+
+// MarkRestocked records the goods of return id as back in stock
+func (r *OrderRepository) MarkRestocked(ctx context.Context, id int64, now time.Time) error {
+
+	_, err := r.db.ExecContext(ctx, "UPDATE order_returns SET restocked_at = $1 WHERE id = $2 AND restocked_at IS NULL", now, id)
+	return err
+}
+
+func (r *OrderRepository) listReturns(ctx context.Context, query string, args ...any) ([]entity.Return, error) {
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.Return
+	for rows.Next() {
+		rt, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *rt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ptrs := make([]*entity.Return, len(list))
+	for i := range list {
+		ptrs[i] = &list[i]
+	}
+	return list, r.loadReturnLines(ctx, ptrs)
+}
+
+// loadReturnLines fills the lines of returns in one query
+func (r *OrderRepository) loadReturnLines(ctx context.Context, returns []*entity.Return) error {
+
+	if len(returns) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(returns))
+	byID := make(map[int64]*entity.Return, len(returns))
+	for i, rt := range returns {
+		ids[i] = rt.ID
+		byID[rt.ID] = rt
+	}
+
+	query, args, err := sqlx.In(`SELECT rl.return_id, rl.order_line_id, l.product_id, rl.quantity, rl.restock
+		FROM order_return_lines rl JOIN order_lines l ON l.id = rl.order_line_id
+		WHERE rl.return_id IN (?) ORDER BY rl.return_id, rl.order_line_id`, ids)
+	if err != nil {
+		return err
+	}
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var returnID int64
+		var l entity.ReturnLine
+		if err := rows.Scan(&returnID, &l.LineID, &l.ProductID, &l.Quantity, &l.Restock); err != nil {
+			return err
+		}
+		rt := byID[returnID]
+		rt.Lines = append(rt.Lines, l)
+	}
+	return rows.Err()
+}
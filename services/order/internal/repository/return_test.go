@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReturnRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestReturnRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReturnRepositoryTestSuite))
+}
+
+func (suite *ReturnRepositoryTestSuite) SetupTest() {
+	dbConn, err := migrateOrderDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *ReturnRepositoryTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+func (suite *ReturnRepositoryTestSuite) TestReturnLifecycle() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderDelivered)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	line := o.Lines[0].ID
+
+	rt, err := o.RequestReturn(entity.ReturnNoLongerNeeded, "too big", []entity.ReturnLine{{LineID: line, Quantity: 1}}, 7, now)
+	suite.Require().NoError(err)
+	suite.Nil(repo.CreateReturn(ctx, o, rt, producer.NewOrderReturn))
+	suite.NotZero(rt.ID)
+
+	got, err := repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(1, got.Lines[0].ReturningQuantity)
+
+	suite.Require().NoError(rt.Approve(9, "ok", now))
+	suite.Nil(repo.ReviewReturn(ctx, rt, entity.ReturnRequested, producer.NewOrderReturn))
+	// approved already
+	suite.Equal(entity.ErrReturnChanged, repo.ReviewReturn(ctx, rt, entity.ReturnRequested, producer.NewOrderReturn))
+
+	sc, err := o.ReceiveReturn(rt, map[int64]bool{line: true}, 9, "", now)
+	suite.Require().NoError(err)
+	suite.Nil(repo.ReceiveReturn(ctx, o, rt, sc, producer.NewOrderReturn))
+
+	stored, err := repo.GetReturn(ctx, rt.ID)
+	suite.Nil(err)
+	suite.Equal(entity.ReturnReceived, stored.Status)
+	suite.Equal([]entity.ReturnLine{{LineID: line, ProductID: 1, Quantity: 1, Restock: true}}, stored.Lines)
+	suite.Equal(usd(1100), stored.Refund.Amount)
+	suite.Nil(stored.RestockedAt)
+
+	got, err = repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(0, got.Lines[0].ReturningQuantity)
+	suite.Equal(1, got.Lines[0].ReturnedQuantity)
+	suite.Equal(usd(1100), got.RefundedTotal)
+
+	list, err := repo.ListUnrestocked(ctx, now.Add(time.Minute), 10)
+	suite.Nil(err)
+	suite.Len(list, 1)
+	suite.Nil(repo.MarkRestocked(ctx, rt.ID, now))
+	list, err = repo.ListUnrestocked(ctx, now.Add(time.Minute), 10)
+	suite.Nil(err)
+	suite.Empty(list)
+}
+
+func (suite *ReturnRepositoryTestSuite) TestRejectReturn() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	o := createOrder(&suite.Suite, repo, entity.OrderDelivered)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	rt, err := o.RequestReturn(entity.ReturnDamaged, "", []entity.ReturnLine{{LineID: o.Lines[1].ID, Quantity: 1}}, 7, now)
+	suite.Require().NoError(err)
+	suite.Nil(repo.CreateReturn(ctx, o, rt, producer.NewOrderReturn))
+
+	// the quantity is held until the return is rejected
+	_, err = o.RequestReturn(entity.ReturnDamaged, "", []entity.ReturnLine{{LineID: o.Lines[1].ID, Quantity: 1}}, 7, now)
+	suite.Equal(entity.ErrReturnQuantityTooHigh, err)
+
+	suite.Require().NoError(o.RejectReturn(rt, 9, "no photos", now))
+	suite.Nil(repo.ReviewReturn(ctx, rt, entity.ReturnRequested, producer.NewOrderReturn))
+
+	got, err := repo.GetByID(ctx, o.ID)
+	suite.Nil(err)
+	suite.Equal(0, got.Lines[1].ReturningQuantity)
+
+	list, err := repo.ListReturns(ctx, o.ID)
+	suite.Nil(err)
+	suite.Len(list, 1)
+	suite.Equal(entity.ReturnRejected, list[0].Status)
+	suite.Equal("no photos", list[0].ReviewNote)
+
+	missing, err := repo.GetReturn(ctx, rt.ID+1)
+	suite.Nil(err)
+	suite.Nil(missing)
+}
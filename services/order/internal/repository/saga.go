@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+)
+
+// sagaColumns lists the columns read by scanSaga, in order
+const sagaColumns = "order_id, step, payment_reference, failure_reason, deadline, locked_until, attempts, created_at, updated_at"
+
+func scanSaga(row scanner) (*entity.CheckoutSaga, error) {
+
+	var s entity.CheckoutSaga
+	err := row.Scan(&s.OrderID, &s.Step, &s.PaymentReference, &s.FailureReason, &s.Deadline, &s.LockedUntil, &s.Attempts, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func insertSaga(ctx context.Context, tx *sqlx.Tx, s *entity.CheckoutSaga) error {
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO checkout_sagas (order_id, step, deadline, locked_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		s.OrderID, s.Step, s.Deadline, s.LockedUntil, s.CreatedAt, s.UpdatedAt)
+	return err
+}
+
+// SaveSaga stores the progress of a saga, provided the caller still holds the lease it claimed: claiming
+// counts an attempt, so s.Attempts no longer matches once another instance took the saga over. It returns
+// entity.ErrSagaLeaseLost then.
+func (r *OrderRepository) SaveSaga(ctx context.Context, s *entity.CheckoutSaga) error {
+
+	res, err := r.db.ExecContext(ctx, `UPDATE checkout_sagas SET step = $1, payment_reference = $2, failure_reason = $3,
+		locked_until = $4, updated_at = $5 WHERE order_id = $6 AND attempts = $7`,
+		s.Step, s.PaymentReference, s.FailureReason, s.LockedUntil, s.UpdatedAt, s.OrderID, s.Attempts)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entity.ErrSagaLeaseLost
+	}
+	return nil
+}
+
+// GetSaga returns the checkout of an order, nil when there is none
+func (r *OrderRepository) GetSaga(ctx context.Context, orderID int64) (*entity.CheckoutSaga, error) {
+
+	s, err := scanSaga(r.db.QueryRowContext(ctx, "SELECT "+sagaColumns+" FROM checkout_sagas WHERE order_id = $1", orderID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+// ClaimStaleSagas leases to the caller, until now+lease, up to limit unfinished sagas whose lease ran out:
+// their instance crashed or gave up on a step it will retry
+func (r *OrderRepository) ClaimStaleSagas(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.CheckoutSaga, error) {
+
+	rows, err := r.db.QueryContext(ctx, `UPDATE checkout_sagas SET locked_until = $1, attempts = attempts + 1
+		WHERE order_id IN (
+			SELECT order_id FROM checkout_sagas
+			WHERE step NOT IN ('completed', 'compensated') AND locked_until <= $2
+			ORDER BY locked_until LIMIT $3
+			`+r.skipLocked()+`
+		)
+		RETURNING `+sagaColumns,
+		now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.CheckoutSaga
+	for rows.Next() {
+		s, err := scanSaga(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+	return list, rows.Err()
+}
+
+// ClaimSaga leases the saga of an order to the caller until now+lease, provided it is at step; it returns
+// nil when it is not
+func (r *OrderRepository) ClaimSaga(ctx context.Context, orderID int64, step entity.SagaStep, now time.Time, lease time.Duration) (*entity.CheckoutSaga, error) {
+
+	s, err := scanSaga(r.db.QueryRowContext(ctx, `UPDATE checkout_sagas SET locked_until = $1, attempts = attempts + 1
+		WHERE order_id = $2 AND step = $3 RETURNING `+sagaColumns,
+		now.Add(lease), orderID, step))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
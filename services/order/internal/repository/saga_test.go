@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+type SagaRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestSagaRepositorySuite(t *testing.T) {
+	suite.Run(t, new(SagaRepositoryTestSuite))
+}
+
+func (suite *SagaRepositoryTestSuite) SetupTest() {
+	dbConn, err := migrateOrderDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *SagaRepositoryTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+func (suite *SagaRepositoryTestSuite) insert(orderID int64, step entity.SagaStep, now time.Time) {
+
+	s := entity.NewCheckoutSaga(orderID, now, 15*time.Minute, time.Minute)
+	s.Step = step
+	tx := suite.DB.MustBegin()
+	suite.Require().NoError(insertSaga(context.Background(), tx, s))
+	suite.Require().NoError(tx.Commit())
+}
+
+func (suite *SagaRepositoryTestSuite) TestClaimStaleSagas() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	now := time.Now().UTC()
+	suite.insert(1, entity.SagaStarted, now)
+	suite.insert(2, entity.SagaCompleted, now)
+	suite.insert(3, entity.SagaCompensating, now.Add(time.Minute))
+
+	// all still leased to the instances that started them
+	list, err := repo.ClaimStaleSagas(ctx, now, time.Minute, 10)
+	suite.Nil(err)
+	suite.Empty(list)
+
+	// finished sagas are left alone
+	list, err = repo.ClaimStaleSagas(ctx, now.Add(time.Minute), time.Minute, 10)
+	suite.Nil(err)
+	suite.Len(list, 1)
+	suite.Equal(int64(1), list[0].OrderID)
+	suite.Equal(1, list[0].Attempts)
+
+	list, err = repo.ClaimStaleSagas(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	suite.Nil(err)
+	suite.Len(list, 2)
+}
+
+func (suite *SagaRepositoryTestSuite) TestSaveSagaAfterLosingTheLease() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	now := time.Now().UTC()
+	suite.insert(1, entity.SagaStarted, now)
+
+	stuck, err := repo.ClaimStaleSagas(ctx, now.Add(time.Minute), time.Minute, 10)
+	suite.Nil(err)
+	suite.Len(stuck, 1)
+
+	// the first instance stalled past its lease and another one took over
+	taken, err := repo.ClaimStaleSagas(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	suite.Nil(err)
+	suite.Len(taken, 1)
+
+	stuck[0].Step = entity.SagaCompensating
+	suite.Equal(entity.ErrSagaLeaseLost, repo.SaveSaga(ctx, &stuck[0]))
+
+	taken[0].Step = entity.SagaStockReserved
+	suite.Nil(repo.SaveSaga(ctx, &taken[0]))
+
+	s, err := repo.GetSaga(ctx, 1)
+	suite.Nil(err)
+	suite.Equal(entity.SagaStockReserved, s.Step)
+	suite.Equal(2, s.Attempts)
+}
+
+func (suite *SagaRepositoryTestSuite) TestClaimSaga() {
+
+	ctx := context.Background()
+	repo := NewOrderRepository(suite.DB)
+	now := time.Now().UTC()
+	suite.insert(1, entity.SagaPaymentPending, now)
+
+	s, err := repo.ClaimSaga(ctx, 1, entity.SagaStarted, now, time.Minute)
+	suite.Nil(err)
+	suite.Nil(s)
+
+	s, err = repo.ClaimSaga(ctx, 1, entity.SagaPaymentPending, now, time.Minute)
+	suite.Nil(err)
+	suite.Equal(1, s.Attempts)
+	suite.Nil(repo.SaveSaga(ctx, s))
+
+	missing, err := repo.GetSaga(ctx, 2)
+	suite.Nil(err)
+	suite.Nil(missing)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+)
+
+const refundRecoveryBatch = 50
+
+type CancelLineInput struct {
+	LineID   int64
+	Quantity int
+}
+
+type CancelOrderInput struct {
+	Reason entity.CancelReason
+	Note   string
+	// Lines are the quantities to cancel; all that is left of the order when empty
+	Lines []CancelLineInput
+	// CancelledBy is the user id of who cancels, Staff whether they do it as staff
+	CancelledBy int64
+	Staff       bool
+}
+
+// CancelOrder cancels an order, or some of its lines, and announces it. Once the order is paid, the stock
+// of the cancelled lines is released and what they cost is refunded; before, the checkout of the order
+// does it when it finds the order cancelled, right away when it waits for the payment.
+func (uc *OrderUseCase) CancelOrder(ctx context.Context, orderID int64, input CancelOrderInput) (*entity.Order, *entity.Cancellation, error) {
+
+	o, err := uc.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := make([]entity.CancelLine, len(input.Lines))
+	for i, l := range input.Lines {
+		lines[i] = entity.CancelLine{LineID: l.LineID, Quantity: l.Quantity}
+	}
+	c, sc, err := o.Cancel(input.Reason, input.Note, lines, input.CancelledBy, input.Staff, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []entity.OutboxMessage
+	if sc != nil {
+		m, err := producer.NewOrderStatusChanged(sc)
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, m)
+	}
+	if err := uc.repo.Cancel(ctx, o, c, sc, producer.NewOrderCancelled, events...); err != nil {
+		return nil, nil, err
+	}
+
+	if !c.DuringCheckout() {
+		uc.releaseStock(ctx, o, c)
+	} else if err := uc.resumeCheckout(ctx, o.ID); err != nil {
+		// a checkout waiting for the payment would otherwise hold the stock until its deadline
+		log.Printf("warning: checkout of order %d: %v", o.ID, err)
+	}
+	if c.Refund != nil {
+		// left to the recovery once its lease runs out
+		if err := uc.issueRefund(ctx, o, c.Refund); err != nil {
+			log.Printf("warning: refund %d of order %d: %v", c.Refund.ID, o.ID, err)
+		}
+	}
+
+	return o, c, nil
+}
+
+// releaseStock gives back the stock of the lines c cancelled. The cancellation stands either way, a failure
+// is only logged.
+func (uc *OrderUseCase) releaseStock(ctx context.Context, o *entity.Order, c *entity.Cancellation) {
+
+	var err error
+	if c.Full {
+		err = uc.products.ReleaseStock(ctx, stockReference(o.ID))
+	} else {
+		quantities := make(map[int64]int64, len(c.Lines))
+		for _, l := range c.Lines {
+			quantities[l.ProductID] += int64(l.Quantity)
+		}
+		err = uc.products.ReleaseStockItems(ctx, stockReference(o.ID), fmt.Sprintf("cancellation-%d", c.ID), quantities)
+	}
+	if err != nil {
+		log.Printf("warning: release stock of cancellation %d of order %d: %v", c.ID, o.ID, err)
+	}
+}
+
+// issueRefund pays f back through the payment of o and records it. The gateway refunds once per key,
+// so a refund issued but not recorded is not paid twice.
+func (uc *OrderUseCase) issueRefund(ctx context.Context, o *entity.Order, f *entity.Refund) error {
+
+	p, err := uc.getPayment(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := p.Refund(f.Amount, now); err != nil {
+		return err
+	}
+	if err := uc.gateway.Refund(ctx, p.Reference, f.Key(), f.Amount); err != nil {
+		return err
+	}
+	return uc.repo.RecordRefund(ctx, p, f, now)
+}
+
+// RetryRefunds issues the refunds whose instance crashed or failed to issue them
+func (uc *OrderUseCase) RetryRefunds(ctx context.Context, now time.Time) error {
+
+	refunds, err := uc.repo.ClaimDueRefunds(ctx, now, entity.RefundLease, refundRecoveryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range refunds {
+		f := &refunds[i]
+		o, err := uc.GetOrder(ctx, f.OrderID)
+		if err == nil {
+			err = uc.issueRefund(ctx, o, f)
+		}
+		if err != nil {
+			log.Printf("warning: refund %d of order %d: %v", f.ID, f.OrderID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	productclient "github.com/raulsilva-tech/e-commerce/services/product/pkg/client"
+)
+
+const (
+	// sagaLease bounds how long an instance may run a checkout before another one resumes it
+	sagaLease         = time.Minute
+	sagaRecoveryBatch = 50
+)
+
+// runCheckout carries the checkout of o forward from where saga stands: reserve the stock, authorize the
+// payment and confirm the order, saving each step. A payment waiting for the customer leaves the checkout
+// to the payment webhook, or to the recovery which checks on it. A step failing for good, a step failing while retry
+// is false, or the deadline passing turns the saga to compensation, which undoes the steps done.
+// It returns why the checkout failed, if it did.
+func (uc *OrderUseCase) runCheckout(ctx context.Context, o *entity.Order, saga *entity.CheckoutSaga, retry bool) error {
+
+	var failure error
+	for !saga.IsFinished() {
+		if saga.Expired(time.Now()) {
+			saga.Fail("checkout timed out")
+		}
+
+		var err error
+		switch saga.Step {
+		case entity.SagaStarted:
+			err = uc.reserveStock(ctx, o, saga)
+		case entity.SagaStockReserved:
+			err = uc.authorizePayment(ctx, o, saga)
+		case entity.SagaPaymentPending:
+			var waiting bool
+			if waiting, err = uc.paymentPending(ctx, o, saga); waiting {
+				return nil
+			}
+		case entity.SagaPaymentAuthorized:
+			err = uc.confirmOrder(ctx, o, saga)
+		case entity.SagaCompensating:
+			if err = uc.compensate(ctx, o, saga); err != nil {
+				// left for the recovery to finish once the lease ends
+				return errors.Join(failure, err)
+			}
+		}
+
+		if err != nil && !saga.IsCompensating() {
+			if failure == nil {
+				failure = err
+			}
+			if retry && !isFinal(err) {
+				return err
+			}
+			saga.Fail(err.Error())
+		}
+
+		// each step saved renews the lease, so only a stuck instance loses it
+		saga.UpdatedAt = time.Now()
+		saga.LockedUntil = saga.UpdatedAt.Add(sagaLease)
+		if saga.IsFinished() {
+			saga.LockedUntil = saga.UpdatedAt
+		}
+		if err := uc.repo.SaveSaga(ctx, saga); err != nil {
+			// entity.ErrSagaLeaseLost: the instance that took over carries on, this one stops here
+			return err
+		}
+	}
+
+	if saga.Step == entity.SagaCompensated && failure == nil {
+		// resumed compensation of a checkout that failed earlier
+		failure = errors.New(saga.FailureReason)
+	}
+	return failure
+}
+
+// isFinal tells whether retrying a step that failed with err is pointless
+func isFinal(err error) bool {
+	return errors.Is(err, entity.ErrInsufficientStock) ||
+		errors.Is(err, entity.ErrPaymentDeclined) ||
+		errors.Is(err, entity.ErrInvalidPaymentState) ||
+		errors.Is(err, entity.ErrInvalidTransition)
+}
+
+func (uc *OrderUseCase) reserveStock(ctx context.Context, o *entity.Order, saga *entity.CheckoutSaga) error {
+
+	// bundles hold their components
+	err := uc.products.ReserveStock(ctx, stockReference(o.ID), o.Quantities())
+	if errors.Is(err, productclient.ErrInsufficientStock) {
+		return entity.ErrInsufficientStock
+	}
+	if err != nil {
+		return err
+	}
+	saga.Step = entity.SagaStockReserved
+	return nil
+}
+
+func (uc *OrderUseCase) authorizePayment(ctx context.Context, o *entity.Order, saga *entity.CheckoutSaga) error {
+
+	// an order cancelled meanwhile cannot move on, which compensates the checkout
+	if o.Status != entity.OrderAwaitingPayment {
+		if _, err := uc.setStatus(ctx, o, entity.OrderAwaitingPayment, 0, ""); err != nil {
+			return err
+		}
+	}
+
+	p, err := uc.getPayment(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if p.Status == entity.PaymentPending {
+		if err := uc.sendPayment(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	switch p.Status {
+	case entity.PaymentRequiresAction:
+		saga.Step = entity.SagaPaymentPending
+	case entity.PaymentAuthorized:
+		saga.Step, saga.PaymentReference = entity.SagaPaymentAuthorized, p.Reference
+	case entity.PaymentFailed:
+		return fmt.Errorf("%w: %s", entity.ErrPaymentDeclined, p.FailureReason)
+	default:
+		return entity.ErrInvalidPaymentState
+	}
+	return nil
+}
+
+// paymentPending tells whether the payment of o still waits for the customer. Otherwise the checkout goes
+// back to authorizing the payment, which finds out how it ended.
+func (uc *OrderUseCase) paymentPending(ctx context.Context, o *entity.Order, saga *entity.CheckoutSaga) (bool, error) {
+
+	// an order cancelled meanwhile cannot move on, which compensates the checkout
+	if o.Status != entity.OrderAwaitingPayment {
+		return false, entity.ErrInvalidTransition
+	}
+	p, err := uc.getPayment(ctx, o.ID)
+	if err != nil {
+		return false, err
+	}
+	if p.Status == entity.PaymentRequiresAction {
+		return true, nil
+	}
+	saga.Step = entity.SagaStockReserved
+	return false, nil
+}
+
+// confirmOrder marks the order paid and takes the money authorized. A capture failing for good
+// compensates the checkout, which cancels the order.
+func (uc *OrderUseCase) confirmOrder(ctx context.Context, o *entity.Order, saga *entity.CheckoutSaga) error {
+
+	if o.Status != entity.OrderPaid {
+		if _, err := uc.setStatus(ctx, o, entity.OrderPaid, 0, "payment authorized"); err != nil {
+			return err
+		}
+	}
+
+	p, err := uc.getPayment(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if p.Status == entity.PaymentAuthorized {
+		if err := uc.gateway.Capture(ctx, p.Reference, p.Amount); err != nil {
+			return err
+		}
+		if err := p.Capture(time.Now()); err != nil {
+			return err
+		}
+		if err := uc.repo.UpdatePayment(ctx, p); err != nil {
+			return err
+		}
+	}
+	saga.Step = entity.SagaCompleted
+	return nil
+}
+
+// compensate gives the payment back, releases the stock and cancels the order. Each part is harmless to
+// repeat, so a compensation cut short is simply run again.
+func (uc *OrderUseCase) compensate(ctx context.Context, o *entity.Order, saga *entity.CheckoutSaga) error {
+
+	if err := uc.releasePayment(ctx, o.ID); err != nil {
+		return err
+	}
+	if err := uc.products.ReleaseStock(ctx, stockReference(o.ID)); err != nil {
+		return err
+	}
+	if o.Status.CanMoveTo(entity.OrderCancelled) {
+		if _, err := uc.setStatus(ctx, o, entity.OrderCancelled, 0, saga.FailureReason); err != nil {
+			return err
+		}
+	}
+	saga.Step = entity.SagaCompensated
+	return nil
+}
+
+// ResumeCheckouts carries on the checkouts whose instance crashed or left a step to retry
+func (uc *OrderUseCase) ResumeCheckouts(ctx context.Context, now time.Time) error {
+
+	sagas, err := uc.repo.ClaimStaleSagas(ctx, now, sagaLease, sagaRecoveryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range sagas {
+		o, err := uc.GetOrder(ctx, sagas[i].OrderID)
+		if err != nil {
+			log.Printf("warning: resume checkout of order %d: %v", sagas[i].OrderID, err)
+			continue
+		}
+		if err := uc.runCheckout(ctx, o, &sagas[i], true); err != nil {
+			log.Printf("warning: checkout of order %d: %v", o.ID, err)
+		}
+	}
+	return nil
+}
+
+// RunCheckoutRecovery resumes stale checkouts and retries due refunds, restocks and product updates every
+// interval until ctx is done. All live in the database, so work interrupted by a crash is resumed on the first run.
+func (uc *OrderUseCase) RunCheckoutRecovery(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := uc.ResumeCheckouts(ctx, time.Now()); err != nil {
+			log.Printf("warning: checkout recovery: %v", err)
+		}
+		if err := uc.RetryRefunds(ctx, time.Now()); err != nil {
+			log.Printf("warning: refund recovery: %v", err)
+		}
+		if err := uc.RetryRestocks(ctx, time.Now()); err != nil {
+			log.Printf("warning: restock recovery: %v", err)
+		}
+		if err := uc.RetryProductUpdates(ctx, time.Now()); err != nil {
+			log.Printf("warning: product update recovery: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,35 +2,186 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
 	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/payment"
 	"github.com/raulsilva-tech/e-commerce/services/order/internal/repository"
-	"github.com/segmentio/kafka-go"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	productclient "github.com/raulsilva-tech/e-commerce/services/product/pkg/client"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 )
 
 type OrderUseCase struct {
-	repo     *repository.OrderRepository
-	producer *kafka.Writer
+	repo            *repository.OrderRepository
+	producer        producer.MessageWriter
+	products        *productclient.Client
+	pricing         entity.PricingPolicy
+	gateway         payment.PaymentGateway
+	checkoutTimeout time.Duration
 }
 
-func NewOrderUseCase(r *repository.OrderRepository, p *kafka.Writer) *OrderUseCase {
-	return &OrderUseCase{repo: r, producer: p}
+func NewOrderUseCase(r *repository.OrderRepository, p producer.MessageWriter, products *productclient.Client, pricing entity.PricingPolicy,
+	gateway payment.PaymentGateway, checkoutTimeout time.Duration) *OrderUseCase {
+	return &OrderUseCase{repo: r, producer: p, products: products, pricing: pricing, gateway: gateway, checkoutTimeout: checkoutTimeout}
 }
 
-func (uc *OrderUseCase) CreateOrder(ctx context.Context, productID int64, quantity int, total float64) error {
-	order, err := entity.NewOrder(0, productID, quantity, total)
+type CreateOrderLineInput struct {
+	ProductID int64
+	Quantity  int
+}
+
+type CreateOrderInput struct {
+	// UserID is the customer placing the order
+	UserID int64
+	// Currency and Region pick the prices the product service quotes
+	Currency string
+	Region   string
+	Lines    []CreateOrderLineInput
+	// PaymentMethod is the token of the card to charge, issued by the payment gateway
+	PaymentMethod string
+}
+
+// CreateOrder prices the lines at what the product service charges now; what the client thinks
+// things cost plays no part. Once the order is saved it is returned even when its checkout fails, along
+// with why.
+func (uc *OrderUseCase) CreateOrder(ctx context.Context, input CreateOrderInput) (*entity.Order, error) {
+
+	currency := strings.ToUpper(input.Currency)
+	if _, err := money.New(0, currency); err != nil {
+		return nil, err
+	}
+
+	lines, err := uc.priceLines(ctx, input.Lines, &pb.PriceContext{Currency: currency, Region: input.Region})
+	if err != nil {
+		return nil, err
+	}
+	// postgres keeps microseconds, the order returned matches the one read back later
+	order, err := entity.NewOrder(input.UserID, currency, lines, uc.pricing, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := entity.NewPaymentIntent(0, uc.gateway.Name(), input.PaymentMethod, order.Total, order.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	saga := entity.NewCheckoutSaga(0, order.CreatedAt, uc.checkoutTimeout, sagaLease)
+	if err := uc.repo.Create(ctx, order, saga, p, producer.NewOrderCreated); err != nil {
+		return nil, err
+	}
+
+	// a failed checkout cancels the order, which was announced already; one waiting for the customer
+	// to complete the payment returns the order awaiting payment, as does one the recovery took over
+	if err := uc.runCheckout(ctx, order, saga, false); err != nil && !errors.Is(err, entity.ErrSagaLeaseLost) {
+		return order, err
+	}
+
+	return order, nil
+}
+
+// priceLines snapshots the current price of each product for pc. Products that do not exist, are not
+// on sale to customers or have no price in pc's currency are reported missing by the product service.
+func (uc *OrderUseCase) priceLines(ctx context.Context, input []CreateOrderLineInput, pc *pb.PriceContext) ([]entity.OrderLine, error) {
+
+	ids := make([]int64, len(input))
+	for i, l := range input {
+		ids[i] = l.ProductID
+	}
+	found, missing, err := uc.products.BatchGetProducts(ctx, ids, pc)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, entity.ErrProductNotFound
+	}
+	byID := make(map[string]*pb.GetProductResponse, len(found))
+	for _, p := range found {
+		byID[p.Id] = p
+	}
+
+	lines := make([]entity.OrderLine, len(input))
+	for i, l := range input {
+		p := byID[strconv.FormatInt(l.ProductID, 10)]
+		if p == nil || p.Price == nil {
+			return nil, entity.ErrProductNotFound
+		}
+		price, err := fromPBMoney(p.Price)
+		if err != nil {
+			return nil, err
+		}
+		var regular *money.Money
+		if p.CompareAtPrice != nil {
+			m, err := fromPBMoney(p.CompareAtPrice)
+			if err != nil {
+				return nil, err
+			}
+			regular = &m
+		}
+
+		if lines[i], err = entity.NewOrderLine(l.ProductID, p.Sku, l.Quantity, price, regular); err != nil {
+			return nil, err
+		}
 	}
+	return lines, nil
+}
+
+func fromPBMoney(m *pb.Money) (money.Money, error) {
+	return money.FromUnitsNanos(m.CurrencyCode, m.Units, m.Nanos)
+}
+
+// stockReference names the stock an order holds in the product service
+func stockReference(orderID int64) string {
+	return fmt.Sprintf("order-%d", orderID)
+}
 
-	if err := uc.repo.Create(order); err != nil {
-		return err
+const (
+	defaultOrderLimit = 20
+	maxOrderLimit     = 100
+)
+
+// GetOrder returns entity.ErrOrderNotFound when there is no order id
+func (uc *OrderUseCase) GetOrder(ctx context.Context, id int64) (*entity.Order, error) {
+
+	o, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		return nil, entity.ErrOrderNotFound
 	}
+	return o, nil
+}
 
-	if err := producer.PublishOrderCreated(ctx, uc.producer, order.ID); err != nil {
-		return err
+// ListOrders returns a page of the orders matching q, newest first, and where the next page starts
+// when there is one
+func (uc *OrderUseCase) ListOrders(ctx context.Context, q entity.OrderQuery) (list []entity.Order, next *entity.OrderCursor, err error) {
+
+	if err := q.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultOrderLimit
 	}
+	q.Limit = min(q.Limit, maxOrderLimit)
+	limit := q.Limit
 
-	return nil
+	// one extra row tells whether there is a next page
+	q.Limit++
+	list, err = uc.repo.List(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(list) > limit {
+		list = list[:limit]
+		c := entity.CursorOf(&list[limit-1])
+		next = &c
+	}
+	return list, next, nil
 }
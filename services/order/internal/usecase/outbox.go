@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	outboxBatchSize = 100
+	// outboxLease bounds how long a relay may take to publish a batch before another relay claims it again
+	outboxLease = 30 * time.Second
+)
+
+// RelayOutbox publishes the events due at now to Kafka, marking them sent. An event that fails is tried
+// again later with backoff; one whose relay crashed midway is claimed again once its lease ends,
+// so every event is published at least once.
+func (uc *OrderUseCase) RelayOutbox(ctx context.Context, now time.Time) (relayed int, err error) {
+
+	batch, err := uc.repo.ClaimOutbox(ctx, now, outboxLease, outboxBatchSize)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	msgs := make([]kafka.Message, len(batch))
+	for i, m := range batch {
+		msgs[i] = producer.ToKafka(m)
+	}
+	werr := uc.producer.WriteMessages(ctx, msgs...)
+
+	// a batch fails as a whole or message by message
+	var perMessage kafka.WriteErrors
+	errors.As(werr, &perMessage)
+
+	sent := make([]int64, 0, len(batch))
+	for i, m := range batch {
+		failure := werr
+		if len(perMessage) == len(batch) {
+			failure = perMessage[i]
+		}
+		if failure == nil {
+			sent = append(sent, m.ID)
+			continue
+		}
+		if err := uc.repo.MarkOutboxFailed(ctx, m.ID, now.Add(entity.RetryDelay(m.Attempts)), failure.Error()); err != nil {
+			log.Printf("warning: reschedule outbox message %d: %v", m.ID, err)
+		}
+	}
+	if len(sent) > 0 {
+		// not marking them only leads to a redelivery once the lease ends
+		if err := uc.repo.MarkOutboxSent(ctx, sent, time.Now()); err != nil {
+			return 0, err
+		}
+	}
+	if len(sent) < len(batch) {
+		log.Printf("warning: outbox relay: %d of %d events not published: %v", len(batch)-len(sent), len(batch), werr)
+	}
+
+	return len(sent), nil
+}
+
+// RunOutboxRelay relays the outbox every interval until ctx is done, right away again while there is
+// a backlog. The outbox lives in the database, so events left by a crash are relayed on the first run.
+func (uc *OrderUseCase) RunOutboxRelay(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := uc.RelayOutbox(ctx, time.Now())
+		if err != nil {
+			log.Printf("warning: outbox relay: %v", err)
+		}
+		if n == outboxBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/repository"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeWriter records the messages written and fails with err
+type fakeWriter struct {
+	written []kafka.Message
+	err     error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+
+	w.written = append(w.written, msgs...)
+	return w.err
+}
+
+func migrateOutboxDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE order_outbox (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    value BLOB NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at DATETIME
+);`)
+
+	return db, err
+}
+
+type OutboxRelayTestSuite struct {
+	DB     *sqlx.DB
+	writer *fakeWriter
+	uc     *OrderUseCase
+	suite.Suite
+}
+
+func TestOutboxRelaySuite(t *testing.T) {
+	suite.Run(t, new(OutboxRelayTestSuite))
+}
+
+func (suite *OutboxRelayTestSuite) SetupTest() {
+
+	dbConn, err := migrateOutboxDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+	suite.writer = &fakeWriter{}
+	suite.uc = NewOrderUseCase(repository.NewOrderRepository(dbConn), suite.writer, nil, entity.PricingPolicy{}, nil, 0)
+}
+
+func (suite *OutboxRelayTestSuite) TearDownTest() {
+	suite.DB.Close()
+}
+
+func (suite *OutboxRelayTestSuite) insert(keys ...string) {
+
+	for _, k := range keys {
+		suite.DB.MustExec("INSERT INTO order_outbox (key, event_type, value) VALUES ($1, $2, $3)", k, "order_created", []byte("{}"))
+	}
+}
+
+// unsent returns the last error of the messages not sent yet, by id
+func (suite *OutboxRelayTestSuite) unsent() map[int64]string {
+
+	rows, err := suite.DB.Query("SELECT id, last_error FROM order_outbox WHERE sent_at IS NULL")
+	suite.Require().NoError(err)
+	defer rows.Close()
+
+	res := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var lastError string
+		suite.Require().NoError(rows.Scan(&id, &lastError))
+		res[id] = lastError
+	}
+	return res
+}
+
+func (suite *OutboxRelayTestSuite) TestRelayOneMessagePerKey() {
+
+	ctx := context.Background()
+	suite.insert("1", "2", "1")
+	now := time.Now().UTC()
+
+	n, err := suite.uc.RelayOutbox(ctx, now)
+	suite.Nil(err)
+	suite.Equal(2, n)
+	suite.Len(suite.writer.written, 2)
+	suite.Equal("1", string(suite.writer.written[0].Key))
+	suite.Equal("2", string(suite.writer.written[1].Key))
+
+	// the second message of order 1 goes once the first was sent
+	n, err = suite.uc.RelayOutbox(ctx, now)
+	suite.Nil(err)
+	suite.Equal(1, n)
+	suite.Equal("1", string(suite.writer.written[2].Key))
+	suite.Empty(suite.unsent())
+}
+
+func (suite *OutboxRelayTestSuite) TestRelayReschedulesFailedMessages() {
+
+	ctx := context.Background()
+	suite.insert("1", "2")
+	now := time.Now().UTC()
+
+	suite.writer.err = kafka.WriteErrors{nil, errors.New("leader not available")}
+	n, err := suite.uc.RelayOutbox(ctx, now)
+	suite.Nil(err)
+	suite.Equal(1, n)
+	suite.Equal(map[int64]string{2: "leader not available"}, suite.unsent())
+
+	// retried after the first backoff, well before the lease would have run out
+	suite.writer.err = nil
+	n, err = suite.uc.RelayOutbox(ctx, now.Add(entity.RetryDelay(1)))
+	suite.Nil(err)
+	suite.Equal(1, n)
+	suite.Equal("2", string(suite.writer.written[2].Key))
+	suite.Empty(suite.unsent())
+}
+
+func (suite *OutboxRelayTestSuite) TestRelayFailingAsAWhole() {
+
+	ctx := context.Background()
+	suite.insert("1", "2")
+	now := time.Now().UTC()
+
+	suite.writer.err = errors.New("connection refused")
+	n, err := suite.uc.RelayOutbox(ctx, now)
+	suite.Nil(err)
+	suite.Equal(0, n)
+	suite.Equal(map[int64]string{1: "connection refused", 2: "connection refused"}, suite.unsent())
+}
+
+func (suite *OutboxRelayTestSuite) TestRelayClaimsAgainAfterACrash() {
+
+	ctx := context.Background()
+	suite.insert("1")
+	now := time.Now().UTC()
+
+	// a relay claimed the message and died before publishing it
+	repo := repository.NewOrderRepository(suite.DB)
+	claimed, err := repo.ClaimOutbox(ctx, now, outboxLease, outboxBatchSize)
+	suite.Require().NoError(err)
+	suite.Require().Len(claimed, 1)
+
+	n, err := suite.uc.RelayOutbox(ctx, now)
+	suite.Nil(err)
+	suite.Equal(0, n)
+
+	n, err = suite.uc.RelayOutbox(ctx, now.Add(outboxLease))
+	suite.Nil(err)
+	suite.Equal(1, n)
+	suite.Empty(suite.unsent())
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/payment"
+)
+
+// GetPayment returns the payment of an order, entity.ErrPaymentNotFound when there is none
+func (uc *OrderUseCase) GetPayment(ctx context.Context, orderID int64) (*entity.PaymentIntent, error) {
+	return uc.getPayment(ctx, orderID)
+}
+
+func (uc *OrderUseCase) getPayment(ctx context.Context, orderID int64) (*entity.PaymentIntent, error) {
+
+	p, err := uc.repo.GetPayment(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrPaymentNotFound
+	}
+	return p, nil
+}
+
+// sendPayment asks the gateway to authorize p and records its answer. A payment the gateway declines
+// is recorded failed, not returned as an error.
+func (uc *OrderUseCase) sendPayment(ctx context.Context, p *entity.PaymentIntent) error {
+
+	a, err := uc.gateway.Authorize(ctx, payment.AuthorizeRequest{Key: p.Key(), OrderID: p.OrderID, Amount: p.Amount, PaymentMethod: p.PaymentMethod})
+	now := time.Now()
+	switch {
+	case errors.Is(err, entity.ErrPaymentDeclined):
+		err = p.Fail(err.Error(), now)
+	case err != nil:
+		return err
+	case a.RequiresAction:
+		err = p.RequireAction(a.Reference, a.ActionURL, now)
+	default:
+		err = p.Authorize(a.Reference, now)
+	}
+	if err != nil {
+		return err
+	}
+	return uc.repo.UpdatePayment(ctx, p)
+}
+
+// releasePayment gives back what the payment of an order holds: it voids an authorization and refunds
+// what was captured
+func (uc *OrderUseCase) releasePayment(ctx context.Context, orderID int64) error {
+
+	p, err := uc.repo.GetPayment(ctx, orderID)
+	if err != nil || p == nil {
+		return err
+	}
+
+	now := time.Now()
+	switch p.Status {
+	case entity.PaymentAuthorized, entity.PaymentRequiresAction:
+		if err := uc.gateway.Void(ctx, p.Reference); err != nil {
+			return err
+		}
+		err = p.Void(now)
+	case entity.PaymentCaptured:
+		amount := p.Refundable()
+		if err := uc.gateway.Refund(ctx, p.Reference, p.Key()+"-release", amount); err != nil {
+			return err
+		}
+		err = p.Refund(amount, now)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return uc.repo.UpdatePayment(ctx, p)
+}
+
+// HandlePaymentEvent applies a webhook of the gateway about a payment waiting for the customer, then
+// carries the checkout of its order on. A redelivered event finds the payment moved on and changes nothing.
+func (uc *OrderUseCase) HandlePaymentEvent(ctx context.Context, e payment.Event) error {
+
+	p, err := uc.repo.GetPaymentByReference(ctx, uc.gateway.Name(), e.Reference)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return entity.ErrPaymentNotFound
+	}
+	if p.Status != entity.PaymentRequiresAction {
+		return nil
+	}
+
+	now := time.Now()
+	switch e.Type {
+	case payment.EventAuthorizationSucceeded:
+		err = p.Authorize(p.Reference, now)
+	case payment.EventAuthorizationFailed:
+		err = p.Fail(e.Reason, now)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.UpdatePayment(ctx, p); err != nil {
+		return err
+	}
+
+	// the recovery takes over should this fail
+	if err := uc.resumeCheckout(ctx, p.OrderID); err != nil {
+		log.Printf("warning: checkout of order %d: %v", p.OrderID, err)
+	}
+	return nil
+}
+
+// resumeCheckout carries on the checkout of an order whose payment waited for the customer. When the
+// checkout gave up meanwhile, what the payment holds goes back.
+func (uc *OrderUseCase) resumeCheckout(ctx context.Context, orderID int64) error {
+
+	saga, err := uc.repo.ClaimSaga(ctx, orderID, entity.SagaPaymentPending, time.Now(), sagaLease)
+	if err != nil {
+		return err
+	}
+	if saga == nil {
+		s, err := uc.repo.GetSaga(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if s != nil && s.IsCompensating() {
+			return uc.releasePayment(ctx, orderID)
+		}
+		return nil
+	}
+
+	o, err := uc.GetOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return uc.runCheckout(ctx, o, saga, true)
+}
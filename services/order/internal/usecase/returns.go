@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+)
+
+const (
+	restockRecoveryBatch = 50
+	// restockRecoveryDelay leaves the instance that received a return time to restock it itself
+	restockRecoveryDelay = time.Minute
+)
+
+type ReturnLineInput struct {
+	LineID   int64
+	Quantity int
+}
+
+type RequestReturnInput struct {
+	Reason entity.ReturnReason
+	Note   string
+	Lines  []ReturnLineInput
+	// RequestedBy is the user id of the customer
+	RequestedBy int64
+}
+
+// RequestReturn asks to send back lines of a delivered order and announces it
+func (uc *OrderUseCase) RequestReturn(ctx context.Context, orderID int64, input RequestReturnInput) (*entity.Return, error) {
+
+	o, err := uc.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]entity.ReturnLine, len(input.Lines))
+	for i, l := range input.Lines {
+		lines[i] = entity.ReturnLine{LineID: l.LineID, Quantity: l.Quantity}
+	}
+	r, err := o.RequestReturn(input.Reason, input.Note, lines, input.RequestedBy, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.CreateReturn(ctx, o, r, producer.NewOrderReturn); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetReturn returns a return of an order, entity.ErrReturnNotFound when the order has no such return
+func (uc *OrderUseCase) GetReturn(ctx context.Context, orderID, returnID int64) (*entity.Return, error) {
+
+	r, err := uc.repo.GetReturn(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.OrderID != orderID {
+		return nil, entity.ErrReturnNotFound
+	}
+	return r, nil
+}
+
+func (uc *OrderUseCase) ListReturns(ctx context.Context, orderID int64) ([]entity.Return, error) {
+	return uc.repo.ListReturns(ctx, orderID)
+}
+
+// ApproveReturn lets the customer send the goods of a return back
+func (uc *OrderUseCase) ApproveReturn(ctx context.Context, orderID, returnID, by int64, note string) (*entity.Return, error) {
+
+	r, err := uc.GetReturn(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	from := r.Status
+	if err := r.Approve(by, note, time.Now().UTC().Truncate(time.Microsecond)); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.ReviewReturn(ctx, r, from, producer.NewOrderReturn); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RejectReturn turns a return down, before or after approving it
+func (uc *OrderUseCase) RejectReturn(ctx context.Context, orderID, returnID, by int64, note string) (*entity.Return, error) {
+
+	o, r, err := uc.getOrderReturn(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	from := r.Status
+	if err := o.RejectReturn(r, by, note, time.Now().UTC().Truncate(time.Microsecond)); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.ReviewReturn(ctx, r, from, producer.NewOrderReturn); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type ReceiveReturnInput struct {
+	// Restock lists the lines whose goods can be sold again
+	Restock    map[int64]bool
+	ReceivedBy int64
+	Note       string
+}
+
+// ReceiveReturn records the goods of an approved return as back, then puts the ones to restock back in
+// stock and refunds the customer through the payment of the order. Both are retried by the recovery
+// when they fail, so the receipt stands either way.
+func (uc *OrderUseCase) ReceiveReturn(ctx context.Context, orderID, returnID int64, input ReceiveReturnInput) (*entity.Order, *entity.Return, error) {
+
+	o, r, err := uc.getOrderReturn(ctx, orderID, returnID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sc, err := o.ReceiveReturn(r, input.Restock, input.ReceivedBy, input.Note, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []entity.OutboxMessage
+	if sc != nil {
+		m, err := producer.NewOrderStatusChanged(sc)
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, m)
+	}
+	if err := uc.repo.ReceiveReturn(ctx, o, r, sc, producer.NewOrderReturn, events...); err != nil {
+		return nil, nil, err
+	}
+
+	if r.RestockedAt == nil {
+		if err := uc.restock(ctx, r); err != nil {
+			log.Printf("warning: restock return %d of order %d: %v", r.ID, o.ID, err)
+		}
+	}
+	if r.Refund != nil {
+		// left to the recovery once its lease runs out
+		if err := uc.issueRefund(ctx, o, r.Refund); err != nil {
+			log.Printf("warning: refund %d of order %d: %v", r.Refund.ID, o.ID, err)
+		}
+	}
+
+	return o, r, nil
+}
+
+// restock puts the goods of a received return back on hand. The shipment took them out of stock; the
+// restock is named after the return, which makes retrying it harmless.
+func (uc *OrderUseCase) restock(ctx context.Context, r *entity.Return) error {
+
+	if err := uc.products.RestockItems(ctx, fmt.Sprintf("return-%d", r.ID), r.Restocks()); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := uc.repo.MarkRestocked(ctx, r.ID, now); err != nil {
+		return err
+	}
+	r.RestockedAt = &now
+	return nil
+}
+
+// RetryRestocks restocks the returns received a while ago whose instance crashed or failed to restock them
+func (uc *OrderUseCase) RetryRestocks(ctx context.Context, now time.Time) error {
+
+	returns, err := uc.repo.ListUnrestocked(ctx, now.Add(-restockRecoveryDelay), restockRecoveryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range returns {
+		r := &returns[i]
+		if err := uc.restock(ctx, r); err != nil {
+			log.Printf("warning: restock return %d of order %d: %v", r.ID, r.OrderID, err)
+		}
+	}
+	return nil
+}
+
+func (uc *OrderUseCase) getOrderReturn(ctx context.Context, orderID, returnID int64) (*entity.Order, *entity.Return, error) {
+
+	r, err := uc.GetReturn(ctx, orderID, returnID)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := uc.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return o, r, nil
+}
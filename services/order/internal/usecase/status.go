@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/order/internal/kafka"
+)
+
+const productUpdateRecoveryBatch = 50
+
+type TransitionInput struct {
+	Status entity.OrderStatus
+	// ChangedBy is the user id of who moves the order, zero for the order service itself
+	ChangedBy int64
+	Reason    string
+}
+
+// TransitionOrder moves an order to another status when its current status allows it, records the change
+// in the order history and announces it. By hand an order only moves along its fulfilment, from paid to
+// delivered; cancelling and refunding go through their own flows, which give back the stock and the money,
+// while the payment statuses are the checkout's alone.
+func (uc *OrderUseCase) TransitionOrder(ctx context.Context, orderID int64, input TransitionInput) (*entity.Order, error) {
+
+	switch input.Status {
+	case entity.OrderCancelled:
+		o, _, err := uc.CancelOrder(ctx, orderID, CancelOrderInput{
+			Reason:      entity.CancelOther,
+			Note:        input.Reason,
+			CancelledBy: input.ChangedBy,
+			Staff:       true,
+		})
+		return o, err
+	case entity.OrderRefunded:
+		return uc.RefundOrder(ctx, orderID, input.ChangedBy, input.Reason)
+	case entity.OrderFulfilling, entity.OrderShipped, entity.OrderDelivered:
+	case entity.OrderPending, entity.OrderAwaitingPayment, entity.OrderPaid:
+		return nil, entity.ErrManualTransition
+	default:
+		return nil, entity.ErrInvalidOrderStatus
+	}
+
+	o, err := uc.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.setStatus(ctx, o, input.Status, input.ChangedBy, input.Reason); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// RefundOrder gives the customer back all that was not refunded yet of an order, paid or delivered, and
+// moves it to refunded. The stock still reserved for a paid order is released; delivered goods only come
+// back through a return.
+func (uc *OrderUseCase) RefundOrder(ctx context.Context, orderID, refundedBy int64, reason string) (*entity.Order, error) {
+
+	o, err := uc.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *o
+	f, c, err := next.RefundAll(refundedBy, reason, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+	m, err := producer.NewOrderStatusChanged(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.RefundOrder(ctx, &next, c, f, m); err != nil {
+		return nil, err
+	}
+	*o = next
+
+	if c.From == entity.OrderPaid {
+		if err := uc.products.ReleaseStock(ctx, stockReference(o.ID)); err != nil {
+			log.Printf("warning: release stock of order %d: %v", o.ID, err)
+		}
+	}
+	if f != nil {
+		// left to the recovery once its lease runs out
+		if err := uc.issueRefund(ctx, o, f); err != nil {
+			log.Printf("warning: refund %d of order %d: %v", f.ID, o.ID, err)
+		}
+	}
+	return o, nil
+}
+
+// setStatus moves o to status to and saves the change with the event announcing it; o is left as it was
+// when saving fails. The update the product service needs, e.g. on shipping, is saved with it and sent
+// right after; when that fails the order keeps its status and RetryProductUpdates sends it later.
+func (uc *OrderUseCase) setStatus(ctx context.Context, o *entity.Order, to entity.OrderStatus, changedBy int64, reason string) (*entity.StatusChange, error) {
+
+	next := *o
+	c, err := next.Transition(to, changedBy, reason, time.Now().UTC().Truncate(time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+	m, err := producer.NewOrderStatusChanged(c)
+	if err != nil {
+		return nil, err
+	}
+	u := entity.ProductUpdateFor(c, entity.ProductUpdateLease)
+	if err := uc.repo.SetStatus(ctx, c, u, m); err != nil {
+		return nil, err
+	}
+	*o = next
+
+	if u != nil {
+		if err := uc.updateProducts(ctx, o, u); err != nil {
+			log.Printf("warning: %s of order %d: %v", u.Kind, o.ID, err)
+		}
+	}
+	return c, nil
+}
+
+func (uc *OrderUseCase) ListStatusHistory(ctx context.Context, orderID int64) ([]entity.StatusChange, error) {
+
+	if _, err := uc.GetOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListStatusHistory(ctx, orderID)
+}
+
+// updateProducts tells the product service what u is about and records it as done. Both calls are
+// harmless to repeat: the stock is committed once per order and a purchase recorded once per product.
+func (uc *OrderUseCase) updateProducts(ctx context.Context, o *entity.Order, u *entity.ProductUpdate) error {
+
+	var err error
+	switch u.Kind {
+	case entity.UpdateCommitStock:
+		err = uc.products.CommitStock(ctx, stockReference(o.ID))
+	case entity.UpdateRecordPurchase:
+		err = uc.recordPurchase(ctx, o)
+	}
+	if err != nil {
+		return err
+	}
+	return uc.repo.MarkProductUpdateDone(ctx, u.ID, time.Now().UTC())
+}
+
+// recordPurchase tells the product service the customer received the products of o, which lets them
+// review them
+func (uc *OrderUseCase) recordPurchase(ctx context.Context, o *entity.Order) error {
+
+	var productIDs []int64
+	for _, l := range o.Lines {
+		if l.Remaining() > 0 {
+			productIDs = append(productIDs, l.ProductID)
+		}
+	}
+	return uc.products.RecordPurchase(ctx, o.UserID, o.ID, productIDs)
+}
+
+// RetryProductUpdates sends the product updates whose instance crashed or failed to send them
+func (uc *OrderUseCase) RetryProductUpdates(ctx context.Context, now time.Time) error {
+
+	updates, err := uc.repo.ClaimDueProductUpdates(ctx, now, entity.ProductUpdateLease, productUpdateRecoveryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range updates {
+		u := &updates[i]
+		o, err := uc.GetOrder(ctx, u.OrderID)
+		if err == nil {
+			err = uc.updateProducts(ctx, o, u)
+		}
+		if err != nil {
+			log.Printf("warning: %s of order %d: %v", u.Kind, u.OrderID, err)
+		}
+	}
+	return nil
+}
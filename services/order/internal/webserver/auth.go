@@ -0,0 +1,50 @@
+package webserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/auth"
+)
+
+type principalKey struct{}
+
+// Principal is the caller identified by the access token issued by the auth service
+type Principal = auth.Principal
+
+// PrincipalFromContext returns the caller set by jwtMiddleware, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// ---- JWT Middleware
+func (s *Server) jwtMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, msg := s.authenticate(r)
+		if msg != "" {
+			http.Error(w, msg, http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalKey{}, p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate reads the bearer token, returning why it was rejected
+func (s *Server) authenticate(r *http.Request) (Principal, string) {
+	return auth.ParseBearer(s.cfg.JWTSecret, r.Header.Get("Authorization"))
+}
+
+// requireStaff must run after jwtMiddleware
+func (s *Server) requireStaff(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok || !p.IsStaff() {
+			http.Error(w, "staff only", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
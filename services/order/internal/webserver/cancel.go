@@ -0,0 +1,110 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+type CancelLineDTO struct {
+	LineID    int64 `json:"line_id"`
+	ProductID int64 `json:"product_id,omitempty"`
+	Quantity  int   `json:"quantity"`
+}
+
+// CancelOrderDTO cancels the lines listed, or all that is left of the order when there are none
+type CancelOrderDTO struct {
+	// Reason is one of customer_request, out_of_stock, payment_issue, fraud_suspected, duplicate_order, other
+	Reason string          `json:"reason"`
+	Note   string          `json:"note"`
+	Lines  []CancelLineDTO `json:"lines"`
+}
+
+type CancellationDTO struct {
+	ID          int64           `json:"id"`
+	Reason      string          `json:"reason"`
+	Note        string          `json:"note,omitempty"`
+	Full        bool            `json:"full"`
+	Lines       []CancelLineDTO `json:"lines"`
+	Refund      *money.Money    `json:"refund,omitempty"`
+	CancelledBy int64           `json:"cancelled_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type CancelOrderResultDTO struct {
+	Order        OrderDTO        `json:"order"`
+	Cancellation CancellationDTO `json:"cancellation"`
+}
+
+// CancelOrder cancels an order, or some of its lines, for its customer until it is paid and for staff
+// until it ships
+func (s *Server) CancelOrder(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return
+	}
+
+	var dto CancelOrderDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	order, err := s.orderUseCase.GetOrder(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	p, _ := PrincipalFromContext(r.Context())
+	if order.UserID != p.UserID && !p.IsStaff() {
+		writeUseCaseError(w, entity.ErrOrderNotFound)
+		return
+	}
+
+	input := usecase.CancelOrderInput{
+		Reason:      entity.CancelReason(dto.Reason),
+		Note:        dto.Note,
+		Lines:       make([]usecase.CancelLineInput, len(dto.Lines)),
+		CancelledBy: p.UserID,
+		Staff:       p.IsStaff(),
+	}
+	for i, l := range dto.Lines {
+		input.Lines[i] = usecase.CancelLineInput{LineID: l.LineID, Quantity: l.Quantity}
+	}
+
+	order, c, err := s.orderUseCase.CancelOrder(r.Context(), id, input)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := CancelOrderResultDTO{
+		Order: toOrderDTO(order),
+		Cancellation: CancellationDTO{
+			ID:          c.ID,
+			Reason:      string(c.Reason),
+			Note:        c.Note,
+			Full:        c.Full,
+			Lines:       make([]CancelLineDTO, len(c.Lines)),
+			CancelledBy: c.CancelledBy,
+			CreatedAt:   c.CreatedAt,
+		},
+	}
+	for i, l := range c.Lines {
+		res.Cancellation.Lines[i] = CancelLineDTO{LineID: l.LineID, ProductID: l.ProductID, Quantity: l.Quantity}
+	}
+	if c.Refund != nil {
+		res.Cancellation.Refund = &c.Refund.Amount
+	}
+	writeJSON(w, http.StatusOK, res)
+}
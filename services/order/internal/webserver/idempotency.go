@@ -0,0 +1,270 @@
+package webserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	maxIdempotencyKey = 255
+	// idempotencyTTL is how long a response is replayed for retries of its request
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLockTTL bounds how long a request holds its key, should its instance die midway
+	idempotencyLockTTL = time.Minute
+	maxIdempotentBody  = 1 << 20
+)
+
+// idempotentRecord is what Redis holds for a key: the request in flight, then its response
+type idempotentRecord struct {
+	RequestHash string `json:"request_hash"`
+	// Token tells apart the claims of a request and of a retry that reclaimed its key once the lock ran out
+	Token       string `json:"token,omitempty"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotent lets clients retry a request safely by sending the same Idempotency-Key: the first request runs,
+// retries get its response replayed, server errors included once the handler saved something. A key reused with another request is rejected with 422, and a retry
+// arriving while the first request still runs with 409. Keys are per user; must run after jwtMiddleware.
+// Requests without the header run as usual, as do all requests while Redis is down.
+func (s *Server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			http.Error(w, "idempotency key too long", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+		r.Body.Close()
+		if err != nil || len(body) > maxIdempotentBody {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		p, _ := PrincipalFromContext(r.Context())
+		redisKey := fmt.Sprintf("idempotency:%d:%s %s:%s", p.UserID, r.Method, r.URL.Path, key)
+		hash := requestHash(r, body)
+
+		claim, _ := json.Marshal(idempotentRecord{RequestHash: hash, Token: rand.Text()})
+		claimed, err := s.idempotency.setNX(r.Context(), redisKey, claim, idempotencyLockTTL)
+		if err != nil {
+			log.Printf("warning: idempotency claim: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !claimed {
+			replay(r.Context(), w, s.idempotency, redisKey, hash)
+			return
+		}
+
+		// the outcome is stored even when the client went away meanwhile, its retry gets it
+		ctx := context.WithoutCancel(r.Context())
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		keep := new(bool)
+		stop := s.holdIdempotencyKey(ctx, redisKey, claim)
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), keepKey{}, keep)))
+		stop()
+
+		// a failure before anything was saved may not happen again, the client can retry with the same key
+		if rec.status >= http.StatusInternalServerError && !*keep {
+			if _, err := s.idempotency.compareAndSet(ctx, redisKey, claim, nil, 0); err != nil {
+				log.Printf("warning: idempotency release: %v", err)
+			}
+			return
+		}
+
+		done, _ := json.Marshal(idempotentRecord{
+			RequestHash: hash,
+			Done:        true,
+			Status:      rec.status,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		})
+		// a key whose lock ran out may be held by a retry by now, which stores its own outcome
+		stored, err := s.idempotency.compareAndSet(ctx, redisKey, claim, done, idempotencyTTL)
+		if err != nil {
+			log.Printf("warning: idempotency store: %v", err)
+		} else if !stored {
+			log.Printf("warning: idempotency store: lock on %s ran out before the response", redisKey)
+		}
+	})
+}
+
+type keepKey struct{}
+
+// keepIdempotencyKey tells idempotent the request saved something before failing, e.g. an order whose
+// checkout failed: its outcome is stored like any other, so a retry does not do it again
+func keepIdempotencyKey(ctx context.Context) {
+
+	if keep, ok := ctx.Value(keepKey{}).(*bool); ok {
+		*keep = true
+	}
+}
+
+// holdIdempotencyKey refreshes the lock on key until the returned func is called, so a request running
+// longer than idempotencyLockTTL keeps its key
+func (s *Server) holdIdempotencyKey(ctx context.Context, key string, claim []byte) func() {
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(idempotencyLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				held, err := s.idempotency.compareAndSet(ctx, key, claim, claim, idempotencyLockTTL)
+				if err != nil {
+					log.Printf("warning: idempotency refresh: %v", err)
+				} else if !held {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// replay answers a request whose key was claimed before, from the record held for it
+func replay(ctx context.Context, w http.ResponseWriter, store idempotencyStore, key, hash string) {
+
+	raw, err := store.get(ctx, key)
+	if err == nil && raw == nil {
+		// the first request failed or its lock ran out between the claim and now
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "request with this idempotency key in progress, retry", http.StatusConflict)
+		return
+	}
+	var stored idempotentRecord
+	if err == nil {
+		err = json.Unmarshal(raw, &stored)
+	}
+	if err != nil {
+		log.Printf("error: idempotency lookup: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case stored.RequestHash != hash:
+		http.Error(w, "idempotency key reused with a different request", http.StatusUnprocessableEntity)
+	case !stored.Done:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "request with this idempotency key in progress, retry", http.StatusConflict)
+	default:
+		if stored.ContentType != "" {
+			w.Header().Set("Content-Type", stored.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(stored.Status)
+		w.Write(stored.Body)
+	}
+}
+
+// requestHash identifies a request by its method, path and body
+func requestHash(r *http.Request, body []byte) string {
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// idempotencyStore holds the records of the idempotency keys
+type idempotencyStore interface {
+	// setNX stores value under key unless the key is set, telling whether it did
+	setNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// get returns nil for a key that is not set
+	get(ctx context.Context, key string) ([]byte, error)
+	// compareAndSet replaces the value of key with value, or deletes key when value is nil, provided key
+	// still holds old; it tells whether it did
+	compareAndSet(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
+}
+
+type redisIdempotencyStore struct {
+	rdb *redis.Client
+}
+
+// compareAndSetScript is KEYS[1] old new ttl_ms, with an empty new for a delete
+var compareAndSetScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] == '' then
+	redis.call('DEL', KEYS[1])
+else
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+end
+return 1
+`)
+
+func (s redisIdempotencyStore) setNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (s redisIdempotencyStore) get(ctx context.Context, key string) ([]byte, error) {
+
+	raw, err := s.rdb.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return raw, err
+}
+
+func (s redisIdempotencyStore) compareAndSet(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+
+	n, err := compareAndSetScript.Run(ctx, s.rdb, []string{key}, old, value, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
@@ -0,0 +1,192 @@
+package webserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyStore keeps the records in a map, ignoring their ttl
+type memoryIdempotencyStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{m: map[string][]byte{}}
+}
+
+func (s *memoryIdempotencyStore) setNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.m[key]; ok {
+		return false, nil
+	}
+	s.m[key] = value
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) get(ctx context.Context, key string) ([]byte, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+
+func (s *memoryIdempotencyStore) compareAndSet(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !bytes.Equal(s.m[key], old) {
+		return false, nil
+	}
+	if value == nil {
+		delete(s.m, key)
+	} else {
+		s.m[key] = value
+	}
+	return true, nil
+}
+
+const testIdempotencyKey = "idempotency:7:POST /orders/:k1"
+
+func postWithKey(h http.Handler, body string) *httptest.ResponseRecorder {
+
+	r := httptest.NewRequest(http.MethodPost, "/orders/", strings.NewReader(body))
+	r.Header.Set(idempotencyHeader, "k1")
+	r = r.WithContext(context.WithValue(r.Context(), principalKey{}, Principal{UserID: 7}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotent(t *testing.T) {
+
+	type call struct {
+		body     string
+		status   int
+		replayed bool
+	}
+	tests := []struct {
+		name  string
+		store idempotencyStore
+		// inFlight claims the key for a request with this body before the calls
+		inFlight string
+		// handlerStatus is what the wrapped handler answers, keep whether it saved something first
+		handlerStatus int
+		keep          bool
+		calls         []call
+		wantRuns      int
+	}{
+		{
+			name:          "replays the stored response",
+			store:         newMemoryIdempotencyStore(),
+			handlerStatus: http.StatusCreated,
+			calls:         []call{{`{"a":1}`, http.StatusCreated, false}, {`{"a":1}`, http.StatusCreated, true}},
+			wantRuns:      1,
+		},
+		{
+			name:          "rejects the key reused with another body",
+			store:         newMemoryIdempotencyStore(),
+			handlerStatus: http.StatusCreated,
+			calls:         []call{{`{"a":1}`, http.StatusCreated, false}, {`{"a":2}`, http.StatusUnprocessableEntity, false}},
+			wantRuns:      1,
+		},
+		{
+			name:          "answers 409 while the first request runs",
+			store:         newMemoryIdempotencyStore(),
+			inFlight:      `{"a":1}`,
+			handlerStatus: http.StatusCreated,
+			calls:         []call{{`{"a":1}`, http.StatusConflict, false}},
+			wantRuns:      0,
+		},
+		{
+			name:          "releases the key after a 5xx",
+			store:         newMemoryIdempotencyStore(),
+			handlerStatus: http.StatusInternalServerError,
+			calls:         []call{{`{"a":1}`, http.StatusInternalServerError, false}, {`{"a":1}`, http.StatusInternalServerError, false}},
+			wantRuns:      2,
+		},
+		{
+			name:          "stores a 5xx once something was saved",
+			store:         newMemoryIdempotencyStore(),
+			handlerStatus: http.StatusInternalServerError,
+			keep:          true,
+			calls:         []call{{`{"a":1}`, http.StatusInternalServerError, false}, {`{"a":1}`, http.StatusInternalServerError, true}},
+			wantRuns:      1,
+		},
+		{
+			name: "runs every request while redis is down",
+			// a closed port, every call fails fast
+			store:         redisIdempotencyStore{rdb: redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})},
+			handlerStatus: http.StatusCreated,
+			calls:         []call{{`{"a":1}`, http.StatusCreated, false}, {`{"a":1}`, http.StatusCreated, false}},
+			wantRuns:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if tt.inFlight != "" {
+				r := httptest.NewRequest(http.MethodPost, "/orders/", nil)
+				claim, _ := json.Marshal(idempotentRecord{RequestHash: requestHash(r, []byte(tt.inFlight)), Token: "other"})
+				tt.store.setNX(context.Background(), testIdempotencyKey, claim, idempotencyLockTTL)
+			}
+
+			runs := 0
+			s := &Server{idempotency: tt.store}
+			h := s.idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				runs++
+				if tt.keep {
+					keepIdempotencyKey(r.Context())
+				}
+				writeJSON(w, tt.handlerStatus, map[string]int{"run": runs})
+			}))
+
+			var first string
+			for i, c := range tt.calls {
+				w := postWithKey(h, c.body)
+				assert.Equal(t, c.status, w.Code, "call %d", i)
+				assert.Equal(t, c.replayed, w.Header().Get("Idempotent-Replayed") == "true", "call %d", i)
+				if i == 0 {
+					first = w.Body.String()
+				} else if c.replayed {
+					assert.Equal(t, first, w.Body.String())
+				}
+			}
+			assert.Equal(t, tt.wantRuns, runs)
+		})
+	}
+}
+
+func TestIdempotentKeepsTheRecordOfAnotherClaim(t *testing.T) {
+
+	store := newMemoryIdempotencyStore()
+	taken := []byte(`{"request_hash":"x","token":"retry"}`)
+
+	s := &Server{idempotency: store}
+	h := s.idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the lock ran out and a retry claimed the key meanwhile
+		store.mu.Lock()
+		store.m[testIdempotencyKey] = taken
+		store.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := postWithKey(h, `{"a":1}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	held, _ := store.get(context.Background(), testIdempotencyKey)
+	assert.Equal(t, taken, held)
+}
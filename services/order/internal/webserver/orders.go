@@ -0,0 +1,250 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+type OrderLineDTO struct {
+	ID                int64       `json:"id,omitempty"`
+	ProductID         int64       `json:"product_id"`
+	SKU               string      `json:"sku,omitempty"`
+	Quantity          int         `json:"quantity"`
+	CancelledQuantity int         `json:"cancelled_quantity"`
+	ReturningQuantity int         `json:"returning_quantity"`
+	ReturnedQuantity  int         `json:"returned_quantity"`
+	UnitPrice         money.Money `json:"unit_price"`
+	Discount          money.Money `json:"discount"`
+	LineTotal         money.Money `json:"line_total"`
+}
+
+type OrderDTO struct {
+	ID            int64          `json:"id,omitempty"`
+	UserID        int64          `json:"user_id"`
+	Status        string         `json:"status"`
+	Currency      string         `json:"currency"`
+	Subtotal      money.Money    `json:"subtotal"`
+	DiscountTotal money.Money    `json:"discount_total"`
+	TaxTotal      money.Money    `json:"tax_total"`
+	ShippingTotal money.Money    `json:"shipping_total"`
+	Total         money.Money    `json:"total"`
+	RefundedTotal money.Money    `json:"refunded_total"`
+	Lines         []OrderLineDTO `json:"lines"`
+	Payment       *PaymentDTO    `json:"payment,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+func toOrderDTO(o *entity.Order) OrderDTO {
+
+	dto := OrderDTO{
+		ID:            o.ID,
+		UserID:        o.UserID,
+		Status:        string(o.Status),
+		Currency:      o.Currency,
+		Subtotal:      o.Subtotal,
+		DiscountTotal: o.DiscountTotal,
+		TaxTotal:      o.TaxTotal,
+		ShippingTotal: o.ShippingTotal,
+		Total:         o.Total,
+		RefundedTotal: o.RefundedTotal,
+		Lines:         make([]OrderLineDTO, len(o.Lines)),
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+	}
+	for i, l := range o.Lines {
+		dto.Lines[i] = OrderLineDTO{
+			ID:                l.ID,
+			ProductID:         l.ProductID,
+			SKU:               l.SKU,
+			Quantity:          l.Quantity,
+			CancelledQuantity: l.CancelledQuantity,
+			ReturningQuantity: l.ReturningQuantity,
+			ReturnedQuantity:  l.ReturnedQuantity,
+			UnitPrice:         l.UnitPrice,
+			Discount:          l.Discount,
+			LineTotal:         l.LineTotal,
+		}
+	}
+	return dto
+}
+
+type CreateOrderLineDTO struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+// CreateOrderDTO carries no prices, the order service looks them up
+type CreateOrderDTO struct {
+	// Currency defaults to USD
+	Currency string               `json:"currency"`
+	Region   string               `json:"region"`
+	Lines    []CreateOrderLineDTO `json:"lines"`
+	// PaymentMethod is the card token issued by the payment gateway
+	PaymentMethod string `json:"payment_method"`
+}
+
+// CreateOrder places an order for the caller of the token
+func (s *Server) CreateOrder(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	var dto CreateOrderDTO
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := PrincipalFromContext(r.Context())
+	if dto.Currency == "" {
+		dto.Currency = "USD"
+	}
+	input := usecase.CreateOrderInput{
+		UserID:        p.UserID,
+		Currency:      dto.Currency,
+		Region:        dto.Region,
+		Lines:         make([]usecase.CreateOrderLineInput, len(dto.Lines)),
+		PaymentMethod: dto.PaymentMethod,
+	}
+	for i, l := range dto.Lines {
+		input.Lines[i] = usecase.CreateOrderLineInput{ProductID: l.ProductID, Quantity: l.Quantity}
+	}
+
+	order, err := s.orderUseCase.CreateOrder(r.Context(), input)
+	if err != nil {
+		if order != nil {
+			// the order exists, a retry must not place another one
+			keepIdempotencyKey(r.Context())
+		}
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.withPayment(r, toOrderDTO(order)))
+}
+
+// GetOrder shows an order to the customer who placed it and to staff
+func (s *Server) GetOrder(w http.ResponseWriter, r *http.Request) {
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return
+	}
+
+	order, err := s.orderUseCase.GetOrder(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	// someone else's order is not found rather than forbidden, ids are not secret but orders are
+	if p, _ := PrincipalFromContext(r.Context()); order.UserID != p.UserID && !p.IsStaff() {
+		writeUseCaseError(w, entity.ErrOrderNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.withPayment(r, toOrderDTO(order)))
+}
+
+type OrderListDTO struct {
+	Orders        []OrderDTO `json:"orders"`
+	NextPageToken string     `json:"next_page_token,omitempty"`
+}
+
+// ListMyOrders lists the caller's orders, newest first. It takes ?status=, ?from= and ?to= (RFC 3339),
+// ?limit= and ?page_token= from a previous response.
+func (s *Server) ListMyOrders(w http.ResponseWriter, r *http.Request) {
+
+	q, ok := orderQuery(w, r)
+	if !ok {
+		return
+	}
+	p, _ := PrincipalFromContext(r.Context())
+	q.UserID = p.UserID
+
+	s.listOrders(w, r, q)
+}
+
+// SearchOrders lists the orders of every customer for staff. On top of the filters of ListMyOrders
+// it takes ?user_id= and ?product_id=.
+func (s *Server) SearchOrders(w http.ResponseWriter, r *http.Request) {
+
+	q, ok := orderQuery(w, r)
+	if !ok {
+		return
+	}
+	for name, dest := range map[string]*int64{"user_id": &q.UserID, "product_id": &q.ProductID} {
+		if v := r.URL.Query().Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+			*dest = n
+		}
+	}
+
+	s.listOrders(w, r, q)
+}
+
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request, q entity.OrderQuery) {
+
+	list, next, err := s.orderUseCase.ListOrders(r.Context(), q)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := OrderListDTO{Orders: make([]OrderDTO, len(list))}
+	for i := range list {
+		res.Orders[i] = toOrderDTO(&list[i])
+	}
+	if next != nil {
+		res.NextPageToken = next.String()
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// orderQuery reads the filters shared by the order listings, answering 400 when one is malformed
+func orderQuery(w http.ResponseWriter, r *http.Request) (entity.OrderQuery, bool) {
+
+	v := r.URL.Query()
+	q := entity.OrderQuery{Status: entity.OrderStatus(v.Get("status"))}
+
+	if raw := v.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return q, false
+		}
+		q.Limit = n
+	}
+	for name, dest := range map[string]**time.Time{"from": &q.From, "to": &q.To} {
+		if raw := v.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http.Error(w, "invalid "+name+", want RFC 3339", http.StatusBadRequest)
+				return q, false
+			}
+			*dest = &t
+		}
+	}
+	if raw := v.Get("page_token"); raw != "" {
+		c, err := entity.ParseCursor(raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return q, false
+		}
+		q.After = c
+	}
+	return q, true
+}
@@ -0,0 +1,88 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/payment"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// maxWebhookBody bounds what a webhook may send, events are small
+const maxWebhookBody = 64 << 10
+
+// PaymentDTO shows the payment of an order. NextActionURL is where the customer completes it when it
+// requires action, e.g. 3-D Secure.
+type PaymentDTO struct {
+	Status         string      `json:"status"`
+	Gateway        string      `json:"gateway"`
+	Amount         money.Money `json:"amount"`
+	CapturedAmount money.Money `json:"captured_amount"`
+	RefundedAmount money.Money `json:"refunded_amount"`
+	NextActionURL  string      `json:"next_action_url,omitempty"`
+	FailureReason  string      `json:"failure_reason,omitempty"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func toPaymentDTO(p *entity.PaymentIntent) *PaymentDTO {
+
+	return &PaymentDTO{
+		Status:         string(p.Status),
+		Gateway:        p.Gateway,
+		Amount:         p.Amount,
+		CapturedAmount: p.CapturedAmount,
+		RefundedAmount: p.RefundedAmount,
+		NextActionURL:  p.NextActionURL,
+		FailureReason:  p.FailureReason,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+// withPayment adds the payment of the order to dto. Orders placed before payments were recorded
+// have none; a failure to read it leaves the order shown without it.
+func (s *Server) withPayment(r *http.Request, dto OrderDTO) OrderDTO {
+
+	p, err := s.orderUseCase.GetPayment(r.Context(), dto.ID)
+	switch {
+	case errors.Is(err, entity.ErrPaymentNotFound):
+	case err != nil:
+		log.Printf("warning: payment of order %d: %v", dto.ID, err)
+	default:
+		dto.Payment = toPaymentDTO(p)
+	}
+	return dto
+}
+
+// PaymentWebhook receives the events of the payment gateway. It answers 401 unless the body is signed
+// with the webhook secret, and 204 once the event is applied, redeliveries included.
+func (s *Server) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := payment.VerifySignature([]byte(s.cfg.PaymentWebhookSecret), r.Header.Get(payment.SignatureHeader), body, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var e payment.Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, "invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.orderUseCase.HandlePaymentEvent(r.Context(), e); err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,252 @@
+package webserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+type ReturnLineDTO struct {
+	LineID    int64 `json:"line_id"`
+	ProductID int64 `json:"product_id,omitempty"`
+	Quantity  int   `json:"quantity"`
+	Restock   bool  `json:"restock"`
+}
+
+// RequestReturnDTO lists the lines of a delivered order to send back
+type RequestReturnDTO struct {
+	// Reason is one of damaged, defective, wrong_item, not_as_described, no_longer_needed, other
+	Reason string          `json:"reason"`
+	Note   string          `json:"note"`
+	Lines  []ReturnLineDTO `json:"lines"`
+}
+
+type ReviewReturnDTO struct {
+	Note string `json:"note"`
+}
+
+// ReceiveReturnDTO records the goods of a return as back; the lines of RestockLineIDs can be sold again
+type ReceiveReturnDTO struct {
+	Note           string  `json:"note"`
+	RestockLineIDs []int64 `json:"restock_line_ids"`
+}
+
+type ReturnDTO struct {
+	ID          int64           `json:"id"`
+	OrderID     int64           `json:"order_id"`
+	Status      string          `json:"status"`
+	Reason      string          `json:"reason"`
+	Note        string          `json:"note,omitempty"`
+	Lines       []ReturnLineDTO `json:"lines"`
+	RequestedBy int64           `json:"requested_by"`
+	ReviewedBy  int64           `json:"reviewed_by,omitempty"`
+	ReviewNote  string          `json:"review_note,omitempty"`
+	Refund      *money.Money    `json:"refund,omitempty"`
+	RestockedAt *time.Time      `json:"restocked_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func toReturnDTO(rt *entity.Return) ReturnDTO {
+
+	dto := ReturnDTO{
+		ID:          rt.ID,
+		OrderID:     rt.OrderID,
+		Status:      string(rt.Status),
+		Reason:      string(rt.Reason),
+		Note:        rt.Note,
+		Lines:       make([]ReturnLineDTO, len(rt.Lines)),
+		RequestedBy: rt.RequestedBy,
+		ReviewedBy:  rt.ReviewedBy,
+		ReviewNote:  rt.ReviewNote,
+		RestockedAt: rt.RestockedAt,
+		CreatedAt:   rt.CreatedAt,
+		UpdatedAt:   rt.UpdatedAt,
+	}
+	for i, l := range rt.Lines {
+		dto.Lines[i] = ReturnLineDTO{LineID: l.LineID, ProductID: l.ProductID, Quantity: l.Quantity, Restock: l.Restock}
+	}
+	if rt.Refund != nil {
+		dto.Refund = &rt.Refund.Amount
+	}
+	return dto
+}
+
+type ReceiveReturnResultDTO struct {
+	Order  OrderDTO  `json:"order"`
+	Return ReturnDTO `json:"return"`
+}
+
+// RequestReturn asks to send back lines of a delivered order, for its customer and for staff on their behalf
+func (s *Server) RequestReturn(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	order, ok := s.visibleOrder(w, r)
+	if !ok {
+		return
+	}
+
+	var dto RequestReturnDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := PrincipalFromContext(r.Context())
+	input := usecase.RequestReturnInput{
+		Reason:      entity.ReturnReason(dto.Reason),
+		Note:        dto.Note,
+		Lines:       make([]usecase.ReturnLineInput, len(dto.Lines)),
+		RequestedBy: p.UserID,
+	}
+	for i, l := range dto.Lines {
+		input.Lines[i] = usecase.ReturnLineInput{LineID: l.LineID, Quantity: l.Quantity}
+	}
+
+	rt, err := s.orderUseCase.RequestReturn(r.Context(), order.ID, input)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toReturnDTO(rt))
+}
+
+// ListReturns shows the returns of an order, oldest first, to its customer and to staff
+func (s *Server) ListReturns(w http.ResponseWriter, r *http.Request) {
+
+	order, ok := s.visibleOrder(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := s.orderUseCase.ListReturns(r.Context(), order.ID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := make([]ReturnDTO, len(list))
+	for i := range list {
+		res[i] = toReturnDTO(&list[i])
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ApproveReturn lets the customer send the goods of a requested return back
+func (s *Server) ApproveReturn(w http.ResponseWriter, r *http.Request) {
+	s.reviewReturn(w, r, s.orderUseCase.ApproveReturn)
+}
+
+// RejectReturn turns a return down, before the goods are received
+func (s *Server) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	s.reviewReturn(w, r, s.orderUseCase.RejectReturn)
+}
+
+func (s *Server) reviewReturn(w http.ResponseWriter, r *http.Request,
+	review func(ctx context.Context, orderID, returnID, by int64, note string) (*entity.Return, error)) {
+
+	defer r.Body.Close()
+
+	orderID, returnID, ok := returnIDs(w, r)
+	if !ok {
+		return
+	}
+	var dto ReviewReturnDTO
+	if err := decodeOptional(r.Body, &dto); err != nil {
+		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := PrincipalFromContext(r.Context())
+	rt, err := review(r.Context(), orderID, returnID, p.UserID, dto.Note)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toReturnDTO(rt))
+}
+
+// ReceiveReturn records the goods of an approved return as back, restocks the lines listed and refunds
+// the customer
+func (s *Server) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	orderID, returnID, ok := returnIDs(w, r)
+	if !ok {
+		return
+	}
+	var dto ReceiveReturnDTO
+	if err := decodeOptional(r.Body, &dto); err != nil {
+		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := PrincipalFromContext(r.Context())
+	input := usecase.ReceiveReturnInput{Restock: make(map[int64]bool, len(dto.RestockLineIDs)), ReceivedBy: p.UserID, Note: dto.Note}
+	for _, id := range dto.RestockLineIDs {
+		input.Restock[id] = true
+	}
+
+	order, rt, err := s.orderUseCase.ReceiveReturn(r.Context(), orderID, returnID, input)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ReceiveReturnResultDTO{Order: toOrderDTO(order), Return: toReturnDTO(rt)})
+}
+
+// visibleOrder loads the order of the path for its customer and for staff, answering the error otherwise
+func (s *Server) visibleOrder(w http.ResponseWriter, r *http.Request) (*entity.Order, bool) {
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return nil, false
+	}
+
+	order, err := s.orderUseCase.GetOrder(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return nil, false
+	}
+	// someone else's order is not found rather than forbidden
+	if p, _ := PrincipalFromContext(r.Context()); order.UserID != p.UserID && !p.IsStaff() {
+		writeUseCaseError(w, entity.ErrOrderNotFound)
+		return nil, false
+	}
+	return order, true
+}
+
+func returnIDs(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+
+	orderID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	returnID, err := strconv.ParseInt(mux.Vars(r)["returnID"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid return id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return orderID, returnID, true
+}
+
+// decodeOptional decodes a JSON body into v, leaving v as is when the body is empty
+func decodeOptional(body io.Reader, v any) error {
+
+	if err := json.NewDecoder(body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
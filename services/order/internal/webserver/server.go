@@ -3,6 +3,7 @@ package webserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -10,13 +11,15 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
 	"github.com/raulsilva-tech/e-commerce/services/order/config"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
 	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 )
 
 type Server struct {
 	cfg          config.Config
 	orderUseCase usecase.OrderUseCase
-	rdb          *redis.Client
+	idempotency  idempotencyStore
 }
 
 func NewServer(cfg config.Config, uc usecase.OrderUseCase) (http.Handler, error) {
@@ -31,16 +34,32 @@ func NewServer(cfg config.Config, uc usecase.OrderUseCase) (http.Handler, error)
 	s := &Server{
 		cfg:          cfg,
 		orderUseCase: uc,
-		rdb:          rdb,
+		idempotency:  redisIdempotencyStore{rdb: rdb},
 	}
 	r := mux.NewRouter()
 	r.HandleFunc("/health", s.healthHandler).Methods("GET")
 
-	r.HandleFunc("/orders/", s.CreateOrder).Methods("POST")
-	// r.HandleFunc("/orders/:id", s.GetOrderById).Methods("GET")
-	// r.HandleFunc("/orders/:limit", s.GetOrders).Methods("GET")
-	// r.HandleFunc("/orders/:id", s.UpdateOrder).Methods("PUT")
-	// r.HandleFunc("/orders/:id", s.DeleteOrder).Methods("DELETE")
+	// signed in customers
+	r.Handle("/orders/", s.jwtMiddleware(s.idempotent(http.HandlerFunc(s.CreateOrder)))).Methods("POST")
+	r.Handle("/orders", s.jwtMiddleware(http.HandlerFunc(s.ListMyOrders))).Methods("GET")
+	r.Handle("/orders/{id:[0-9]+}", s.jwtMiddleware(http.HandlerFunc(s.GetOrder))).Methods("GET")
+	r.Handle("/orders/{id:[0-9]+}/history", s.jwtMiddleware(http.HandlerFunc(s.ListStatusHistory))).Methods("GET")
+	r.Handle("/orders/{id:[0-9]+}/cancel", s.jwtMiddleware(http.HandlerFunc(s.CancelOrder))).Methods("POST")
+	r.Handle("/orders/{id:[0-9]+}/returns", s.jwtMiddleware(http.HandlerFunc(s.RequestReturn))).Methods("POST")
+	r.Handle("/orders/{id:[0-9]+}/returns", s.jwtMiddleware(http.HandlerFunc(s.ListReturns))).Methods("GET")
+
+	// called by the payment gateway, which signs its requests instead of holding a token
+	r.HandleFunc("/payments/webhook", s.PaymentWebhook).Methods("POST")
+
+	// admin, staff tokens only
+	admin := func(h http.HandlerFunc) http.Handler {
+		return s.jwtMiddleware(s.requireStaff(h))
+	}
+	r.Handle("/orders/search", admin(s.SearchOrders)).Methods("GET")
+	r.Handle("/orders/{id:[0-9]+}/transitions", admin(s.TransitionOrder)).Methods("POST")
+	r.Handle("/orders/{id:[0-9]+}/returns/{returnID:[0-9]+}/approve", admin(s.ApproveReturn)).Methods("POST")
+	r.Handle("/orders/{id:[0-9]+}/returns/{returnID:[0-9]+}/reject", admin(s.RejectReturn)).Methods("POST")
+	r.Handle("/orders/{id:[0-9]+}/returns/{returnID:[0-9]+}/receive", admin(s.ReceiveReturn)).Methods("POST")
 
 	fs := http.FileServer(http.Dir("./docs"))
 	r.PathPrefix("/swagger/").Handler(http.StripPrefix("/swagger/", fs))
@@ -48,32 +67,61 @@ func NewServer(cfg config.Config, uc usecase.OrderUseCase) (http.Handler, error)
 	return r, nil
 }
 
-type OrderDTO struct {
-	ID        int64   `json:"id,omitempty"`
-	ProductID int64   `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Total     float64 `json:"total"`
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
 }
 
-func (s *Server) CreateOrder(w http.ResponseWriter, r *http.Request) {
-
-	defer r.Body.Close()
-
-	var order OrderDTO
-
-	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
-		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
-		return
+// writeUseCaseError maps domain errors to HTTP statuses, hiding unexpected errors behind a 500
+func writeUseCaseError(w http.ResponseWriter, err error) {
+
+	switch {
+	case errors.Is(err, entity.ErrOrderNotFound),
+		errors.Is(err, entity.ErrPaymentNotFound),
+		errors.Is(err, entity.ErrReturnNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entity.ErrInsufficientStock),
+		errors.Is(err, entity.ErrInvalidTransition),
+		errors.Is(err, entity.ErrManualTransition),
+		errors.Is(err, entity.ErrOrderStatusChanged),
+		errors.Is(err, entity.ErrCancelNotAllowed),
+		errors.Is(err, entity.ErrPartialCancelNotAllowed),
+		errors.Is(err, entity.ErrCancelQuantityTooHigh),
+		errors.Is(err, entity.ErrPaymentChanged),
+		errors.Is(err, entity.ErrInvalidPaymentState),
+		errors.Is(err, entity.ErrReturnNotAllowed),
+		errors.Is(err, entity.ErrReturnQuantityTooHigh),
+		errors.Is(err, entity.ErrInvalidReturnTransition),
+		errors.Is(err, entity.ErrReturnChanged):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, entity.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, entity.ErrPaymentDeclined):
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+	case errors.Is(err, entity.ErrProductIdIsRequired),
+		errors.Is(err, entity.ErrQuantityIsRequired),
+		errors.Is(err, entity.ErrNegativeTotal),
+		errors.Is(err, entity.ErrUserIdIsRequired),
+		errors.Is(err, entity.ErrOrderNeedsLines),
+		errors.Is(err, entity.ErrDuplicateLine),
+		errors.Is(err, entity.ErrMixedCurrencies),
+		errors.Is(err, entity.ErrInvalidOrderStatus),
+		errors.Is(err, entity.ErrInvalidPageToken),
+		errors.Is(err, entity.ErrReasonTooLong),
+		errors.Is(err, entity.ErrInvalidCancelReason),
+		errors.Is(err, entity.ErrOrderLineNotFound),
+		errors.Is(err, entity.ErrDuplicateCancelLine),
+		errors.Is(err, entity.ErrPaymentMethodRequired),
+		errors.Is(err, entity.ErrInvalidReturnReason),
+		errors.Is(err, entity.ErrReturnNeedsLines),
+		errors.Is(err, entity.ErrDuplicateReturnLine),
+		errors.Is(err, money.ErrInvalidCurrency):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("error: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
-
-	err := s.orderUseCase.CreateOrder(r.Context(), order.ProductID, order.Quantity, order.Total)
-	if err != nil {
-		http.Error(w, "error: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	json.NewEncoder(w).Encode(map[string]string{"message": "success"})
-	w.WriteHeader(http.StatusCreated)
 }
 
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
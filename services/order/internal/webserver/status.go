@@ -0,0 +1,88 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/order/internal/usecase"
+)
+
+type TransitionDTO struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type StatusChangeDTO struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedBy int64     `json:"changed_by"`
+	Reason    string    `json:"reason,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// TransitionOrder moves an order along its lifecycle, e.g. from paid to fulfilling, for staff
+func (s *Server) TransitionOrder(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return
+	}
+
+	var dto TransitionDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid input data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := PrincipalFromContext(r.Context())
+	order, err := s.orderUseCase.TransitionOrder(r.Context(), id, usecase.TransitionInput{
+		Status:    entity.OrderStatus(dto.Status),
+		ChangedBy: p.UserID,
+		Reason:    dto.Reason,
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toOrderDTO(order))
+}
+
+// ListStatusHistory shows the statuses an order went through, oldest first, to its customer and to staff
+func (s *Server) ListStatusHistory(w http.ResponseWriter, r *http.Request) {
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid order id", http.StatusBadRequest)
+		return
+	}
+
+	order, err := s.orderUseCase.GetOrder(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	if p, _ := PrincipalFromContext(r.Context()); order.UserID != p.UserID && !p.IsStaff() {
+		writeUseCaseError(w, entity.ErrOrderNotFound)
+		return
+	}
+
+	list, err := s.orderUseCase.ListStatusHistory(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := make([]StatusChangeDTO, len(list))
+	for i, c := range list {
+		res[i] = StatusChangeDTO{From: string(c.From), To: string(c.To), ChangedBy: c.ChangedBy, Reason: c.Reason, ChangedAt: c.ChangedAt}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
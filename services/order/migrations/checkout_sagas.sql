@@ -0,0 +1,17 @@
+-- the checkout of each order: reserve stock, authorize payment, confirm, or compensate what was done
+CREATE TABLE IF NOT EXISTS checkout_sagas(
+    order_id integer PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    step TEXT NOT NULL DEFAULT 'started'
+        CHECK (step IN ('started', 'stock_reserved', 'payment_pending', 'payment_authorized', 'completed', 'compensating', 'compensated')),
+    payment_reference TEXT NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- an instance running the saga holds it until then; a saga whose lease ran out is resumed by another
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS checkout_sagas_unfinished_idx ON checkout_sagas (locked_until)
+    WHERE step NOT IN ('completed', 'compensated');
@@ -1,6 +1,19 @@
 CREATE TABLE IF NOT EXISTS orders(
     id SERIAL PRIMARY KEY,
-    product_id integer NOT NULL,
-    quantity integer NOT NULL,
-    total DOUBLE PRECISION
-);
\ No newline at end of file
+    user_id integer NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'awaiting_payment', 'paid', 'fulfilling', 'shipped', 'delivered', 'cancelled', 'refunded')),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    -- at regular prices; total = subtotal - discount_total + tax_total + shipping_total
+    subtotal NUMERIC(19,4) NOT NULL DEFAULT 0,
+    discount_total NUMERIC(19,4) NOT NULL DEFAULT 0,
+    tax_total NUMERIC(19,4) NOT NULL DEFAULT 0,
+    shipping_total NUMERIC(19,4) NOT NULL DEFAULT 0,
+    total NUMERIC(19,4) NOT NULL DEFAULT 0,
+    -- what was given back of total, e.g. for cancelled lines
+    refunded_total NUMERIC(19,4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id, created_at DESC);
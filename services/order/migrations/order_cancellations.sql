@@ -0,0 +1,25 @@
+-- cancellations of whole orders or of some of their lines
+CREATE TABLE IF NOT EXISTS order_cancellations(
+    id SERIAL PRIMARY KEY,
+    order_id integer NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('customer_request', 'out_of_stock', 'payment_issue', 'fraud_suspected', 'duplicate_order', 'other')),
+    note TEXT NOT NULL DEFAULT '',
+    -- status of the order when it was cancelled
+    from_status TEXT NOT NULL,
+    -- nothing was left of the order afterwards
+    full_cancel BOOLEAN NOT NULL DEFAULT false,
+    refund_id integer REFERENCES order_refunds(id),
+    -- user id from the auth service
+    cancelled_by integer NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_cancellations_order_idx ON order_cancellations (order_id);
+
+CREATE TABLE IF NOT EXISTS order_cancellation_lines(
+    cancellation_id integer NOT NULL REFERENCES order_cancellations(id) ON DELETE CASCADE,
+    order_line_id integer NOT NULL REFERENCES order_lines(id) ON DELETE CASCADE,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (cancellation_id, order_line_id)
+);
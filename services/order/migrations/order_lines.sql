@@ -0,0 +1,18 @@
+-- unit_price is a snapshot: later price changes do not touch placed orders
+CREATE TABLE IF NOT EXISTS order_lines(
+    id SERIAL PRIMARY KEY,
+    order_id integer NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id integer NOT NULL,
+    sku TEXT NOT NULL,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    cancelled_quantity integer NOT NULL DEFAULT 0 CHECK (cancelled_quantity >= 0 AND cancelled_quantity <= quantity),
+    -- held by open returns, then received back
+    returning_quantity integer NOT NULL DEFAULT 0 CHECK (returning_quantity >= 0),
+    returned_quantity integer NOT NULL DEFAULT 0 CHECK (returned_quantity >= 0),
+    unit_price NUMERIC(19,4) NOT NULL,
+    -- savings against the regular price, line_total is already net of it
+    discount NUMERIC(19,4) NOT NULL DEFAULT 0,
+    line_total NUMERIC(19,4) NOT NULL,
+    UNIQUE (order_id, product_id),
+    CHECK (cancelled_quantity + returning_quantity + returned_quantity <= quantity)
+);
@@ -0,0 +1,17 @@
+-- events written in the transaction of the change they announce and relayed to Kafka afterwards,
+-- at least once; consumers drop redeliveries by their id, sent in the event-id header
+CREATE TABLE IF NOT EXISTS order_outbox(
+    id BIGSERIAL PRIMARY KEY,
+    key TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    value BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    attempts integer NOT NULL DEFAULT 0,
+    -- while a relay holds the message it is its lease, after a failure the time of the next try
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS order_outbox_pending_idx ON order_outbox (next_attempt_at) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS order_outbox_key_idx ON order_outbox (key, id) WHERE sent_at IS NULL;
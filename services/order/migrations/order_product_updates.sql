@@ -0,0 +1,15 @@
+-- what the product service must learn of a status change, saved with it: the stock of a shipped order
+-- leaves the warehouse, the customer of a delivered one may review its products. An update is due until
+-- done_at is set and retried once its lease runs out.
+CREATE TABLE IF NOT EXISTS order_product_updates(
+    id SERIAL PRIMARY KEY,
+    order_id integer NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('commit_stock', 'record_purchase')),
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    done_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (order_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS order_product_updates_due_idx ON order_product_updates (locked_until) WHERE done_at IS NULL;
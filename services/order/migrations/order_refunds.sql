@@ -0,0 +1,13 @@
+-- money given back to customers; a refund is due until refunded_at is set and retried once its lease runs out
+CREATE TABLE IF NOT EXISTS order_refunds(
+    id SERIAL PRIMARY KEY,
+    order_id integer NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    amount NUMERIC(19,4) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    refunded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_refunds_due_idx ON order_refunds (locked_until) WHERE refunded_at IS NULL;
@@ -0,0 +1,31 @@
+-- returns of delivered goods (RMA): requested by the customer, approved or rejected by staff, then received
+CREATE TABLE IF NOT EXISTS order_returns(
+    id SERIAL PRIMARY KEY,
+    order_id integer NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'requested'
+        CHECK (status IN ('requested', 'approved', 'rejected', 'received')),
+    reason TEXT NOT NULL
+        CHECK (reason IN ('damaged', 'defective', 'wrong_item', 'not_as_described', 'no_longer_needed', 'other')),
+    note TEXT NOT NULL DEFAULT '',
+    -- user ids from the auth service
+    requested_by integer NOT NULL,
+    reviewed_by integer NOT NULL DEFAULT 0,
+    review_note TEXT NOT NULL DEFAULT '',
+    refund_id integer REFERENCES order_refunds(id),
+    -- set once the goods received are back in stock
+    restocked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_returns_order_idx ON order_returns (order_id);
+CREATE INDEX IF NOT EXISTS order_returns_restock_idx ON order_returns (updated_at) WHERE status = 'received' AND restocked_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS order_return_lines(
+    return_id integer NOT NULL REFERENCES order_returns(id) ON DELETE CASCADE,
+    order_line_id integer NOT NULL REFERENCES order_lines(id) ON DELETE CASCADE,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    -- the goods can be sold again, decided when they are received
+    restock BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (return_id, order_line_id)
+);
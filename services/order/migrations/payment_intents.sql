@@ -0,0 +1,23 @@
+-- the payment of each order through a gateway: authorized at checkout, captured once the order is confirmed
+CREATE TABLE IF NOT EXISTS payment_intents(
+    id SERIAL PRIMARY KEY,
+    order_id integer NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    gateway TEXT NOT NULL,
+    -- the id of the payment at the gateway, once it was sent there
+    reference TEXT NOT NULL DEFAULT '',
+    -- token of the card or wallet, issued by the gateway
+    payment_method TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'requires_action', 'authorized', 'captured', 'voided', 'failed', 'refunded')),
+    amount NUMERIC(19,4) NOT NULL,
+    captured_amount NUMERIC(19,4) NOT NULL DEFAULT 0,
+    refunded_amount NUMERIC(19,4) NOT NULL DEFAULT 0 CHECK (refunded_amount <= captured_amount),
+    next_action_url TEXT NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    -- counts the updates, so concurrent ones are told apart
+    version integer NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS payment_intents_reference_idx ON payment_intents (gateway, reference) WHERE reference <> '';
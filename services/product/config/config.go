@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type Config struct {
+	WebServerPort   string
+	DatabaseDSN     string
+	RedisAddr       string
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	GRPCServerPort  string
+	FXRatesFile     string
+	MediaDir        string
+	MediaBaseURL    string
+	KafkaAddr       string
+	// SiteURL is the storefront the sitemap links products and categories to
+	SiteURL string
+	// SitemapURL is where this service's /sitemaps are reachable, listed by the sitemap index
+	SitemapURL string
+	// PriceSchedulerInterval is how often scheduled price changes are checked
+	PriceSchedulerInterval time.Duration
+}
@@ -0,0 +1,276 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format, use csv or jsonl")
+	ErrMissingColumn     = errors.New("missing column in csv header")
+)
+
+// Columns is the CSV header used for import and export
+var Columns = []string{"sku", "name", "description", "category", "price", "currency", "attributes"}
+
+// ParseFormat accepts a format name, file extension or content type
+func ParseFormat(v string) (Format, error) {
+
+	switch strings.ToLower(strings.TrimPrefix(v, ".")) {
+	case "csv", "text/csv":
+		return CSV, nil
+	case "jsonl", "ndjson", "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return JSONL, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Record is one input row; Err holds the reason the row could not be turned into a valid product
+type Record struct {
+	Line    int
+	SKU     string
+	Product *entity.Product
+	Err     error
+}
+
+// Source yields records until io.EOF
+type Source interface {
+	Next() (Record, error)
+}
+
+// jsonProduct is the JSON Lines representation of a product, with the price in major units
+type jsonProduct struct {
+	SKU         string `json:"sku"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Price       string `json:"price"`
+	Currency    string `json:"currency"`
+	// Attributes replace the stored ones, a row without them clears them
+	Attributes entity.Attributes `json:"attributes,omitempty"`
+}
+
+func NewReader(r io.Reader, format Format) (Source, error) {
+
+	switch format {
+	case CSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		return &csvReader{r: cr}, nil
+	case JSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &jsonlReader{sc: sc}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	index  map[string]int
+	line   int
+	header error
+}
+
+func (c *csvReader) Next() (Record, error) {
+
+	if c.index == nil && c.header == nil {
+		c.header = c.readHeader()
+	}
+	if c.header != nil {
+		return Record{}, c.header
+	}
+
+	fields, err := c.r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return Record{}, io.EOF
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			c.line = perr.Line
+			return Record{Line: c.line, Err: err}, nil
+		}
+		return Record{}, err
+	}
+	c.line, _ = c.r.FieldPos(0)
+
+	get := func(col string) string {
+		if i, ok := c.index[col]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	jp := jsonProduct{
+		SKU:         get("sku"),
+		Name:        get("name"),
+		Description: get("description"),
+		Category:    get("category"),
+		Price:       get("price"),
+		Currency:    get("currency"),
+	}
+	// the attributes column holds a JSON object
+	if raw := get("attributes"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &jp.Attributes); err != nil {
+			return Record{Line: c.line, SKU: jp.SKU, Err: fmt.Errorf("invalid attributes: %w", err)}, nil
+		}
+	}
+
+	return newRecord(c.line, jp), nil
+}
+
+func (c *csvReader) readHeader() error {
+
+	header, err := c.r.Read()
+	if err != nil {
+		return err
+	}
+
+	c.index = make(map[string]int, len(header))
+	for i, h := range header {
+		c.index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, col := range []string{"sku", "name", "price", "currency"} {
+		if _, ok := c.index[col]; !ok {
+			return fmt.Errorf("%w: %s", ErrMissingColumn, col)
+		}
+	}
+	return nil
+}
+
+type jsonlReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func (j *jsonlReader) Next() (Record, error) {
+
+	for j.sc.Scan() {
+		j.line++
+		text := strings.TrimSpace(j.sc.Text())
+		if text == "" {
+			continue
+		}
+
+		var jp jsonProduct
+		if err := json.Unmarshal([]byte(text), &jp); err != nil {
+			return Record{Line: j.line, Err: err}, nil
+		}
+		return newRecord(j.line, jp), nil
+	}
+
+	if err := j.sc.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+func newRecord(line int, jp jsonProduct) Record {
+
+	rec := Record{Line: line, SKU: jp.SKU}
+
+	price, err := money.Parse(jp.Price, jp.Currency)
+	if err != nil {
+		rec.Err = err
+		return rec
+	}
+
+	rec.Product, rec.Err = entity.NewProduct(0, jp.SKU, jp.Name, jp.Description, jp.Category, price)
+	if rec.Product != nil {
+		rec.Product.Attributes = jp.Attributes
+	}
+	return rec
+}
+
+// Writer encodes products in one of the bulk formats; Flush must be called when done
+type Writer interface {
+	Write(p entity.Product) error
+	Flush() error
+}
+
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case JSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (c *csvWriter) Write(p entity.Product) error {
+
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		if err := c.w.Write(Columns); err != nil {
+			return err
+		}
+	}
+	var attributes string
+	if len(p.Attributes) > 0 {
+		b, err := json.Marshal(p.Attributes)
+		if err != nil {
+			return err
+		}
+		attributes = string(b)
+	}
+	return c.w.Write([]string{p.SKU, p.Name, p.Description, p.Category, p.Price.Decimal(), p.Price.Currency, attributes})
+}
+
+func (c *csvWriter) Flush() error {
+
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		if err := c.w.Write(Columns); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(p entity.Product) error {
+
+	return j.enc.Encode(jsonProduct{
+		SKU:         p.SKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Category:    p.Category,
+		Price:       p.Price.Decimal(),
+		Currency:    p.Price.Currency,
+		Attributes:  p.Attributes,
+	})
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
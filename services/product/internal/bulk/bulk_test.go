@@ -0,0 +1,92 @@
+package bulk
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, src Source) []Record {
+
+	var records []Record
+	for {
+		rec, err := src.Next()
+		if err == io.EOF {
+			return records
+		}
+		assert.Nil(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+
+	src, err := NewReader(strings.NewReader("sku,name,price,currency\nA-1,Mug,12.50,USD\nA-2,,1,USD\nA-3,Cup,abc,USD\n"), CSV)
+	assert.Nil(t, err)
+
+	records := readAll(t, src)
+
+	assert.Len(t, records, 3)
+	assert.Nil(t, records[0].Err)
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, money.Money{Amount: 1250, Currency: "USD"}, records[0].Product.Price)
+	assert.Equal(t, entity.ErrNameIsRequired, records[1].Err)
+	assert.Equal(t, money.ErrInvalidAmount, records[2].Err)
+}
+
+func TestReadCSVWhenColumnIsMissing(t *testing.T) {
+
+	src, _ := NewReader(strings.NewReader("sku,name,currency\nA-1,Mug,USD\n"), CSV)
+
+	_, err := src.Next()
+
+	assert.ErrorIs(t, err, ErrMissingColumn)
+}
+
+func TestReadJSONL(t *testing.T) {
+
+	src, err := NewReader(strings.NewReader(`{"sku":"A-1","name":"Mug","price":"3","currency":"EUR"}`+"\n\n{oops\n"), JSONL)
+	assert.Nil(t, err)
+
+	records := readAll(t, src)
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, "A-1", records[0].Product.SKU)
+	assert.NotNil(t, records[1].Err)
+	assert.Equal(t, 3, records[1].Line)
+}
+
+func TestWriteCSV(t *testing.T) {
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, CSV)
+
+	p, _ := entity.NewProduct(1, "A-1", "Mug", "Large, blue", "kitchen", money.Money{Amount: 1250, Currency: "USD"})
+	assert.Nil(t, w.Write(*p))
+	p.Attributes = entity.Attributes{"capacity_ml": 350.0}
+	assert.Nil(t, w.Write(*p))
+	assert.Nil(t, w.Flush())
+
+	assert.Equal(t, "sku,name,description,category,price,currency,attributes\n"+
+		"A-1,Mug,\"Large, blue\",kitchen,12.50,USD,\n"+
+		"A-1,Mug,\"Large, blue\",kitchen,12.50,USD,\"{\"\"capacity_ml\"\":350}\"\n", buf.String())
+}
+
+func TestReadCSVAttributes(t *testing.T) {
+
+	src, _ := NewReader(strings.NewReader("sku,name,price,currency,attributes\nA-1,Mug,1,USD,\"{\"\"capacity_ml\"\":350}\"\nA-2,Cup,1,USD,{oops\n"), CSV)
+
+	rec, err := src.Next()
+	assert.Nil(t, err)
+	assert.Nil(t, rec.Err)
+	assert.Equal(t, entity.Attributes{"capacity_ml": 350.0}, rec.Product.Attributes)
+
+	rec, err = src.Next()
+	assert.Nil(t, err)
+	assert.NotNil(t, rec.Err)
+}
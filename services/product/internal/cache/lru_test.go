@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+
+	c := NewLRU[int, string](2, time.Minute)
+	c.Add(1, "a")
+	c.Add(2, "b")
+
+	// touching 1 makes 2 the oldest
+	_, ok := c.Get(1)
+	assert.True(t, ok)
+	c.Add(3, "c")
+
+	_, ok = c.Get(2)
+	assert.False(t, ok)
+	v, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRUExpires(t *testing.T) {
+
+	now := time.Now()
+	c := NewLRU[int, string](2, time.Second)
+	c.now = func() time.Time { return now }
+	c.Add(1, "a")
+
+	now = now.Add(2 * time.Second)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRURemove(t *testing.T) {
+
+	c := NewLRU[int, string](2, time.Minute)
+	c.Add(1, "a")
+	c.Remove(1)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,234 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAttributeCode     = errors.New("attribute code must be lowercase letters, digits and underscores")
+	ErrInvalidAttributeType     = errors.New("attribute type must be string, number, enum or bool")
+	ErrAttributeOptionsRequired = errors.New("enum attributes need at least one option")
+	ErrAttributeAlreadyExists   = errors.New("attribute already defined for the category")
+	ErrUnknownAttribute         = errors.New("attribute is not defined for the category")
+	ErrInvalidAttributeValue    = errors.New("invalid attribute value")
+	ErrAttributeRequired        = errors.New("attribute is required")
+	ErrFilterCategoryRequired   = errors.New("attribute filters need a category")
+)
+
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeEnum   AttributeType = "enum"
+	AttributeBool   AttributeType = "bool"
+)
+
+var attributeCode = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AttributeDefinition describes one specification field of the products in a category, e.g.
+// "screen_size", a number in inches, for "tvs"
+type AttributeDefinition struct {
+	ID       int64           `db:"id" json:"id"`
+	Category string          `db:"category" json:"category"`
+	Code     string          `db:"code" json:"code"`
+	Label    string          `db:"label" json:"label"`
+	Type     AttributeType   `db:"type" json:"type"`
+	Unit     string          `db:"unit" json:"unit,omitempty"`
+	Options  AttributeValues `db:"options" json:"options,omitempty"`
+	Required bool            `db:"required" json:"required"`
+}
+
+func NewAttributeDefinition(category, code, label string, typ AttributeType, unit string, options []string, required bool) (*AttributeDefinition, error) {
+
+	d := &AttributeDefinition{
+		Category: category,
+		Code:     strings.ToLower(strings.TrimSpace(code)),
+		Label:    label,
+		Type:     typ,
+		Unit:     unit,
+		Options:  options,
+		Required: required,
+	}
+
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *AttributeDefinition) Validate() error {
+
+	if !attributeCode.MatchString(d.Code) {
+		return ErrInvalidAttributeCode
+	}
+	switch d.Type {
+	case AttributeString, AttributeNumber, AttributeBool:
+	case AttributeEnum:
+		if len(d.Options) == 0 {
+			return ErrAttributeOptionsRequired
+		}
+	default:
+		return ErrInvalidAttributeType
+	}
+	return nil
+}
+
+// Check validates a value decoded from JSON: numbers arrive as float64
+func (d *AttributeDefinition) Check(v any) error {
+
+	ok := false
+	switch d.Type {
+	case AttributeString:
+		_, ok = v.(string)
+	case AttributeNumber:
+		_, ok = v.(float64)
+	case AttributeBool:
+		_, ok = v.(bool)
+	case AttributeEnum:
+		s, isString := v.(string)
+		ok = isString && slices.Contains(d.Options, s)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s must be a %s", ErrInvalidAttributeValue, d.Code, d.Type)
+	}
+	return nil
+}
+
+// Parse converts the text form of a value, as found in a query string, to the attribute type
+func (d *AttributeDefinition) Parse(raw string) (any, error) {
+
+	var v any = raw
+	switch d.Type {
+	case AttributeNumber:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a %s", ErrInvalidAttributeValue, d.Code, d.Type)
+		}
+		v = f
+	case AttributeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a %s", ErrInvalidAttributeValue, d.Code, d.Type)
+		}
+		v = b
+	}
+	if err := d.Check(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Attributes holds the specification values of a product by attribute code; it is stored as a JSON column
+type Attributes map[string]any
+
+// Validate checks the values against the definitions of the product category
+func (a Attributes) Validate(defs []AttributeDefinition) error {
+
+	byCode := make(map[string]*AttributeDefinition, len(defs))
+	for i := range defs {
+		byCode[defs[i].Code] = &defs[i]
+	}
+
+	for code, v := range a {
+		d, ok := byCode[code]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownAttribute, code)
+		}
+		if err := d.Check(v); err != nil {
+			return err
+		}
+	}
+	for _, d := range defs {
+		if _, ok := a[d.Code]; d.Required && !ok {
+			return fmt.Errorf("%w: %s", ErrAttributeRequired, d.Code)
+		}
+	}
+	return nil
+}
+
+func (a Attributes) Value() (driver.Value, error) {
+
+	if a == nil {
+		a = Attributes{}
+	}
+	// sent as text, lib/pq would encode []byte as bytea
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *Attributes) Scan(src any) error {
+	return scanJSON(src, a)
+}
+
+// AttributeValues is a list of strings stored as a JSON column
+type AttributeValues []string
+
+func (v AttributeValues) Value() (driver.Value, error) {
+
+	if v == nil {
+		v = AttributeValues{}
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (v *AttributeValues) Scan(src any) error {
+	return scanJSON(src, v)
+}
+
+// AttributeFilter matches products whose attribute equals a value, or for numbers lies within [Min, Max].
+// Equals holds the text form until it is resolved against the category definitions.
+type AttributeFilter struct {
+	Code   string
+	Equals any
+	Min    *float64
+	Max    *float64
+}
+
+// ResolveAttributeFilters checks the filters against the definitions of a category and converts
+// the text values to the attribute types, so that they compare equal to the stored values
+func ResolveAttributeFilters(defs []AttributeDefinition, filters []AttributeFilter) ([]AttributeFilter, error) {
+
+	resolved := make([]AttributeFilter, 0, len(filters))
+	for _, f := range filters {
+		i := slices.IndexFunc(defs, func(d AttributeDefinition) bool { return d.Code == f.Code })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAttribute, f.Code)
+		}
+		d := &defs[i]
+
+		if raw, ok := f.Equals.(string); ok {
+			v, err := d.Parse(raw)
+			if err != nil {
+				return nil, err
+			}
+			f.Equals = v
+		}
+		if (f.Min != nil || f.Max != nil) && d.Type != AttributeNumber {
+			return nil, fmt.Errorf("%w: %s is not a number, it cannot be filtered by range", ErrInvalidAttributeValue, d.Code)
+		}
+		resolved = append(resolved, f)
+	}
+	return resolved, nil
+}
+
+func scanJSON(src any, dest any) error {
+
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	case nil:
+		return nil
+	}
+	return errors.New("unsupported json value")
+}
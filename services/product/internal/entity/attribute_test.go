@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tvAttributes() []AttributeDefinition {
+
+	size, _ := NewAttributeDefinition("tvs", "screen_size", "Screen size", AttributeNumber, "in", nil, true)
+	panel, _ := NewAttributeDefinition("tvs", "panel", "Panel", AttributeEnum, "", []string{"lcd", "oled"}, false)
+	return []AttributeDefinition{*size, *panel}
+}
+
+func TestNewAttributeDefinitionWhenEnumHasNoOptions(t *testing.T) {
+
+	_, err := NewAttributeDefinition("tvs", "panel", "Panel", AttributeEnum, "", nil, false)
+	assert.Equal(t, ErrAttributeOptionsRequired, err)
+
+	_, err = NewAttributeDefinition("tvs", "Screen Size", "", AttributeNumber, "", nil, false)
+	assert.Equal(t, ErrInvalidAttributeCode, err)
+}
+
+func TestAttributesValidate(t *testing.T) {
+
+	defs := tvAttributes()
+
+	assert.Nil(t, Attributes{"screen_size": 55.0, "panel": "oled"}.Validate(defs))
+	assert.ErrorIs(t, Attributes{"panel": "oled"}.Validate(defs), ErrAttributeRequired)
+	assert.ErrorIs(t, Attributes{"screen_size": "55"}.Validate(defs), ErrInvalidAttributeValue)
+	assert.ErrorIs(t, Attributes{"screen_size": 55.0, "panel": "plasma"}.Validate(defs), ErrInvalidAttributeValue)
+	assert.ErrorIs(t, Attributes{"screen_size": 55.0, "weight": 12.0}.Validate(defs), ErrUnknownAttribute)
+}
+
+func TestResolveAttributeFilters(t *testing.T) {
+
+	min := 50.0
+	filters, err := ResolveAttributeFilters(tvAttributes(), []AttributeFilter{
+		{Code: "screen_size", Equals: "55"},
+		{Code: "screen_size", Min: &min},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 55.0, filters[0].Equals)
+
+	_, err = ResolveAttributeFilters(tvAttributes(), []AttributeFilter{{Code: "panel", Min: &min}})
+	assert.ErrorIs(t, err, ErrInvalidAttributeValue)
+
+	_, err = ResolveAttributeFilters(tvAttributes(), []AttributeFilter{{Code: "weight", Equals: "1"}})
+	assert.ErrorIs(t, err, ErrUnknownAttribute)
+}
@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"errors"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var (
+	ErrBundleNeedsComponents  = errors.New("bundle needs at least one component")
+	ErrInvalidComponentQty    = errors.New("component quantity must be positive")
+	ErrDuplicateComponent     = errors.New("component listed more than once")
+	ErrNestedBundle           = errors.New("a bundle cannot contain bundles")
+	ErrInvalidBundlePricing   = errors.New("bundle pricing must be fixed or percent_off")
+	ErrInvalidPercentOff      = errors.New("percent off must be between 0 and 100")
+	ErrNotABundle             = errors.New("product is not a bundle")
+	ErrBundleStockIsComputed  = errors.New("bundle stock comes from its components")
+	ErrComponentInUse         = errors.New("product is a component of a bundle")
+	ErrComponentNotFound      = errors.New("bundle component not found")
+	ErrInvalidReservationItem = errors.New("reserved quantity must be positive")
+	ErrReleaseIdIsRequired    = errors.New("release id is required")
+	ErrReferenceIsRequired    = errors.New("reference is required")
+)
+
+type ProductKind string
+
+const (
+	ProductSimple ProductKind = "simple"
+	// ProductBundle is sold as one line item and takes its stock from its components
+	ProductBundle ProductKind = "bundle"
+)
+
+type BundlePricing string
+
+const (
+	// BundleFixed sells the bundle at the price of the bundle product
+	BundleFixed BundlePricing = "fixed"
+	// BundlePercentOff sells the bundle at the sum of its components minus a percentage
+	BundlePercentOff BundlePricing = "percent_off"
+)
+
+type BundleComponent struct {
+	ProductID int64  `json:"product_id"`
+	SKU       string `json:"sku"`
+	Quantity  int64  `json:"quantity"`
+}
+
+type Bundle struct {
+	ProductID  int64             `json:"product_id"`
+	Pricing    BundlePricing     `json:"pricing"`
+	PercentOff int64             `json:"percent_off,omitempty"`
+	Components []BundleComponent `json:"components"`
+}
+
+func NewBundle(productID int64, pricing BundlePricing, percentOff int64, components []BundleComponent) (*Bundle, error) {
+
+	b := &Bundle{ProductID: productID, Pricing: pricing, PercentOff: percentOff, Components: components}
+
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Bundle) Validate() error {
+
+	switch b.Pricing {
+	case BundleFixed:
+	case BundlePercentOff:
+		if b.PercentOff < 0 || b.PercentOff > 100 {
+			return ErrInvalidPercentOff
+		}
+	default:
+		return ErrInvalidBundlePricing
+	}
+
+	if len(b.Components) == 0 {
+		return ErrBundleNeedsComponents
+	}
+	seen := make(map[string]bool, len(b.Components))
+	for _, c := range b.Components {
+		if c.Quantity <= 0 {
+			return ErrInvalidComponentQty
+		}
+		if seen[c.SKU] {
+			return ErrDuplicateComponent
+		}
+		seen[c.SKU] = true
+	}
+	return nil
+}
+
+// Price is the percent off price of a bundle whose components cost prices, given in component order
+func (b *Bundle) Price(prices []money.Money) (money.Money, error) {
+
+	var sum money.Money
+	for i, c := range b.Components {
+		line := prices[i].Mul(c.Quantity)
+		if i == 0 {
+			sum = line
+			continue
+		}
+		var err error
+		if sum, err = sum.Add(line); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return sum.PercentOff(b.PercentOff), nil
+}
+
+// Available is how many bundles the components can make, given what is available of each in component order
+func (b *Bundle) Available(available []int64) int64 {
+
+	var n int64
+	for i, c := range b.Components {
+		can := max(available[i], 0) / c.Quantity
+		if i == 0 || can < n {
+			n = can
+		}
+	}
+	return n
+}
+
+// StockItem is a quantity of a product to reserve or release
+type StockItem struct {
+	ProductID int64
+	Quantity  int64
+}
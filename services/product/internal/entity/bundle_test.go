@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBundle(t *testing.T) {
+
+	components := []BundleComponent{{SKU: "MUG", Quantity: 2}, {SKU: "TEA", Quantity: 1}}
+
+	_, err := NewBundle(0, BundlePercentOff, 15, components)
+	assert.Nil(t, err)
+
+	_, err = NewBundle(0, "half_off", 0, components)
+	assert.Equal(t, ErrInvalidBundlePricing, err)
+
+	_, err = NewBundle(0, BundlePercentOff, 101, components)
+	assert.Equal(t, ErrInvalidPercentOff, err)
+
+	_, err = NewBundle(0, BundleFixed, 0, nil)
+	assert.Equal(t, ErrBundleNeedsComponents, err)
+
+	_, err = NewBundle(0, BundleFixed, 0, []BundleComponent{{SKU: "MUG", Quantity: 0}})
+	assert.Equal(t, ErrInvalidComponentQty, err)
+
+	_, err = NewBundle(0, BundleFixed, 0, []BundleComponent{{SKU: "MUG", Quantity: 1}, {SKU: "MUG", Quantity: 2}})
+	assert.Equal(t, ErrDuplicateComponent, err)
+}
+
+func TestBundlePriceAndAvailable(t *testing.T) {
+
+	b := &Bundle{Pricing: BundlePercentOff, PercentOff: 10, Components: []BundleComponent{{SKU: "MUG", Quantity: 2}, {SKU: "TEA", Quantity: 1}}}
+
+	price, err := b.Price([]money.Money{{Amount: 1000, Currency: "USD"}, {Amount: 500, Currency: "USD"}})
+	assert.Nil(t, err)
+	assert.Equal(t, money.Money{Amount: 2250, Currency: "USD"}, price)
+
+	_, err = b.Price([]money.Money{{Amount: 1000, Currency: "USD"}, {Amount: 500, Currency: "EUR"}})
+	assert.Equal(t, money.ErrCurrencyMismatch, err)
+
+	assert.Equal(t, int64(3), b.Available([]int64{7, 4}))
+	assert.Equal(t, int64(0), b.Available([]int64{7, -1}))
+}
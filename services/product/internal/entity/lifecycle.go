@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidProductStatus = errors.New("status must be draft, active or archived")
+	ErrPublishNeedsActive   = errors.New("published_at only applies to active products")
+	ErrStatusChanged        = errors.New("product status changed meanwhile, try again")
+)
+
+type ProductStatus string
+
+const (
+	// ProductDraft is being prepared and only staff see it; new products start here
+	ProductDraft ProductStatus = "draft"
+	// ProductActive is sold from PublishedAt on
+	ProductActive ProductStatus = "active"
+	// ProductArchived is no longer sold; staff still see it and orders keep referring to it
+	ProductArchived ProductStatus = "archived"
+)
+
+func (s ProductStatus) Validate() error {
+
+	switch s {
+	case ProductDraft, ProductActive, ProductArchived:
+		return nil
+	}
+	return ErrInvalidProductStatus
+}
+
+// StatusChange records who moved a product to another status, and when
+type StatusChange struct {
+	ID          int64         `json:"id"`
+	ProductID   int64         `json:"product_id"`
+	From        ProductStatus `json:"from"`
+	To          ProductStatus `json:"to"`
+	PublishedAt *time.Time    `json:"published_at,omitempty"`
+	// ChangedBy is the user id of the staff member, 0 for internal callers
+	ChangedBy int64     `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// NewStatusChange moves p to status. An active product is published at publishedAt, now when nil,
+// so a time in the future schedules the publication.
+func NewStatusChange(p *Product, status ProductStatus, publishedAt *time.Time, changedBy int64, now time.Time) (*StatusChange, error) {
+
+	if err := status.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := &StatusChange{ProductID: p.ID, From: p.Status, To: status, ChangedBy: changedBy, ChangedAt: now.UTC()}
+	switch {
+	case status == ProductActive && publishedAt != nil:
+		at := publishedAt.UTC()
+		c.PublishedAt = &at
+	case status == ProductActive:
+		c.PublishedAt = &c.ChangedAt
+	case publishedAt != nil:
+		return nil, ErrPublishNeedsActive
+	}
+	return c, nil
+}
+
+// IsVisible tells whether customers see the product at now
+func (p *Product) IsVisible(now time.Time) bool {
+	return p.Status == ProductActive && (p.PublishedAt == nil || !p.PublishedAt.After(now))
+}
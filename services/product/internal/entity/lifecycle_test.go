@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStatusChange(t *testing.T) {
+
+	now := time.Now().UTC()
+	p := &Product{ID: 1, Status: ProductDraft}
+
+	c, err := NewStatusChange(p, ProductActive, nil, 7, now)
+	assert.Nil(t, err)
+	assert.Equal(t, ProductDraft, c.From)
+	assert.Equal(t, ProductActive, c.To)
+	assert.Equal(t, now, *c.PublishedAt)
+	assert.Equal(t, int64(7), c.ChangedBy)
+
+	later := now.Add(time.Hour)
+	c, err = NewStatusChange(p, ProductActive, &later, 7, now)
+	assert.Nil(t, err)
+	assert.Equal(t, later, *c.PublishedAt)
+
+	_, err = NewStatusChange(p, ProductArchived, &later, 7, now)
+	assert.Equal(t, ErrPublishNeedsActive, err)
+
+	_, err = NewStatusChange(p, "deleted", nil, 7, now)
+	assert.Equal(t, ErrInvalidProductStatus, err)
+}
+
+func TestIsVisible(t *testing.T) {
+
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	assert.False(t, (&Product{Status: ProductDraft}).IsVisible(now))
+	assert.False(t, (&Product{Status: ProductArchived, PublishedAt: &past}).IsVisible(now))
+	assert.True(t, (&Product{Status: ProductActive, PublishedAt: &past}).IsVisible(now))
+	assert.False(t, (&Product{Status: ProductActive, PublishedAt: &future}).IsVisible(now))
+}
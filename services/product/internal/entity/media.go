@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrMediaNotFound      = errors.New("media not found")
+	ErrMediaOrderMismatch = errors.New("media order must list every media of the product exactly once")
+)
+
+// MediaRendition is a generated version of an uploaded image stored under Key in the blob store
+type MediaRendition struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Key    string `json:"key"`
+}
+
+// MediaRenditions is stored as a JSON column
+type MediaRenditions []MediaRendition
+
+func (r MediaRenditions) Value() (driver.Value, error) {
+
+	if r == nil {
+		r = MediaRenditions{}
+	}
+	// sent as text, lib/pq would encode []byte as bytea
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *MediaRenditions) Scan(src any) error {
+
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	case nil:
+		*r = nil
+		return nil
+	}
+	return errors.New("unsupported renditions value")
+}
+
+// ProductMedia is an image of a product, or of one of its variants when VariantSKU is set.
+// Media are shown in ascending Position.
+type ProductMedia struct {
+	ID          int64           `db:"id" json:"id"`
+	ProductID   int64           `db:"product_id" json:"product_id"`
+	VariantSKU  string          `db:"variant_sku" json:"variant_sku"`
+	Position    int             `db:"position" json:"position"`
+	AltText     string          `db:"alt_text" json:"alt_text"`
+	ContentType string          `db:"content_type" json:"content_type"`
+	Width       int             `db:"width" json:"width"`
+	Height      int             `db:"height" json:"height"`
+	OriginalKey string          `db:"original_key" json:"original_key"`
+	Renditions  MediaRenditions `db:"renditions" json:"renditions"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var (
+	ErrPriceListNameIsRequired = errors.New("price list name is required")
+	ErrPriceListNotFound       = errors.New("price list not found")
+	ErrInvalidEffectiveRange   = errors.New("price list must end after it starts")
+	ErrPriceNotAvailable       = errors.New("price not available for the requested context")
+)
+
+// PriceList holds prices in a single currency, optionally restricted to a region and a customer group.
+// An empty Region or CustomerGroup matches any caller.
+type PriceList struct {
+	ID            int64      `db:"id" json:"id"`
+	Name          string     `db:"name" json:"name"`
+	Currency      string     `db:"currency" json:"currency"`
+	Region        string     `db:"region" json:"region"`
+	CustomerGroup string     `db:"customer_group" json:"customer_group"`
+	Priority      int        `db:"priority" json:"priority"`
+	StartsAt      time.Time  `db:"starts_at" json:"starts_at"`
+	EndsAt        *time.Time `db:"ends_at" json:"ends_at,omitempty"`
+}
+
+func NewPriceList(id int64, name, currency, region, customerGroup string, priority int, startsAt time.Time, endsAt *time.Time) (*PriceList, error) {
+
+	pl := &PriceList{
+		ID:            id,
+		Name:          name,
+		Currency:      strings.ToUpper(currency),
+		Region:        strings.ToUpper(region),
+		CustomerGroup: customerGroup,
+		Priority:      priority,
+		StartsAt:      startsAt,
+		EndsAt:        endsAt,
+	}
+
+	if err := pl.Validate(); err != nil {
+		return nil, err
+	}
+	return pl, nil
+}
+
+func (pl *PriceList) Validate() error {
+
+	if pl.Name == "" {
+		return ErrPriceListNameIsRequired
+	}
+
+	if err := (money.Money{Currency: pl.Currency}).Validate(); err != nil {
+		return err
+	}
+
+	if pl.EndsAt != nil && !pl.EndsAt.After(pl.StartsAt) {
+		return ErrInvalidEffectiveRange
+	}
+
+	return nil
+}
+
+// PriceContext describes who is asking for a price and when
+type PriceContext struct {
+	Currency      string
+	Region        string
+	CustomerGroup string
+	At            time.Time
+}
@@ -1,20 +1,47 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
 
 var (
-	ErrNameIsRequired = errors.New("name is required")
+	ErrSKUIsRequired    = errors.New("sku is required")
+	ErrNameIsRequired   = errors.New("name is required")
+	ErrNegativePrice    = errors.New("price must not be negative")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrSKUAlreadyExists = errors.New("sku already exists")
 )
 
 type Product struct {
-	ID    int64   `db:"id" json:"id"`
-	Name  string  `db:"name" json:"name"`
-	Price float64 `db:"price" json:"price"`
+	ID          int64       `db:"id" json:"id"`
+	SKU         string      `db:"sku" json:"sku"`
+	Name        string      `db:"name" json:"name"`
+	Description string      `db:"description" json:"description"`
+	Category    string      `db:"category" json:"category"`
+	Kind        ProductKind `db:"kind" json:"kind"`
+	Price       money.Money `db:"price" json:"price"`
+	// CompareAtPrice is the regular price while a sale is live, in the currency of Price
+	CompareAtPrice *money.Money `db:"compare_at_price" json:"compare_at_price,omitempty"`
+	// Attributes are validated against the definitions of Category
+	Attributes Attributes `db:"attributes" json:"attributes,omitempty"`
+	// Slug addresses the product in URLs; it follows the name, and the slugs it had keep redirecting here
+	Slug string `db:"slug" json:"slug"`
+	// SEOTitle and SEODescription replace the name and description in search engine results when set
+	SEOTitle       string `db:"seo_title" json:"seo_title,omitempty"`
+	SEODescription string `db:"seo_description" json:"seo_description,omitempty"`
+	// Status and PublishedAt decide whether customers see the product, see IsVisible
+	Status      ProductStatus `db:"status" json:"status"`
+	PublishedAt *time.Time    `db:"published_at" json:"published_at,omitempty"`
+	// Rating is maintained from approved reviews
+	Rating RatingSummary `db:"-" json:"rating"`
 }
 
-func NewProduct(id int64, name string, price float64) (*Product, error) {
+func NewProduct(id int64, sku, name, description, category string, price money.Money) (*Product, error) {
 
-	p := &Product{id, name, price}
+	p := &Product{ID: id, SKU: sku, Name: name, Description: description, Category: category, Kind: ProductSimple, Status: ProductDraft, Price: price}
 
 	if err := p.Validate(); err != nil {
 		return nil, err
@@ -24,9 +51,31 @@ func NewProduct(id int64, name string, price float64) (*Product, error) {
 
 func (p *Product) Validate() error {
 
+	if p.SKU == "" {
+		return ErrSKUIsRequired
+	}
+
 	if p.Name == "" {
 		return ErrNameIsRequired
 	}
 
+	if err := p.Price.Validate(); err != nil {
+		return err
+	}
+
+	if p.Price.IsNegative() {
+		return ErrNegativePrice
+	}
+
+	if p.Slug != "" {
+		if err := ValidateSlug(p.Slug); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSEO(p.SEOTitle, p.SEODescription); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -3,24 +3,52 @@ package entity
 import (
 	"testing"
 
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewProduct(t *testing.T) {
 
-	p, err := NewProduct(1, "Product", 2.1)
+	p, err := NewProduct(1, "SKU-1", "Product", "", "", money.Money{Amount: 210, Currency: "USD"})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, p)
 	assert.Equal(t, p.Name, "Product")
-	assert.Equal(t, p.Price, 2.1)
+	assert.Equal(t, p.Price, money.Money{Amount: 210, Currency: "USD"})
 }
 
 func TestNewProductWhenNameIsRequired(t *testing.T) {
 
-	p, err := NewProduct(1, "", 2.1)
+	p, err := NewProduct(1, "SKU-1", "", "", "", money.Money{Amount: 210, Currency: "USD"})
 
 	assert.NotNil(t, err)
 	assert.Nil(t, p)
 	assert.Equal(t, err, ErrNameIsRequired)
 }
+
+func TestNewProductWhenPriceIsNegative(t *testing.T) {
+
+	p, err := NewProduct(1, "SKU-1", "Product", "", "", money.Money{Amount: -1, Currency: "USD"})
+
+	assert.NotNil(t, err)
+	assert.Nil(t, p)
+	assert.Equal(t, err, ErrNegativePrice)
+}
+
+func TestNewProductWhenCurrencyIsInvalid(t *testing.T) {
+
+	p, err := NewProduct(1, "SKU-1", "Product", "", "", money.Money{Amount: 210, Currency: "???"})
+
+	assert.NotNil(t, err)
+	assert.Nil(t, p)
+	assert.Equal(t, err, money.ErrInvalidCurrency)
+}
+
+func TestNewProductWhenSKUIsRequired(t *testing.T) {
+
+	p, err := NewProduct(1, "", "Product", "", "", money.Money{Amount: 210, Currency: "USD"})
+
+	assert.NotNil(t, err)
+	assert.Nil(t, p)
+	assert.Equal(t, err, ErrSKUIsRequired)
+}
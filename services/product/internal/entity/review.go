@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidRating        = errors.New("rating must be between 1 and 5")
+	ErrReviewNotFound       = errors.New("review not found")
+	ErrReviewAlreadyExists  = errors.New("product already reviewed by this user")
+	ErrPurchaseRequired     = errors.New("only customers who bought the product can review it")
+	ErrInvalidReviewStatus  = errors.New("review status must be pending, approved or rejected")
+	ErrCannotVoteOwnReview  = errors.New("cannot vote on your own review")
+	ErrReviewBodyIsRequired = errors.New("review body is required")
+	ErrReviewNotPublished   = errors.New("review is not published")
+)
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+func (s ReviewStatus) Valid() bool {
+	return s == ReviewPending || s == ReviewApproved || s == ReviewRejected
+}
+
+// Review is published once a moderator approves it
+type Review struct {
+	ID               int64        `db:"id" json:"id"`
+	ProductID        int64        `db:"product_id" json:"product_id"`
+	UserID           int64        `db:"user_id" json:"user_id"`
+	Rating           int          `db:"rating" json:"rating"`
+	Title            string       `db:"title" json:"title"`
+	Body             string       `db:"body" json:"body"`
+	Status           ReviewStatus `db:"status" json:"status"`
+	VerifiedPurchase bool         `db:"verified_purchase" json:"verified_purchase"`
+	HelpfulCount     int          `db:"helpful_count" json:"helpful_count"`
+	CreatedAt        time.Time    `db:"created_at" json:"created_at"`
+	ModeratedAt      *time.Time   `db:"moderated_at" json:"moderated_at,omitempty"`
+}
+
+func NewReview(productID, userID int64, rating int, title, body string) (*Review, error) {
+
+	r := &Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    rating,
+		Title:     title,
+		Body:      body,
+		Status:    ReviewPending,
+		CreatedAt: time.Now(),
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Review) Validate() error {
+
+	if r.Rating < 1 || r.Rating > 5 {
+		return ErrInvalidRating
+	}
+	if r.Body == "" {
+		return ErrReviewBodyIsRequired
+	}
+	if !r.Status.Valid() {
+		return ErrInvalidReviewStatus
+	}
+	return nil
+}
+
+// RatingSummary aggregates the approved reviews of a product; Histogram[0] counts 1 star ratings
+type RatingSummary struct {
+	Average   float64 `json:"average"`
+	Count     int     `json:"count"`
+	Histogram [5]int  `json:"histogram"`
+}
+
+// NewRatingSummary derives the count and average from the histogram
+func NewRatingSummary(histogram [5]int) RatingSummary {
+
+	s := RatingSummary{Histogram: histogram}
+	sum := 0
+	for i, n := range histogram {
+		s.Count += n
+		sum += (i + 1) * n
+	}
+	if s.Count > 0 {
+		s.Average = float64(sum) / float64(s.Count)
+	}
+	return s
+}
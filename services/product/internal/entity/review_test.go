@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReview(t *testing.T) {
+
+	rv, err := NewReview(1, 2, 5, "Great", "Keeps coffee hot")
+
+	assert.Nil(t, err)
+	assert.Equal(t, ReviewPending, rv.Status)
+}
+
+func TestNewReviewWhenRatingIsOutOfRange(t *testing.T) {
+
+	_, err := NewReview(1, 2, 6, "", "Too good")
+	assert.Equal(t, ErrInvalidRating, err)
+
+	_, err = NewReview(1, 2, 0, "", "Too bad")
+	assert.Equal(t, ErrInvalidRating, err)
+}
+
+func TestNewRatingSummary(t *testing.T) {
+
+	s := NewRatingSummary([5]int{1, 0, 0, 0, 3})
+
+	assert.Equal(t, 4, s.Count)
+	assert.Equal(t, 4.0, s.Average)
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var (
+	ErrScheduledPriceNotFound   = errors.New("scheduled price not found")
+	ErrScheduledPriceNotPending = errors.New("only pending price changes can be cancelled")
+	ErrInvalidSaleWindow        = errors.New("sale must end after it starts")
+	ErrSaleAlreadyEnded         = errors.New("sale ends in the past")
+	ErrSaleOverlaps             = errors.New("sale overlaps another sale of the product")
+)
+
+type ScheduledPriceStatus string
+
+const (
+	// ScheduledPending waits for StartsAt
+	ScheduledPending ScheduledPriceStatus = "pending"
+	// ScheduledActive is a sale whose price is live until EndsAt
+	ScheduledActive ScheduledPriceStatus = "active"
+	// ScheduledApplied is a permanent change that went live
+	ScheduledApplied ScheduledPriceStatus = "applied"
+	// ScheduledEnded is a sale that was reverted, or that ended before it could start
+	ScheduledEnded     ScheduledPriceStatus = "ended"
+	ScheduledCancelled ScheduledPriceStatus = "cancelled"
+)
+
+// ScheduledPrice replaces the base price of a product at StartsAt. Without EndsAt the change is permanent;
+// with it the change is a sale, which shows the replaced price as the compare-at price and is reverted at EndsAt.
+type ScheduledPrice struct {
+	ID        int64                `json:"id"`
+	ProductID int64                `json:"product_id"`
+	Price     money.Money          `json:"price"`
+	StartsAt  time.Time            `json:"starts_at"`
+	EndsAt    *time.Time           `json:"ends_at,omitempty"`
+	Status    ScheduledPriceStatus `json:"status"`
+	// PreviousPrice is the base price the change replaced, set once it went live
+	PreviousPrice *money.Money `json:"previous_price,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// PriceChange is a base price change made by the scheduler
+type PriceChange struct {
+	ProductID int64
+	Old       money.Money
+	New       money.Money
+}
+
+func NewScheduledPrice(productID int64, price money.Money, startsAt time.Time, endsAt *time.Time, now time.Time) (*ScheduledPrice, error) {
+
+	sp := &ScheduledPrice{
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  startsAt.UTC(),
+		Status:    ScheduledPending,
+		CreatedAt: now.UTC(),
+	}
+	if endsAt != nil {
+		end := endsAt.UTC()
+		sp.EndsAt = &end
+	}
+
+	if err := sp.Validate(now); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+func (sp *ScheduledPrice) Validate(now time.Time) error {
+
+	if err := sp.Price.Validate(); err != nil {
+		return err
+	}
+	if sp.Price.IsNegative() {
+		return ErrNegativePrice
+	}
+	if sp.EndsAt != nil {
+		if !sp.EndsAt.After(sp.StartsAt) {
+			return ErrInvalidSaleWindow
+		}
+		if !sp.EndsAt.After(now) {
+			return ErrSaleAlreadyEnded
+		}
+	}
+	return nil
+}
+
+func (sp *ScheduledPrice) IsSale() bool {
+	return sp.EndsAt != nil
+}
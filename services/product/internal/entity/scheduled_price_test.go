@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScheduledPrice(t *testing.T) {
+
+	now := time.Now()
+	price := money.Money{Amount: 999, Currency: "USD"}
+
+	sp, err := NewScheduledPrice(1, price, now.Add(time.Hour), nil, now)
+	assert.Nil(t, err)
+	assert.Equal(t, ScheduledPending, sp.Status)
+	assert.False(t, sp.IsSale())
+
+	end := now.Add(time.Minute)
+	_, err = NewScheduledPrice(1, price, now.Add(time.Hour), &end, now)
+	assert.Equal(t, ErrInvalidSaleWindow, err)
+
+	past := now.Add(-time.Minute)
+	_, err = NewScheduledPrice(1, price, now.Add(-time.Hour), &past, now)
+	assert.Equal(t, ErrSaleAlreadyEnded, err)
+
+	_, err = NewScheduledPrice(1, money.Money{Amount: -1, Currency: "USD"}, now, nil, now)
+	assert.Equal(t, ErrNegativePrice, err)
+}
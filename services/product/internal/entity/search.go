@@ -0,0 +1,52 @@
+package entity
+
+import "github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+
+// SearchQuery filters a full-text product search; price bounds only match products priced in their currency
+type SearchQuery struct {
+	Text     string
+	Category string
+	MinPrice *money.Money
+	MaxPrice *money.Money
+	// Attributes need Category, attribute definitions are per category
+	Attributes []AttributeFilter
+	Limit      int
+	Offset     int
+	// IncludeUnpublished also matches products customers do not see, for staff
+	IncludeUnpublished bool
+}
+
+// ListQuery filters a product listing
+type ListQuery struct {
+	Category   string
+	Attributes []AttributeFilter
+	Limit      int
+	// IncludeUnpublished also lists products customers do not see, for staff
+	IncludeUnpublished bool
+}
+
+type SearchHit struct {
+	Product              Product
+	Score                float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+type CategoryFacet struct {
+	Category string
+	Count    int64
+}
+
+// PriceBucket counts matches priced in [Min, Max); a nil Max means no upper bound
+type PriceBucket struct {
+	Min   money.Money
+	Max   *money.Money
+	Count int64
+}
+
+type SearchResult struct {
+	Hits         []SearchHit
+	Total        int64
+	Categories   []CategoryFacet
+	PriceBuckets []PriceBucket
+}
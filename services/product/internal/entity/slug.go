@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidSlug           = errors.New("slug must be lowercase letters, digits and single hyphens")
+	ErrSlugTaken             = errors.New("slug is used by another product")
+	ErrSEOTitleTooLong       = errors.New("seo title is too long")
+	ErrSEODescriptionTooLong = errors.New("seo description is too long")
+)
+
+const (
+	maxSlugLength           = 80
+	maxSEOTitleLength       = 120
+	maxSEODescriptionLength = 320
+)
+
+// Slugify makes a URL slug out of the first of values that has letters or digits, e.g. "Blue Mug (XL)" gives
+// "blue-mug-xl". Letters outside ASCII are dropped, so a name without any falls back to the next value.
+func Slugify(values ...string) string {
+
+	for _, v := range values {
+		var b strings.Builder
+		hyphen := false
+		for _, r := range strings.ToLower(v) {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+				if hyphen && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				hyphen = false
+				b.WriteRune(r)
+			default:
+				hyphen = true
+			}
+		}
+		slug := b.String()
+		if len(slug) > maxSlugLength {
+			slug = strings.TrimRight(slug[:maxSlugLength], "-")
+		}
+		if slug != "" {
+			return slug
+		}
+	}
+	return "product"
+}
+
+// SuffixSlug appends -n to slug, shortening slug to keep the result within the length limit
+func SuffixSlug(slug string, n int64) string {
+
+	suffix := "-" + strconv.FormatInt(n, 10)
+	if len(slug)+len(suffix) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength-len(suffix)], "-")
+	}
+	return slug + suffix
+}
+
+// ValidateSlug accepts the slugs Slugify makes
+func ValidateSlug(slug string) error {
+
+	if slug == "" || len(slug) > maxSlugLength || Slugify(slug) != slug {
+		return ErrInvalidSlug
+	}
+	return nil
+}
+
+func validateSEO(title, description string) error {
+
+	if utf8.RuneCountInString(title) > maxSEOTitleLength {
+		return ErrSEOTitleTooLong
+	}
+	if utf8.RuneCountInString(description) > maxSEODescriptionLength {
+		return ErrSEODescriptionTooLong
+	}
+	return nil
+}
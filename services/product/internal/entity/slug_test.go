@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+
+	assert.Equal(t, "blue-mug-xl", Slugify("Blue Mug (XL)"))
+	assert.Equal(t, "tea-for-2", Slugify("  Tea -- for 2! "))
+	assert.Equal(t, "sku-9", Slugify("Чай", "SKU-9"))
+	assert.Equal(t, "product", Slugify("!!!"))
+
+	long := Slugify(strings.Repeat("ab ", 60))
+	assert.LessOrEqual(t, len(long), maxSlugLength)
+	assert.Nil(t, ValidateSlug(long))
+}
+
+func TestValidateSlug(t *testing.T) {
+
+	assert.Nil(t, ValidateSlug("blue-mug"))
+	assert.Equal(t, ErrInvalidSlug, ValidateSlug("Blue-Mug"))
+	assert.Equal(t, ErrInvalidSlug, ValidateSlug("blue--mug"))
+	assert.Equal(t, ErrInvalidSlug, ValidateSlug("-blue"))
+	assert.Equal(t, ErrInvalidSlug, ValidateSlug(""))
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// StockLevel is the quantity of a product on hand; products without a row have none. Reserved units
+// are held for orders and are no longer available, but stay on hand until they ship.
+type StockLevel struct {
+	ProductID int64     `db:"product_id" json:"product_id"`
+	OnHand    int64     `db:"on_hand" json:"on_hand"`
+	Reserved  int64     `db:"reserved" json:"reserved"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (s *StockLevel) Available() int64 {
+	return s.OnHand - s.Reserved
+}
+
+// StockMove is how a change took the quantity on hand of a product from Before to After
+type StockMove struct {
+	ProductID int64
+	Before    int64
+	After     int64
+}
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func (s *ProductServer) CreateAttributeDefinition(ctx context.Context, req *pb.CreateAttributeDefinitionRequest) (*pb.AttributeDefinition, error) {
+
+	d, err := s.ProductUseCase.CreateAttributeDefinition(ctx, usecase.AttributeDefinitionInput{
+		Category: req.Category,
+		Code:     req.Code,
+		Label:    req.Label,
+		Type:     entity.AttributeType(req.Type),
+		Unit:     req.Unit,
+		Options:  req.Options,
+		Required: req.Required,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toPBAttributeDefinition(d), nil
+}
+
+func (s *ProductServer) ListAttributeDefinitions(ctx context.Context, req *pb.ListAttributeDefinitionsRequest) (*pb.ListAttributeDefinitionsResponse, error) {
+
+	defs, err := s.ProductUseCase.ListAttributeDefinitions(ctx, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListAttributeDefinitionsResponse{}
+	for i := range defs {
+		resp.Attributes = append(resp.Attributes, toPBAttributeDefinition(&defs[i]))
+	}
+	return resp, nil
+}
+
+func toPBAttributeDefinition(d *entity.AttributeDefinition) *pb.AttributeDefinition {
+
+	return &pb.AttributeDefinition{
+		Id:       strconv.FormatInt(d.ID, 10),
+		Category: d.Category,
+		Code:     d.Code,
+		Label:    d.Label,
+		Type:     string(d.Type),
+		Unit:     d.Unit,
+		Options:  d.Options,
+		Required: d.Required,
+	}
+}
+
+// toPBAttributes converts stored attributes, which only hold strings, numbers and bools
+func toPBAttributes(a entity.Attributes) *structpb.Struct {
+
+	if len(a) == 0 {
+		return nil
+	}
+	st, _ := structpb.NewStruct(a)
+	return st
+}
+
+func fromPBAttributes(st *structpb.Struct) entity.Attributes {
+
+	if st == nil {
+		return nil
+	}
+	return st.AsMap()
+}
+
+func fromPBAttributeFilters(filters []*pb.AttributeFilter) []entity.AttributeFilter {
+
+	list := make([]entity.AttributeFilter, 0, len(filters))
+	for _, f := range filters {
+		af := entity.AttributeFilter{Code: f.Code, Min: f.Min, Max: f.Max}
+		if f.Equals != "" {
+			af.Equals = f.Equals
+		}
+		list = append(list, af)
+	}
+	return list
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"io"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/bulk"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/grpc"
+)
+
+func (s *ProductServer) ImportProducts(stream grpc.ClientStreamingServer[pb.ImportProductsRequest, pb.ImportProductsResponse]) error {
+
+	if _, err := requireStaff(stream.Context()); err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	src := &importStream{stream: stream, done: err == io.EOF}
+	if first != nil {
+		src.pending = first.Products
+	}
+
+	report, err := s.ProductUseCase.ImportProducts(stream.Context(), src, first.GetDryRun())
+	if err != nil {
+		return err
+	}
+
+	resp := &pb.ImportProductsResponse{
+		DryRun:  report.DryRun,
+		Created: int32(report.Created),
+		Updated: int32(report.Updated),
+		Failed:  int32(report.Failed),
+	}
+	for _, e := range report.Errors {
+		resp.Errors = append(resp.Errors, &pb.ImportRowError{Line: int32(e.Line), Sku: e.SKU, Message: e.Message})
+	}
+
+	return stream.SendAndClose(resp)
+}
+
+func (s *ProductServer) ExportProducts(req *pb.ExportProductsRequest, stream grpc.ServerStreamingServer[pb.ProductRecord]) error {
+
+	return s.ProductUseCase.ExportProducts(stream.Context(), func(p entity.Product) error {
+		return stream.Send(&pb.ProductRecord{
+			Sku:         p.SKU,
+			Name:        p.Name,
+			Description: p.Description,
+			Category:    p.Category,
+			Price:       toPBMoney(p.Price),
+			Attributes:  toPBAttributes(p.Attributes),
+		})
+	})
+}
+
+// importStream adapts the client stream to a bulk.Source, one record at a time
+type importStream struct {
+	stream  grpc.ClientStreamingServer[pb.ImportProductsRequest, pb.ImportProductsResponse]
+	pending []*pb.ProductRecord
+	line    int
+	done    bool
+}
+
+func (s *importStream) Next() (bulk.Record, error) {
+
+	for len(s.pending) == 0 {
+		if s.done {
+			return bulk.Record{}, io.EOF
+		}
+		req, err := s.stream.Recv()
+		if err == io.EOF {
+			s.done = true
+			continue
+		}
+		if err != nil {
+			return bulk.Record{}, err
+		}
+		s.pending = req.Products
+	}
+
+	in := s.pending[0]
+	s.pending = s.pending[1:]
+	s.line++
+
+	rec := bulk.Record{Line: s.line, SKU: in.Sku}
+	price, err := fromPBMoney(in.Price)
+	if err != nil {
+		rec.Err = err
+		return rec, nil
+	}
+	rec.Product, rec.Err = entity.NewProduct(0, in.Sku, in.Name, in.Description, in.Category, price)
+	if rec.Product != nil {
+		rec.Product.Attributes = fromPBAttributes(in.Attributes)
+	}
+
+	return rec, nil
+}
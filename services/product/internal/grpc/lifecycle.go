@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SetProductStatus publishes, schedules or archives a product, for staff; the change is recorded as theirs
+func (s *ProductServer) SetProductStatus(ctx context.Context, req *pb.SetProductStatusRequest) (*pb.GetProductResponse, error) {
+
+	staff, err := requireStaff(ctx)
+	if err != nil {
+		return nil, err
+	}
+	productID, _ := strconv.Atoi(req.ProductId)
+
+	input := usecase.SetStatusInput{Status: entity.ProductStatus(req.Status), ChangedBy: staff.UserID}
+	if req.PublishedAt != nil {
+		at := req.PublishedAt.AsTime()
+		input.PublishedAt = &at
+	}
+
+	p, err := s.ProductUseCase.SetProductStatus(ctx, int64(productID), input)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPBProduct(p, p.Price), nil
+}
+
+func (s *ProductServer) ListProductStatusChanges(ctx context.Context, req *pb.ListProductStatusChangesRequest) (*pb.ListProductStatusChangesResponse, error) {
+
+	if _, err := requireStaff(ctx); err != nil {
+		return nil, err
+	}
+	productID, _ := strconv.Atoi(req.ProductId)
+
+	changes, err := s.ProductUseCase.ListStatusChanges(ctx, int64(productID))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListProductStatusChangesResponse{}
+	for _, c := range changes {
+		pbc := &pb.ProductStatusChange{
+			Id:        strconv.Itoa(int(c.ID)),
+			From:      string(c.From),
+			To:        string(c.To),
+			ChangedBy: strconv.Itoa(int(c.ChangedBy)),
+			ChangedAt: timestamppb.New(c.ChangedAt),
+		}
+		if c.PublishedAt != nil {
+			pbc.PublishedAt = timestamppb.New(*c.PublishedAt)
+		}
+		resp.Changes = append(resp.Changes, pbc)
+	}
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/grpc"
+)
+
+var ErrMissingMediaMetadata = errors.New("first message must carry the media metadata")
+
+func (s *ProductServer) UploadProductMedia(stream grpc.ClientStreamingServer[pb.UploadProductMediaRequest, pb.ProductMedia]) error {
+
+	if _, err := requireStaff(stream.Context()); err != nil {
+		return err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	if meta == nil {
+		return ErrMissingMediaMetadata
+	}
+
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data = append(data, req.GetChunk()...)
+		if len(data) > usecase.MaxMediaSize {
+			return usecase.ErrMediaTooLarge
+		}
+	}
+
+	productID, _ := strconv.Atoi(meta.ProductId)
+
+	m, err := s.MediaUseCase.Upload(stream.Context(), usecase.UploadMediaInput{
+		ProductID:  int64(productID),
+		VariantSKU: meta.VariantSku,
+		AltText:    meta.AltText,
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(s.toPBMedia(m))
+}
+
+func (s *ProductServer) toPBMedia(m *entity.ProductMedia) *pb.ProductMedia {
+
+	out := &pb.ProductMedia{
+		Id:          strconv.Itoa(int(m.ID)),
+		ProductId:   strconv.Itoa(int(m.ProductID)),
+		VariantSku:  m.VariantSKU,
+		Position:    int32(m.Position),
+		AltText:     m.AltText,
+		ContentType: m.ContentType,
+		Width:       int32(m.Width),
+		Height:      int32(m.Height),
+		Url:         s.MediaUseCase.URL(m.OriginalKey),
+	}
+	for _, r := range m.Renditions {
+		out.Renditions = append(out.Renditions, &pb.MediaRendition{
+			Name:   r.Name,
+			Format: r.Format,
+			Width:  int32(r.Width),
+			Height: int32(r.Height),
+			Url:    s.MediaUseCase.URL(r.Key),
+		})
+	}
+	return out
+}
+
+func (s *ProductServer) toPBMediaList(list []entity.ProductMedia) []*pb.ProductMedia {
+
+	out := make([]*pb.ProductMedia, 0, len(list))
+	for i := range list {
+		out = append(out, s.toPBMedia(&list[i]))
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ProductServer) GetPrice(ctx context.Context, req *pb.GetPriceRequest) (*pb.GetPriceResponse, error) {
+
+	id, _ := strconv.Atoi(req.ProductId)
+
+	pc := fromPBPriceContext(req.PriceContext)
+	if req.At != nil {
+		pc.At = req.At.AsTime()
+	}
+
+	price, err := s.ProductUseCase.GetPrice(ctx, int64(id), pc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetPriceResponse{Price: toPBMoney(price)}, nil
+}
+
+func (s *ProductServer) CreatePriceList(ctx context.Context, req *pb.CreatePriceListRequest) (*pb.CreatePriceListResponse, error) {
+
+	input := usecase.PriceListInput{
+		Name:          req.Name,
+		Currency:      req.Currency,
+		Region:        req.Region,
+		CustomerGroup: req.CustomerGroup,
+		Priority:      int(req.Priority),
+	}
+	if req.StartsAt != nil {
+		input.StartsAt = req.StartsAt.AsTime()
+	}
+	if req.EndsAt != nil {
+		endsAt := req.EndsAt.AsTime()
+		input.EndsAt = &endsAt
+	}
+
+	id, err := s.ProductUseCase.CreatePriceList(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreatePriceListResponse{Id: strconv.Itoa(int(id))}, nil
+}
+
+func (s *ProductServer) SetPriceListPrice(ctx context.Context, req *pb.SetPriceListPriceRequest) (*pb.SetPriceListPriceResponse, error) {
+
+	priceListID, _ := strconv.Atoi(req.PriceListId)
+	productID, _ := strconv.Atoi(req.ProductId)
+
+	price, err := fromPBMoney(req.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ProductUseCase.SetPriceListPrice(ctx, int64(priceListID), int64(productID), price); err != nil {
+		return nil, err
+	}
+
+	return &pb.SetPriceListPriceResponse{}, nil
+}
+
+func (s *ProductServer) SchedulePrice(ctx context.Context, req *pb.SchedulePriceRequest) (*pb.ScheduledPrice, error) {
+
+	productID, _ := strconv.Atoi(req.ProductId)
+
+	price, err := fromPBMoney(req.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	input := usecase.SchedulePriceInput{Price: price}
+	if req.StartsAt != nil {
+		input.StartsAt = req.StartsAt.AsTime()
+	}
+	if req.EndsAt != nil {
+		endsAt := req.EndsAt.AsTime()
+		input.EndsAt = &endsAt
+	}
+
+	sp, err := s.ProductUseCase.SchedulePrice(ctx, int64(productID), input)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPBScheduledPrice(sp), nil
+}
+
+func (s *ProductServer) ListScheduledPrices(ctx context.Context, req *pb.ListScheduledPricesRequest) (*pb.ListScheduledPricesResponse, error) {
+
+	productID, _ := strconv.Atoi(req.ProductId)
+
+	list, err := s.ProductUseCase.ListScheduledPrices(ctx, int64(productID))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListScheduledPricesResponse{}
+	for i := range list {
+		resp.ScheduledPrices = append(resp.ScheduledPrices, toPBScheduledPrice(&list[i]))
+	}
+	return resp, nil
+}
+
+func (s *ProductServer) CancelScheduledPrice(ctx context.Context, req *pb.CancelScheduledPriceRequest) (*pb.CancelScheduledPriceResponse, error) {
+
+	id, _ := strconv.Atoi(req.Id)
+
+	if err := s.ProductUseCase.CancelScheduledPrice(ctx, int64(id)); err != nil {
+		return nil, err
+	}
+
+	return &pb.CancelScheduledPriceResponse{}, nil
+}
+
+func toPBScheduledPrice(sp *entity.ScheduledPrice) *pb.ScheduledPrice {
+
+	resp := &pb.ScheduledPrice{
+		Id:        strconv.FormatInt(sp.ID, 10),
+		ProductId: strconv.FormatInt(sp.ProductID, 10),
+		Price:     toPBMoney(sp.Price),
+		StartsAt:  timestamppb.New(sp.StartsAt),
+		Status:    string(sp.Status),
+	}
+	if sp.EndsAt != nil {
+		resp.EndsAt = timestamppb.New(*sp.EndsAt)
+	}
+	if sp.PreviousPrice != nil {
+		resp.PreviousPrice = toPBMoney(*sp.PreviousPrice)
+	}
+	return resp
+}
+
+func fromPBPriceContext(pc *pb.PriceContext) entity.PriceContext {
+
+	if pc == nil {
+		return entity.PriceContext{At: time.Now()}
+	}
+	return entity.PriceContext{
+		Currency:      pc.Currency,
+		Region:        pc.Region,
+		CustomerGroup: pc.CustomerGroup,
+		At:            time.Now(),
+	}
+}
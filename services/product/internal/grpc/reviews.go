@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ProductServer) SubmitReview(ctx context.Context, req *pb.SubmitReviewRequest) (*pb.Review, error) {
+
+	productID, _ := strconv.ParseInt(req.ProductId, 10, 64)
+	userID, _ := strconv.ParseInt(req.UserId, 10, 64)
+
+	rv, err := s.ReviewUseCase.SubmitReview(ctx, usecase.SubmitReviewInput{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    int(req.Rating),
+		Title:     req.Title,
+		Body:      req.Body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toPBReview(rv), nil
+}
+
+func (s *ProductServer) ListReviews(ctx context.Context, req *pb.ListReviewsRequest) (*pb.ListReviewsResponse, error) {
+
+	productID, _ := strconv.ParseInt(req.ProductId, 10, 64)
+	offset, _ := strconv.Atoi(req.PageToken)
+
+	p, err := s.ProductUseCase.GetByProductId(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrProductNotFound
+	}
+
+	list, more, err := s.ReviewUseCase.ListReviews(ctx, productID, req.Sort, int(req.PageSize), offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListReviewsResponse{Rating: toPBRating(p.Rating)}
+	for i := range list {
+		resp.Reviews = append(resp.Reviews, toPBReview(&list[i]))
+	}
+	// the offset of the next page travels as the page token
+	if more {
+		resp.NextPageToken = strconv.Itoa(offset + len(list))
+	}
+
+	return resp, nil
+}
+
+func (s *ProductServer) VoteReviewHelpful(ctx context.Context, req *pb.VoteReviewHelpfulRequest) (*pb.VoteReviewHelpfulResponse, error) {
+
+	reviewID, _ := strconv.ParseInt(req.ReviewId, 10, 64)
+	userID, _ := strconv.ParseInt(req.UserId, 10, 64)
+
+	if err := s.ReviewUseCase.VoteHelpful(ctx, reviewID, userID); err != nil {
+		return nil, err
+	}
+	return &pb.VoteReviewHelpfulResponse{}, nil
+}
+
+// RecordPurchase makes the user a verified purchaser of the products, which lets them review them. Only
+// the order service may tell, with a service token.
+func (s *ProductServer) RecordPurchase(ctx context.Context, req *pb.RecordPurchaseRequest) (*pb.RecordPurchaseResponse, error) {
+
+	if err := requireService(ctx); err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(req.UserId, 10, 64)
+	orderID, _ := strconv.ParseInt(req.OrderId, 10, 64)
+
+	for _, raw := range req.ProductIds {
+		productID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		if err := s.ReviewUseCase.RecordPurchase(ctx, userID, productID, orderID); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.RecordPurchaseResponse{}, nil
+}
+
+func toPBReview(rv *entity.Review) *pb.Review {
+
+	return &pb.Review{
+		Id:               strconv.FormatInt(rv.ID, 10),
+		ProductId:        strconv.FormatInt(rv.ProductID, 10),
+		UserId:           strconv.FormatInt(rv.UserID, 10),
+		Rating:           int32(rv.Rating),
+		Title:            rv.Title,
+		Body:             rv.Body,
+		Status:           string(rv.Status),
+		VerifiedPurchase: rv.VerifiedPurchase,
+		HelpfulCount:     int32(rv.HelpfulCount),
+		CreatedAt:        timestamppb.New(rv.CreatedAt),
+	}
+}
+
+func toPBRating(r entity.RatingSummary) *pb.RatingSummary {
+
+	out := &pb.RatingSummary{Average: r.Average, Count: int32(r.Count), Histogram: make([]int32, len(r.Histogram))}
+	for i, n := range r.Histogram {
+		out.Histogram[i] = int32(n)
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+)
+
+func (s *ProductServer) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+
+	offset, _ := strconv.Atoi(req.PageToken)
+
+	sq := entity.SearchQuery{
+		Text:               req.Query,
+		Category:           req.Category,
+		Attributes:         fromPBAttributeFilters(req.AttributeFilters),
+		Limit:              int(req.PageSize),
+		Offset:             offset,
+		IncludeUnpublished: includeUnpublished(ctx, req.IncludeUnpublished),
+	}
+	if req.MinPrice != nil {
+		min, err := fromPBMoney(req.MinPrice)
+		if err != nil {
+			return nil, err
+		}
+		sq.MinPrice = &min
+	}
+	if req.MaxPrice != nil {
+		max, err := fromPBMoney(req.MaxPrice)
+		if err != nil {
+			return nil, err
+		}
+		sq.MaxPrice = &max
+	}
+
+	result, err := s.ProductUseCase.SearchProducts(ctx, sq)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.SearchProductsResponse{Total: result.Total}
+
+	for _, hit := range result.Hits {
+		resp.Hits = append(resp.Hits, &pb.SearchHit{
+			Product:              toPBProduct(&hit.Product, hit.Product.Price),
+			Score:                hit.Score,
+			NameHighlight:        hit.NameHighlight,
+			DescriptionHighlight: hit.DescriptionHighlight,
+		})
+	}
+	for _, f := range result.Categories {
+		resp.Categories = append(resp.Categories, &pb.CategoryFacet{Category: f.Category, Count: f.Count})
+	}
+	for _, b := range result.PriceBuckets {
+		bucket := &pb.PriceBucketFacet{Min: toPBMoney(b.Min), Count: b.Count}
+		if b.Max != nil {
+			bucket.Max = toPBMoney(*b.Max)
+		}
+		resp.PriceBuckets = append(resp.PriceBuckets, bucket)
+	}
+
+	// the offset of the next page travels as the page token
+	if next := offset + len(result.Hits); len(result.Hits) > 0 && int64(next) < result.Total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+
+	return resp, nil
+}
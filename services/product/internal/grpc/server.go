@@ -2,31 +2,67 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
 	"strconv"
 
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
 	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
 	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ProductServer struct {
 	pb.UnimplementedProductServiceServer
 	ProductUseCase usecase.ProductUseCase
+	MediaUseCase   usecase.MediaUseCase
+	ReviewUseCase  usecase.ReviewUseCase
 	// cfg         config.Config
+	grpcServer *grpc.Server
 }
 
-func NewProductServer(uc usecase.ProductUseCase) *ProductServer {
-	return &ProductServer{
+// NewProductServer verifies the access tokens callers send with jwtSecret, the secret of the auth service
+func NewProductServer(uc usecase.ProductUseCase, media usecase.MediaUseCase, reviews usecase.ReviewUseCase, jwtSecret string) *ProductServer {
+
+	s := &ProductServer{
 		ProductUseCase: uc,
+		MediaUseCase:   media,
+		ReviewUseCase:  reviews,
+		grpcServer: grpc.NewServer(
+			grpc.UnaryInterceptor(authInterceptor(jwtSecret)),
+			grpc.StreamInterceptor(authStreamInterceptor(jwtSecret)),
+		),
 	}
+
+	pb.RegisterProductServiceServer(s.grpcServer, s)
+	reflection.Register(s.grpcServer)
+
+	return s
 }
 
 func (s *ProductServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
 
-	id, err := s.ProductUseCase.CreateProduct(ctx, req.Name, req.Price)
+	price, err := fromPBMoney(req.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.ProductUseCase.CreateProduct(ctx, usecase.CreateProductInput{
+		SKU:            req.Sku,
+		Name:           req.Name,
+		Description:    req.Description,
+		Category:       req.Category,
+		Price:          price,
+		Attributes:     fromPBAttributes(req.Attributes),
+		Slug:           req.Slug,
+		SEOTitle:       req.SeoTitle,
+		SEODescription: req.SeoDescription,
+		Bundle:         fromPBBundle(req.Bundle),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -40,49 +76,209 @@ func (s *ProductServer) GetProduct(ctx context.Context, req *pb.GetProductReques
 
 	id, _ := strconv.Atoi(req.Id)
 
-	p, err := s.ProductUseCase.GetByProductId(ctx, int64(id))
-	if err != nil || p == nil {
+	p, err := s.ProductUseCase.GetProduct(ctx, int64(id), includeUnpublished(ctx, req.IncludeUnpublished))
+	if err != nil {
 		return nil, err
 	}
 
-	return &pb.GetProductResponse{
-		Id:    req.Id,
-		Name:  p.Name,
-		Price: p.Price,
-	}, nil
+	return s.getProductResponse(ctx, p, req.PriceContext)
+}
+
+func (s *ProductServer) GetProductBySlug(ctx context.Context, req *pb.GetProductBySlugRequest) (*pb.GetProductResponse, error) {
+
+	p, err := s.ProductUseCase.GetProductBySlug(ctx, req.Slug, includeUnpublished(ctx, req.IncludeUnpublished))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getProductResponse(ctx, p, req.PriceContext)
+}
+
+func (s *ProductServer) getProductResponse(ctx context.Context, p *entity.Product, pc *pb.PriceContext) (*pb.GetProductResponse, error) {
+
+	price, err := s.ProductUseCase.ResolvePrice(ctx, p, fromPBPriceContext(pc))
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.MediaUseCase.ListMedia(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toPBProduct(p, price)
+	resp.Media = s.toPBMediaList(media)
+
+	if p.Kind == entity.ProductBundle {
+		b, err := s.ProductUseCase.GetBundle(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		resp.Bundle = toPBBundle(b)
+	}
+
+	return resp, nil
 }
 
 func (s *ProductServer) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
 
-	list, err := s.ProductUseCase.ListProducts(ctx, 10)
+	list, err := s.ProductUseCase.ListProducts(ctx, entity.ListQuery{
+		Category:           req.Category,
+		Attributes:         fromPBAttributeFilters(req.AttributeFilters),
+		Limit:              10,
+		IncludeUnpublished: includeUnpublished(ctx, req.IncludeUnpublished),
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	ids := make([]int64, len(list))
+	for i, p := range list {
+		ids[i] = p.ID
+	}
+	media, err := s.MediaUseCase.ListMediaByProducts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := fromPBPriceContext(req.PriceContext)
 	var listProductResponse = &pb.ListProductsResponse{}
 
 	for _, p := range list {
 
-		gpr := pb.GetProductResponse{Id: strconv.Itoa(int(p.ID)), Name: p.Name, Price: p.Price}
+		price, err := s.ProductUseCase.ResolvePrice(ctx, &p, pc)
+		if errors.Is(err, entity.ErrPriceNotAvailable) {
+			// not sold in the caller's currency
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		gpr := toPBProduct(&p, price)
+		gpr.Media = s.toPBMediaList(media[p.ID])
 
-		listProductResponse.Products = append(listProductResponse.Products, &gpr)
+		listProductResponse.Products = append(listProductResponse.Products, gpr)
 
 	}
 
 	return listProductResponse, nil
 }
 
+func (s *ProductServer) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+
+	resp := &pb.BatchGetProductsResponse{}
+
+	ids := make([]int64, 0, len(req.Ids))
+	for _, raw := range req.Ids {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			resp.MissingIds = append(resp.MissingIds, raw)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	list, missing, err := s.ProductUseCase.BatchGetProducts(ctx, ids, includeUnpublished(ctx, req.IncludeUnpublished))
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range missing {
+		resp.MissingIds = append(resp.MissingIds, strconv.FormatInt(id, 10))
+	}
+
+	found := make([]int64, len(list))
+	for i, p := range list {
+		found[i] = p.ID
+	}
+	media, err := s.MediaUseCase.ListMediaByProducts(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := fromPBPriceContext(req.PriceContext)
+	for _, p := range list {
+
+		price, err := s.ProductUseCase.ResolvePrice(ctx, &p, pc)
+		if errors.Is(err, entity.ErrPriceNotAvailable) {
+			resp.MissingIds = append(resp.MissingIds, strconv.FormatInt(p.ID, 10))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		gpr := toPBProduct(&p, price)
+		gpr.Media = s.toPBMediaList(media[p.ID])
+		resp.Products = append(resp.Products, gpr)
+	}
+
+	return resp, nil
+}
+
 func (s *ProductServer) StartGRPCServer(port string) error {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	log.Printf("Product gRPC server running on :%s", port)
+	return s.grpcServer.Serve(lis)
+}
 
-	pb.RegisterProductServiceServer(grpcServer, s)
-	reflection.Register(grpcServer)
+// Stop waits for in-flight calls to finish, cancelling them once ctx is done
+func (s *ProductServer) Stop(ctx context.Context) {
 
-	log.Printf("Product gRPC server running on :%s", port)
-	return grpcServer.Serve(lis)
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
+func toPBProduct(p *entity.Product, price money.Money) *pb.GetProductResponse {
+
+	resp := &pb.GetProductResponse{
+		Id:             strconv.Itoa(int(p.ID)),
+		Sku:            p.SKU,
+		Name:           p.Name,
+		Description:    p.Description,
+		Category:       p.Category,
+		Kind:           string(p.Kind),
+		Price:          toPBMoney(price),
+		Rating:         toPBRating(p.Rating),
+		Attributes:     toPBAttributes(p.Attributes),
+		Slug:           p.Slug,
+		SeoTitle:       p.SEOTitle,
+		SeoDescription: p.SEODescription,
+		Status:         string(p.Status),
+	}
+	if p.PublishedAt != nil {
+		resp.PublishedAt = timestamppb.New(*p.PublishedAt)
+	}
+	// the compare-at price goes with the base price, not with list or converted prices
+	if p.CompareAtPrice != nil && price == p.Price {
+		resp.CompareAtPrice = toPBMoney(*p.CompareAtPrice)
+	}
+	return resp
+}
+
+func toPBMoney(m money.Money) *pb.Money {
+
+	units, nanos := m.UnitsNanos()
+	return &pb.Money{CurrencyCode: m.Currency, Units: units, Nanos: nanos}
+}
+
+func fromPBMoney(m *pb.Money) (money.Money, error) {
+
+	if m == nil {
+		return money.Money{}, money.ErrInvalidAmount
+	}
+	return money.FromUnitsNanos(m.CurrencyCode, m.Units, m.Nanos)
 }
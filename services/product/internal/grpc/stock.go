@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ProductServer) GetAvailability(ctx context.Context, req *pb.GetAvailabilityRequest) (*pb.GetAvailabilityResponse, error) {
+
+	id, _ := strconv.Atoi(req.ProductId)
+
+	available, err := s.ProductUseCase.GetAvailability(ctx, int64(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAvailabilityResponse{Available: available}, nil
+}
+
+// ReserveStock answers FAILED_PRECONDITION when stock is short, so callers can tell it from a failure worth retrying
+func (s *ProductServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+
+	err := s.ProductUseCase.ReserveStock(ctx, req.Reference, fromPBStockItems(req.Items))
+	if errors.Is(err, entity.ErrInsufficientStock) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReserveStockResponse{}, nil
+}
+
+func (s *ProductServer) ReleaseStock(ctx context.Context, req *pb.ReleaseStockRequest) (*pb.ReleaseStockResponse, error) {
+
+	var err error
+	if len(req.Items) == 0 {
+		err = s.ProductUseCase.ReleaseStock(ctx, req.Reference)
+	} else {
+		err = s.ProductUseCase.ReleaseStockItems(ctx, req.Reference, req.ReleaseId, fromPBStockItems(req.Items))
+	}
+	if errors.Is(err, entity.ErrReleaseIdIsRequired) || errors.Is(err, entity.ErrInvalidReservationItem) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReleaseStockResponse{}, nil
+}
+
+func (s *ProductServer) CommitStock(ctx context.Context, req *pb.CommitStockRequest) (*pb.CommitStockResponse, error) {
+
+	if err := s.ProductUseCase.CommitStock(ctx, req.Reference); err != nil {
+		return nil, err
+	}
+	return &pb.CommitStockResponse{}, nil
+}
+
+func (s *ProductServer) RestockItems(ctx context.Context, req *pb.RestockItemsRequest) (*pb.RestockItemsResponse, error) {
+
+	err := s.ProductUseCase.RestockItems(ctx, req.Reference, fromPBStockItems(req.Items))
+	if errors.Is(err, entity.ErrReferenceIsRequired) || errors.Is(err, entity.ErrInvalidReservationItem) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RestockItemsResponse{}, nil
+}
+
+func fromPBStockItems(list []*pb.StockItem) []entity.StockItem {
+
+	items := make([]entity.StockItem, len(list))
+	for i, it := range list {
+		id, _ := strconv.Atoi(it.ProductId)
+		items[i] = entity.StockItem{ProductID: int64(id), Quantity: it.Quantity}
+	}
+	return items
+}
+
+func fromPBBundle(b *pb.Bundle) *usecase.BundleInput {
+
+	if b == nil {
+		return nil
+	}
+	input := &usecase.BundleInput{
+		Pricing:    entity.BundlePricing(b.Pricing),
+		PercentOff: b.PercentOff,
+		Components: make([]entity.BundleComponent, len(b.Components)),
+	}
+	for i, c := range b.Components {
+		input.Components[i] = entity.BundleComponent{SKU: c.Sku, Quantity: c.Quantity}
+	}
+	return input
+}
+
+func toPBBundle(b *entity.Bundle) *pb.Bundle {
+
+	resp := &pb.Bundle{Pricing: string(b.Pricing), PercentOff: b.PercentOff}
+	for _, c := range b.Components {
+		resp.Components = append(resp.Components, &pb.BundleComponent{
+			Sku:       c.SKU,
+			Quantity:  c.Quantity,
+			ProductId: strconv.Itoa(int(c.ProductID)),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,201 @@
+package producer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"github.com/raulsilva-tech/e-commerce/services/product/pb/events"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	ProductsTopic = "products"
+	// eventSchema names the message in the value so consumers can pick a decoder
+	eventSchema = "product.events.v1.ProductEvent"
+)
+
+// Publisher sends product events; the usecase layer depends on this rather than on Kafka
+type Publisher interface {
+	Publish(ctx context.Context, ev *events.ProductEvent) error
+	// PublishBatch sends several events at once, e.g. those of an import batch
+	PublishBatch(ctx context.Context, evs []*events.ProductEvent) error
+}
+
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// NewProducer hashes the key to pick the partition, so events of a product keep their order. Writes are
+// asynchronous: a request does not wait for the batch to flush nor for a broker that is down, and a failed
+// delivery is logged once the writer gives up on it. Close flushes what is still buffered.
+func NewProducer(broker string) *kafka.Writer {
+
+	return &kafka.Writer{
+		Addr:         kafka.TCP(broker),
+		Topic:        ProductsTopic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 10 * time.Millisecond,
+		Async:        true,
+		Completion: func(msgs []kafka.Message, err error) {
+			if err != nil {
+				log.Printf("warning: publish %d product events: %v", len(msgs), err)
+			}
+		},
+	}
+}
+
+type KafkaPublisher struct {
+	writer messageWriter
+}
+
+func NewKafkaPublisher(w messageWriter) *KafkaPublisher {
+	return &KafkaPublisher{writer: w}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, ev *events.ProductEvent) error {
+	return p.PublishBatch(ctx, []*events.ProductEvent{ev})
+}
+
+// PublishBatch hands all the events to the writer in one call
+func (p *KafkaPublisher) PublishBatch(ctx context.Context, evs []*events.ProductEvent) error {
+
+	if len(evs) == 0 {
+		return nil
+	}
+	msgs := make([]kafka.Message, len(evs))
+	for i, ev := range evs {
+		data, err := proto.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		msgs[i] = kafka.Message{
+			Key:   []byte(strconv.FormatInt(ev.ProductId, 10)),
+			Value: data,
+			Headers: []kafka.Header{
+				{Key: "event-type", Value: []byte(EventType(ev))},
+				{Key: "content-type", Value: []byte("application/x-protobuf")},
+				{Key: "schema", Value: []byte(eventSchema)},
+			},
+		}
+	}
+
+	return p.writer.WriteMessages(ctx, msgs...)
+}
+
+// EventType is the name of the payload, e.g. "ProductCreated"
+func EventType(ev *events.ProductEvent) string {
+
+	switch ev.Payload.(type) {
+	case *events.ProductEvent_ProductCreated:
+		return "ProductCreated"
+	case *events.ProductEvent_ProductUpdated:
+		return "ProductUpdated"
+	case *events.ProductEvent_PriceChanged:
+		return "PriceChanged"
+	case *events.ProductEvent_ProductDeleted:
+		return "ProductDeleted"
+	case *events.ProductEvent_StockLevelChanged:
+		return "StockLevelChanged"
+	}
+	return "Unknown"
+}
+
+func ProductCreated(p *entity.Product) *events.ProductEvent {
+
+	ev := newEvent(p.ID)
+	ev.Payload = &events.ProductEvent_ProductCreated{
+		ProductCreated: &events.ProductCreated{Product: snapshot(p)},
+	}
+	return ev
+}
+
+func ProductUpdated(p *entity.Product, changedFields []string) *events.ProductEvent {
+
+	ev := newEvent(p.ID)
+	ev.Payload = &events.ProductEvent_ProductUpdated{
+		ProductUpdated: &events.ProductUpdated{Product: snapshot(p), ChangedFields: changedFields},
+	}
+	return ev
+}
+
+// PriceChanged takes a nil oldPrice when the previous price is unknown; priceListID is 0 for the base price
+func PriceChanged(productID, priceListID int64, oldPrice *money.Money, newPrice money.Money) *events.ProductEvent {
+
+	pc := &events.PriceChanged{PriceListId: priceListID, NewPrice: toPBMoney(newPrice)}
+	if oldPrice != nil {
+		pc.OldPrice = toPBMoney(*oldPrice)
+	}
+	ev := newEvent(productID)
+	ev.Payload = &events.ProductEvent_PriceChanged{PriceChanged: pc}
+	return ev
+}
+
+func ProductDeleted(productID int64, sku string) *events.ProductEvent {
+
+	ev := newEvent(productID)
+	ev.Payload = &events.ProductEvent_ProductDeleted{
+		ProductDeleted: &events.ProductDeleted{Sku: sku},
+	}
+	return ev
+}
+
+func StockLevelChanged(productID int64, previous, onHand int64) *events.ProductEvent {
+
+	ev := newEvent(productID)
+	ev.Payload = &events.ProductEvent_StockLevelChanged{
+		StockLevelChanged: &events.StockLevelChanged{PreviousOnHand: previous, OnHand: onHand},
+	}
+	return ev
+}
+
+func newEvent(productID int64) *events.ProductEvent {
+
+	return &events.ProductEvent{
+		EventId:    newEventID(),
+		ProductId:  productID,
+		OccurredAt: timestamppb.Now(),
+	}
+}
+
+func newEventID() string {
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func snapshot(p *entity.Product) *events.ProductSnapshot {
+
+	// validated attributes only hold strings, numbers and bools, which always convert
+	attributes, _ := structpb.NewStruct(p.Attributes)
+
+	snap := &events.ProductSnapshot{
+		Sku:         p.SKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Category:    p.Category,
+		Price:       toPBMoney(p.Price),
+		Attributes:  attributes,
+		Status:      string(p.Status),
+	}
+	if p.PublishedAt != nil {
+		snap.PublishedAt = timestamppb.New(*p.PublishedAt)
+	}
+	return snap
+}
+
+func toPBMoney(m money.Money) *pb.Money {
+
+	units, nanos := m.UnitsNanos()
+	return &pb.Money{CurrencyCode: m.Currency, Units: units, Nanos: nanos}
+}
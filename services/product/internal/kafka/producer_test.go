@@ -0,0 +1,82 @@
+package producer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pb/events"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeWriter struct {
+	msgs  []kafka.Message
+	calls int
+}
+
+func (f *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.msgs = append(f.msgs, msgs...)
+	f.calls++
+	return nil
+}
+
+func header(msg kafka.Message, key string) string {
+
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestPublishKeysByProduct(t *testing.T) {
+
+	w := &fakeWriter{}
+	p, _ := entity.NewProduct(42, "A-1", "Mug", "", "kitchen", money.Money{Amount: 1250, Currency: "USD"})
+
+	err := NewKafkaPublisher(w).Publish(context.Background(), ProductCreated(p))
+
+	assert.Nil(t, err)
+	assert.Len(t, w.msgs, 1)
+	assert.Equal(t, "42", string(w.msgs[0].Key))
+	assert.Equal(t, "ProductCreated", header(w.msgs[0], "event-type"))
+
+	var ev events.ProductEvent
+	assert.Nil(t, proto.Unmarshal(w.msgs[0].Value, &ev))
+	assert.Equal(t, int64(42), ev.ProductId)
+	assert.NotEmpty(t, ev.EventId)
+	assert.Equal(t, "A-1", ev.GetProductCreated().Product.Sku)
+	assert.Equal(t, int64(12), ev.GetProductCreated().Product.Price.Units)
+}
+
+func TestPublishBatchWritesOnce(t *testing.T) {
+
+	w := &fakeWriter{}
+	a, _ := entity.NewProduct(1, "A-1", "Mug", "", "kitchen", money.Money{Amount: 1250, Currency: "USD"})
+	b, _ := entity.NewProduct(2, "B-1", "Cup", "", "kitchen", money.Money{Amount: 800, Currency: "USD"})
+
+	err := NewKafkaPublisher(w).PublishBatch(context.Background(), []*events.ProductEvent{ProductCreated(a), ProductUpdated(b, nil)})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, w.calls)
+	assert.Len(t, w.msgs, 2)
+	assert.Equal(t, "2", string(w.msgs[1].Key))
+	assert.Equal(t, "ProductUpdated", header(w.msgs[1], "event-type"))
+
+	assert.Nil(t, NewKafkaPublisher(w).PublishBatch(context.Background(), nil))
+	assert.Equal(t, 1, w.calls)
+}
+
+func TestPriceChangedWithoutOldPrice(t *testing.T) {
+
+	ev := PriceChanged(1, 7, nil, money.Money{Amount: 500, Currency: "EUR"})
+
+	assert.Equal(t, "PriceChanged", EventType(ev))
+	assert.Nil(t, ev.GetPriceChanged().OldPrice)
+	assert.Equal(t, int64(7), ev.GetPriceChanged().PriceListId)
+	assert.Equal(t, "EUR", ev.GetPriceChanged().NewPrice.CurrencyCode)
+}
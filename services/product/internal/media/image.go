@@ -0,0 +1,157 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type, use jpeg, png, gif or webp")
+	ErrImageTooLarge    = errors.New("image dimensions are too large")
+)
+
+// maxPixels guards against decompression bombs: a small file declaring huge dimensions
+const maxPixels = 50_000_000
+
+// Size is a bounding box a rendition is scaled down to fit in
+type Size struct {
+	Name     string
+	MaxWidth int
+}
+
+// Sizes are the renditions generated for every upload, each one in the original format and as WebP
+var Sizes = []Size{
+	{Name: "thumb", MaxWidth: 200},
+	{Name: "medium", MaxWidth: 800},
+}
+
+// Original describes the uploaded file
+type Original struct {
+	ContentType string
+	Format      string
+	Width       int
+	Height      int
+}
+
+// Rendition is a generated image ready to be stored
+type Rendition struct {
+	Name        string
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Inspect validates the upload and returns its type and dimensions without decoding the pixels
+func Inspect(data []byte) (Original, error) {
+
+	contentType := http.DetectContentType(data)
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Original{}, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return Original{}, ErrImageTooLarge
+	}
+
+	return Original{ContentType: contentType, Format: format, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// Generate builds the thumbnails in the original format plus WebP versions of the thumbnails and of
+// the full size image. Images are never scaled up.
+func Generate(data []byte, original Original) ([]Rendition, error) {
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	full, err := encode(src, "webp")
+	if err != nil {
+		return nil, err
+	}
+	renditions := []Rendition{newRendition("original", "webp", src, full)}
+
+	for _, size := range Sizes {
+		scaled := resize(src, size.MaxWidth)
+
+		for _, format := range []string{thumbnailFormat(original.Format), "webp"} {
+			data, err := encode(scaled, format)
+			if err != nil {
+				return nil, err
+			}
+			renditions = append(renditions, newRendition(size.Name, format, scaled, data))
+		}
+	}
+
+	return renditions, nil
+}
+
+// thumbnailFormat keeps png for images that may carry transparency and uses jpeg for the rest
+func thumbnailFormat(format string) string {
+
+	switch format {
+	case "png", "gif", "webp":
+		return "png"
+	}
+	return "jpeg"
+}
+
+func resize(src image.Image, maxWidth int) image.Image {
+
+	b := src.Bounds()
+	if b.Dx() <= maxWidth {
+		return src
+	}
+
+	height := b.Dy() * maxWidth / b.Dx()
+	if height == 0 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, maxWidth, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, format string) ([]byte, error) {
+
+	var buf bytes.Buffer
+	var err error
+
+	switch format {
+	case "webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+
+	return buf.Bytes(), err
+}
+
+func newRendition(name, format string, img image.Image, data []byte) Rendition {
+
+	b := img.Bounds()
+	return Rendition{
+		Name:        name,
+		Format:      format,
+		ContentType: "image/" + format,
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+		Data:        data,
+	}
+}
@@ -0,0 +1,48 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+
+	img := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+	for x := 0; x < 1000; x++ {
+		img.Set(x, x/2, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, img))
+
+	original, err := Inspect(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "png", original.Format)
+	assert.Equal(t, 1000, original.Width)
+
+	renditions, err := Generate(buf.Bytes(), original)
+	assert.Nil(t, err)
+	assert.Len(t, renditions, 5)
+
+	thumb := renditions[1]
+	assert.Equal(t, "thumb", thumb.Name)
+	assert.Equal(t, "png", thumb.Format)
+	assert.Equal(t, 200, thumb.Width)
+	assert.Equal(t, 100, thumb.Height)
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(renditions[2].Data))
+	assert.Nil(t, err)
+	assert.Equal(t, "webp", format)
+	assert.Equal(t, 200, cfg.Width)
+}
+
+func TestInspectWhenNotAnImage(t *testing.T) {
+
+	_, err := Inspect([]byte("not an image"))
+
+	assert.Equal(t, ErrUnsupportedImage, err)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+type AttributeRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttributeRepository(db *sqlx.DB) *AttributeRepository {
+	return &AttributeRepository{db: db}
+}
+
+func (r *AttributeRepository) Create(ctx context.Context, d *entity.AttributeDefinition) (int64, error) {
+
+	err := r.db.QueryRowContext(ctx, `INSERT INTO attribute_definitions (category, code, label, type, unit, options, required)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		d.Category, d.Code, d.Label, d.Type, d.Unit, d.Options, d.Required).Scan(&d.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, entity.ErrAttributeAlreadyExists
+		}
+		return 0, err
+	}
+
+	return d.ID, nil
+}
+
+// ListByCategory returns the definitions of a category ordered by code
+func (r *AttributeRepository) ListByCategory(ctx context.Context, category string) ([]entity.AttributeDefinition, error) {
+
+	var defs []entity.AttributeDefinition
+	err := r.db.SelectContext(ctx, &defs, `SELECT id, category, code, label, type, unit, options, required
+		FROM attribute_definitions WHERE category = $1 ORDER BY code`, category)
+	if err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
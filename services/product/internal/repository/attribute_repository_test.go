@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+func migrateAttributeDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE attribute_definitions (
+    id integer PRIMARY KEY,
+    category VARCHAR(255) NOT NULL,
+    code VARCHAR(255) NOT NULL,
+    label VARCHAR(255) NOT NULL DEFAULT '',
+    type VARCHAR(16) NOT NULL,
+    unit VARCHAR(32) NOT NULL DEFAULT '',
+    options TEXT NOT NULL DEFAULT '[]',
+    required BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE (category, code)
+);`)
+
+	return db, err
+}
+
+type AttributeRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestAttributeRepositorySuite(t *testing.T) {
+	suite.Run(t, new(AttributeRepositoryTestSuite))
+}
+
+func (suite *AttributeRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *AttributeRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migrateAttributeDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *AttributeRepositoryTestSuite) TestCreateAndListByCategory() {
+
+	ctx := context.Background()
+	repo := NewAttributeRepository(suite.DB)
+
+	size, _ := entity.NewAttributeDefinition("tvs", "screen_size", "Screen size", entity.AttributeNumber, "in", nil, true)
+	panel, _ := entity.NewAttributeDefinition("tvs", "panel", "Panel", entity.AttributeEnum, "", []string{"lcd", "oled"}, false)
+	pages, _ := entity.NewAttributeDefinition("books", "pages", "Pages", entity.AttributeNumber, "", nil, false)
+
+	for _, d := range []*entity.AttributeDefinition{size, panel, pages} {
+		id, err := repo.Create(ctx, d)
+		suite.Nil(err)
+		suite.NotEmpty(id)
+	}
+
+	defs, err := repo.ListByCategory(ctx, "tvs")
+	suite.Nil(err)
+	suite.Len(defs, 2)
+	suite.Equal("panel", defs[0].Code)
+	suite.Equal(entity.AttributeValues{"lcd", "oled"}, defs[0].Options)
+	suite.Equal("screen_size", defs[1].Code)
+	suite.Equal("in", defs[1].Unit)
+	suite.True(defs[1].Required)
+}
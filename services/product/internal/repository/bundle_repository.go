@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+type BundleRepository struct {
+	db *sqlx.DB
+}
+
+func NewBundleRepository(db *sqlx.DB) *BundleRepository {
+	return &BundleRepository{db: db}
+}
+
+// Create inserts the bundle product and its composition together; component ids must be set
+func (r *BundleRepository) Create(ctx context.Context, p *entity.Product, b *entity.Bundle) (int64, error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	p.Kind = entity.ProductBundle
+	if _, err := insertProduct(ctx, tx, p); err != nil {
+		return 0, err
+	}
+	b.ProductID = p.ID
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO bundles (product_id, pricing, percent_off) VALUES ($1, $2, $3)",
+		b.ProductID, b.Pricing, b.PercentOff); err != nil {
+		return 0, err
+	}
+	if err := insertComponents(ctx, tx, b); err != nil {
+		return 0, err
+	}
+
+	return p.ID, tx.Commit()
+}
+
+// Replace changes the pricing and the components of an existing bundle
+func (r *BundleRepository) Replace(ctx context.Context, b *entity.Bundle) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE bundles SET pricing = $1, percent_off = $2 WHERE product_id = $3",
+		b.Pricing, b.PercentOff, b.ProductID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return entity.ErrNotABundle
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bundle_components WHERE bundle_id = $1", b.ProductID); err != nil {
+		return err
+	}
+	if err := insertComponents(ctx, tx, b); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertComponents(ctx context.Context, tx *sqlx.Tx, b *entity.Bundle) error {
+
+	for i, c := range b.Components {
+		_, err := tx.ExecContext(ctx, "INSERT INTO bundle_components (bundle_id, component_id, quantity, position) VALUES ($1, $2, $3, $4)",
+			b.ProductID, c.ProductID, c.Quantity, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns nil when the product is not a bundle
+func (r *BundleRepository) Get(ctx context.Context, productID int64) (*entity.Bundle, error) {
+
+	b := entity.Bundle{ProductID: productID}
+	err := r.db.QueryRowContext(ctx, "SELECT pricing, percent_off FROM bundles WHERE product_id = $1", productID).Scan(&b.Pricing, &b.PercentOff)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT c.component_id, p.sku, c.quantity
+		FROM bundle_components c JOIN products p ON p.id = c.component_id
+		WHERE c.bundle_id = $1 ORDER BY c.position`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c entity.BundleComponent
+		if err := rows.Scan(&c.ProductID, &c.SKU, &c.Quantity); err != nil {
+			return nil, err
+		}
+		b.Components = append(b.Components, c)
+	}
+	return &b, rows.Err()
+}
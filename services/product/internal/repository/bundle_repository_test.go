@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/suite"
+)
+
+func migrateBundleDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE products (
+    id integer PRIMARY KEY,
+    sku VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(255) NOT NULL DEFAULT '',
+    kind VARCHAR(16) NOT NULL DEFAULT 'simple',
+    price NUMERIC,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    attributes TEXT NOT NULL DEFAULT '{}',
+    slug TEXT UNIQUE,
+    seo_title TEXT NOT NULL DEFAULT '',
+    seo_description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'draft',
+    published_at DATETIME
+);
+CREATE TABLE product_slugs (
+    slug TEXT PRIMARY KEY,
+    product_id integer NOT NULL
+);
+CREATE TABLE bundles (
+    product_id integer PRIMARY KEY,
+    pricing TEXT NOT NULL,
+    percent_off integer NOT NULL DEFAULT 0
+);
+CREATE TABLE bundle_components (
+    bundle_id integer NOT NULL,
+    component_id integer NOT NULL,
+    quantity integer NOT NULL,
+    position integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (bundle_id, component_id)
+);
+INSERT INTO products (id, sku, name, price) VALUES (1, 'MUG', 'Mug', 10), (2, 'TEA', 'Tea', 5);`)
+
+	return db, err
+}
+
+type BundleRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestBundleRepositorySuite(t *testing.T) {
+	suite.Run(t, new(BundleRepositoryTestSuite))
+}
+
+func (suite *BundleRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *BundleRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migrateBundleDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *BundleRepositoryTestSuite) TestCreateGetAndReplace() {
+
+	ctx := context.Background()
+	repo := NewBundleRepository(suite.DB)
+
+	p, err := entity.NewProduct(0, "GIFT", "Gift set", "", "", money.Money{Amount: 1800, Currency: "USD"})
+	suite.Nil(err)
+	b, err := entity.NewBundle(0, entity.BundleFixed, 0, []entity.BundleComponent{
+		{ProductID: 2, SKU: "TEA", Quantity: 3},
+		{ProductID: 1, SKU: "MUG", Quantity: 1},
+	})
+	suite.Nil(err)
+
+	id, err := repo.Create(ctx, p, b)
+	suite.Nil(err)
+	suite.Equal(entity.ProductBundle, p.Kind)
+
+	var kind string
+	suite.Nil(suite.DB.QueryRow("SELECT kind FROM products WHERE id = $1", id).Scan(&kind))
+	suite.Equal("bundle", kind)
+
+	got, err := repo.Get(ctx, id)
+	suite.Nil(err)
+	suite.Equal(b, got)
+
+	b.Pricing, b.PercentOff = entity.BundlePercentOff, 10
+	b.Components = b.Components[1:]
+	suite.Nil(repo.Replace(ctx, b))
+
+	got, err = repo.Get(ctx, id)
+	suite.Nil(err)
+	suite.Equal(entity.BundlePercentOff, got.Pricing)
+	suite.Equal(int64(10), got.PercentOff)
+	suite.Equal([]entity.BundleComponent{{ProductID: 1, SKU: "MUG", Quantity: 1}}, got.Components)
+}
+
+func (suite *BundleRepositoryTestSuite) TestNotABundle() {
+
+	ctx := context.Background()
+	repo := NewBundleRepository(suite.DB)
+
+	got, err := repo.Get(ctx, 1)
+	suite.Nil(err)
+	suite.Nil(got)
+
+	b := &entity.Bundle{ProductID: 1, Pricing: entity.BundleFixed, Components: []entity.BundleComponent{{ProductID: 2, Quantity: 1}}}
+	suite.Equal(entity.ErrNotABundle, repo.Replace(ctx, b))
+}
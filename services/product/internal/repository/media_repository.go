@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+const mediaColumns = "id, product_id, variant_sku, position, alt_text, content_type, width, height, original_key, renditions, created_at"
+
+type MediaRepository struct {
+	db *sqlx.DB
+}
+
+func NewMediaRepository(db *sqlx.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+// Create appends the media after the last one of the product
+func (r *MediaRepository) Create(ctx context.Context, m *entity.ProductMedia) (int64, error) {
+
+	err := r.db.QueryRowContext(ctx, `INSERT INTO product_media
+		(product_id, variant_sku, position, alt_text, content_type, width, height, original_key, renditions, created_at)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM product_media WHERE product_id = $3), $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, position`,
+		m.ProductID, m.VariantSKU, m.ProductID, m.AltText, m.ContentType, m.Width, m.Height, m.OriginalKey, m.Renditions, m.CreatedAt,
+	).Scan(&m.ID, &m.Position)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.ID, nil
+}
+
+func (r *MediaRepository) GetByID(ctx context.Context, id int64) (*entity.ProductMedia, error) {
+
+	var m entity.ProductMedia
+	if err := r.db.GetContext(ctx, &m, "SELECT "+mediaColumns+" FROM product_media WHERE id = $1", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *MediaRepository) ListByProduct(ctx context.Context, productID int64) ([]entity.ProductMedia, error) {
+
+	list := []entity.ProductMedia{}
+	err := r.db.SelectContext(ctx, &list, "SELECT "+mediaColumns+" FROM product_media WHERE product_id = $1 ORDER BY position, id", productID)
+	return list, err
+}
+
+// ListByProducts returns the media of several products keyed by product id
+func (r *MediaRepository) ListByProducts(ctx context.Context, productIDs []int64) (map[int64][]entity.ProductMedia, error) {
+
+	byProduct := make(map[int64][]entity.ProductMedia)
+	if len(productIDs) == 0 {
+		return byProduct, nil
+	}
+
+	query, args, err := sqlx.In("SELECT "+mediaColumns+" FROM product_media WHERE product_id IN (?) ORDER BY product_id, position, id", productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []entity.ProductMedia
+	if err := r.db.SelectContext(ctx, &list, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, m := range list {
+		byProduct[m.ProductID] = append(byProduct[m.ProductID], m)
+	}
+	return byProduct, nil
+}
+
+func (r *MediaRepository) Delete(ctx context.Context, id int64) error {
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM product_media WHERE id = $1", id)
+	return err
+}
+
+// Reorder sets the position of each media to its index in ids
+func (r *MediaRepository) Reorder(ctx context.Context, productID int64, ids []int64) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE product_media SET position = $1 WHERE id = $2 AND product_id = $3", i+1, id, productID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+func migrateMediaDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE product_media (
+    id integer PRIMARY KEY,
+    product_id integer NOT NULL,
+    variant_sku VARCHAR(255) NOT NULL DEFAULT '',
+    position integer NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    content_type VARCHAR(255) NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    original_key TEXT NOT NULL,
+    renditions TEXT NOT NULL,
+    created_at DATETIME
+);`)
+
+	return db, err
+}
+
+type MediaRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestMediaRepositorySuite(t *testing.T) {
+	suite.Run(t, new(MediaRepositoryTestSuite))
+}
+
+func (suite *MediaRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *MediaRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migrateMediaDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *MediaRepositoryTestSuite) TestCreateAndReorder() {
+
+	ctx := context.Background()
+	repo := NewMediaRepository(suite.DB)
+
+	first := &entity.ProductMedia{ProductID: 1, ContentType: "image/png", Width: 10, Height: 10, OriginalKey: "a.png", CreatedAt: time.Now(),
+		Renditions: entity.MediaRenditions{{Name: "thumb", Format: "webp", Width: 10, Height: 10, Key: "a.webp"}}}
+	second := &entity.ProductMedia{ProductID: 1, ContentType: "image/png", Width: 10, Height: 10, OriginalKey: "b.png", CreatedAt: time.Now()}
+
+	_, err := repo.Create(ctx, first)
+	suite.Nil(err)
+	_, err = repo.Create(ctx, second)
+	suite.Nil(err)
+	suite.Equal(1, first.Position)
+	suite.Equal(2, second.Position)
+
+	suite.Nil(repo.Reorder(ctx, 1, []int64{second.ID, first.ID}))
+
+	list, err := repo.ListByProduct(ctx, 1)
+	suite.Nil(err)
+	suite.Len(list, 2)
+	suite.Equal(second.ID, list[0].ID)
+	suite.Equal("a.webp", list[1].Renditions[0].Key)
+
+	byProduct, err := repo.ListByProducts(ctx, []int64{1, 2})
+	suite.Nil(err)
+	suite.Len(byProduct[1], 2)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+type PriceListRepository struct {
+	db *sqlx.DB
+}
+
+func NewPriceListRepository(db *sqlx.DB) *PriceListRepository {
+	return &PriceListRepository{db: db}
+}
+
+func (r *PriceListRepository) Create(ctx context.Context, pl *entity.PriceList) (int64, error) {
+
+	err := r.db.QueryRowContext(ctx, `INSERT INTO price_lists (name, currency, region, customer_group, priority, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		pl.Name, pl.Currency, pl.Region, pl.CustomerGroup, pl.Priority, pl.StartsAt, pl.EndsAt).Scan(&pl.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return pl.ID, nil
+}
+
+func (r *PriceListRepository) GetByID(ctx context.Context, id int64) (*entity.PriceList, error) {
+
+	var pl entity.PriceList
+	err := r.db.GetContext(ctx, &pl, `SELECT id, name, currency, region, customer_group, priority, starts_at, ends_at
+		FROM price_lists WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pl, nil
+}
+
+// SetPrice creates or replaces the price of a product in a price list
+func (r *PriceListRepository) SetPrice(ctx context.Context, priceListID, productID int64, price money.Money) error {
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO price_list_items (price_list_id, product_id, price) VALUES ($1, $2, $3)
+		ON CONFLICT (price_list_id, product_id) DO UPDATE SET price = excluded.price`,
+		priceListID, productID, price.Decimal())
+	return err
+}
+
+// FindPrice returns the price of the most specific list matching the context: a region match beats a
+// catch-all list, then a customer group match, then the list priority and the most recent start.
+func (r *PriceListRepository) FindPrice(ctx context.Context, productID int64, pc entity.PriceContext) (*money.Money, error) {
+
+	at := pc.At.UTC()
+
+	row := r.db.QueryRowContext(ctx, `SELECT i.price, l.currency
+		FROM price_list_items i
+		JOIN price_lists l ON l.id = i.price_list_id
+		WHERE i.product_id = $1
+		  AND l.currency = $2
+		  AND (l.region = $3 OR l.region = '')
+		  AND (l.customer_group = $4 OR l.customer_group = '')
+		  AND l.starts_at <= $5
+		  AND (l.ends_at IS NULL OR l.ends_at > $6)
+		ORDER BY (l.region <> '') DESC, (l.customer_group <> '') DESC, l.priority DESC, l.starts_at DESC
+		LIMIT 1`,
+		productID, pc.Currency, pc.Region, pc.CustomerGroup, at, at)
+
+	var price, currency string
+	if err := row.Scan(&price, &currency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	m, err := money.Parse(price, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
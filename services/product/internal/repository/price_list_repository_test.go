@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/suite"
+)
+
+func migratePriceListDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE price_lists (
+    id integer PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    region VARCHAR(255) NOT NULL DEFAULT '',
+    customer_group VARCHAR(255) NOT NULL DEFAULT '',
+    priority integer NOT NULL DEFAULT 0,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME
+);
+CREATE TABLE price_list_items (
+    price_list_id integer NOT NULL,
+    product_id integer NOT NULL,
+    price NUMERIC NOT NULL,
+    PRIMARY KEY (price_list_id, product_id)
+);`)
+
+	return db, err
+}
+
+type PriceListRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestPriceListRepositorySuite(t *testing.T) {
+	suite.Run(t, new(PriceListRepositoryTestSuite))
+}
+
+func (suite *PriceListRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *PriceListRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migratePriceListDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *PriceListRepositoryTestSuite) TestFindPrice() {
+
+	ctx := context.Background()
+	repo := NewPriceListRepository(suite.DB)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	general, _ := entity.NewPriceList(0, "EUR general", "EUR", "", "", 0, start, nil)
+	_, err := repo.Create(ctx, general)
+	suite.Nil(err)
+	suite.Nil(repo.SetPrice(ctx, general.ID, 1, money.Money{Amount: 1000, Currency: "EUR"}))
+
+	regional, _ := entity.NewPriceList(0, "EUR Portugal sale", "EUR", "PT", "", 0, start, &end)
+	_, err = repo.Create(ctx, regional)
+	suite.Nil(err)
+	suite.Nil(repo.SetPrice(ctx, regional.ID, 1, money.Money{Amount: 900, Currency: "EUR"}))
+	suite.Nil(repo.SetPrice(ctx, regional.ID, 1, money.Money{Amount: 850, Currency: "EUR"}))
+
+	price, err := repo.FindPrice(ctx, 1, entity.PriceContext{Currency: "EUR", Region: "PT", At: start.Add(time.Hour)})
+	suite.Nil(err)
+	suite.Equal(&money.Money{Amount: 850, Currency: "EUR"}, price)
+
+	price, err = repo.FindPrice(ctx, 1, entity.PriceContext{Currency: "EUR", Region: "PT", At: end.Add(time.Hour)})
+	suite.Nil(err)
+	suite.Equal(&money.Money{Amount: 1000, Currency: "EUR"}, price)
+
+	price, err = repo.FindPrice(ctx, 1, entity.PriceContext{Currency: "BRL", At: start.Add(time.Hour)})
+	suite.Nil(err)
+	suite.Nil(price)
+}
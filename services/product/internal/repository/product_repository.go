@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 )
 
 type ProductRepository struct {
@@ -17,46 +20,470 @@ func NewProductRepository(db *sqlx.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// visibleMatch keeps the products customers see, see entity.Product.IsVisible
+const visibleMatch = "p.status = 'active' AND (p.published_at IS NULL OR p.published_at <= now())"
+
+// productColumns lists the columns read by scanProduct, in order
+const productColumns = "id, sku, name, description, category, kind, price, currency, compare_at_price, attributes, slug, seo_title, seo_description, status, published_at, rating_1, rating_2, rating_3, rating_4, rating_5"
+
+func scanProduct(row scanner, extra ...any) (*entity.Product, error) {
+
+	var p entity.Product
+	var price, currency string
+	var compareAt, slug sql.NullString
+	var histogram [5]int
+
+	dest := append([]any{&p.ID, &p.SKU, &p.Name, &p.Description, &p.Category, &p.Kind, &price, &currency, &compareAt, &p.Attributes,
+		&slug, &p.SEOTitle, &p.SEODescription, &p.Status, &p.PublishedAt, &histogram[0], &histogram[1], &histogram[2], &histogram[3], &histogram[4]}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	p.Rating = entity.NewRatingSummary(histogram)
+	p.Slug = slug.String
+
+	m, err := money.Parse(price, currency)
+	if err != nil {
+		return nil, err
+	}
+	p.Price = m
+
+	if compareAt.Valid {
+		c, err := money.Parse(compareAt.String, currency)
+		if err != nil {
+			return nil, err
+		}
+		p.CompareAtPrice = &c
+	}
+
+	return &p, nil
+}
+
+// Create inserts p; without a slug it gets one made from its name
 func (r *ProductRepository) Create(ctx context.Context, p *entity.Product) (int64, error) {
 
-	err := r.db.QueryRowContext(ctx, "INSERT INTO products (name, price) VALUES ($1, $2) RETURNING id", p.Name, p.Price).Scan(&p.ID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := insertProduct(ctx, tx, p); err != nil {
+		return 0, err
+	}
+
+	return p.ID, tx.Commit()
+}
+
+// insertProduct is shared with the bundle repository, which inserts bundles in a transaction
+func insertProduct(ctx context.Context, tx *sqlx.Tx, p *entity.Product) (int64, error) {
+
+	if p.Kind == "" {
+		p.Kind = entity.ProductSimple
+	}
+	if p.Status == "" {
+		p.Status = entity.ProductDraft
+	}
+
+	err := tx.QueryRowContext(ctx, `INSERT INTO products (sku, name, description, category, kind, price, currency, attributes, seo_title, seo_description, status, published_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		p.SKU, p.Name, p.Description, p.Category, p.Kind, p.Price.Decimal(), p.Price.Currency, p.Attributes, p.SEOTitle, p.SEODescription,
+		p.Status, p.PublishedAt).Scan(&p.ID)
 	if err != nil {
+		return 0, mapUniqueViolation(err)
+	}
+	if err := setSlug(ctx, tx, p); err != nil {
 		return 0, err
 	}
 
-	return p.ID, err
+	return p.ID, nil
+}
+
+// Update stores p. A product without a slug gets a new one made from its name; the slugs it had
+// stay in the history and keep pointing to it.
+func (r *ProductRepository) Update(ctx context.Context, p *entity.Product) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE products SET sku = $1, name = $2, description = $3, category = $4, price = $5, currency = $6, attributes = $7,
+		seo_title = $8, seo_description = $9 WHERE id = $10`,
+		p.SKU, p.Name, p.Description, p.Category, p.Price.Decimal(), p.Price.Currency, p.Attributes, p.SEOTitle, p.SEODescription, p.ID)
+	if err != nil {
+		return mapUniqueViolation(err)
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if err := setSlug(ctx, tx, p); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setSlug gives p the slug it asks for, failing with entity.ErrSlugTaken when another product has or had it.
+// Without one p gets the slug of its name, or when that is taken the same suffixed with its id.
+func setSlug(ctx context.Context, tx *sqlx.Tx, p *entity.Product) error {
+
+	candidates := []string{p.Slug}
+	if p.Slug == "" {
+		slug := entity.Slugify(p.Name, p.SKU)
+		candidates = []string{slug, entity.SuffixSlug(slug, p.ID)}
+	}
+
+	for _, slug := range candidates {
+		// slugs are never reused by another product, so old links cannot land on the wrong one
+		_, err := tx.ExecContext(ctx, "INSERT INTO product_slugs (slug, product_id) VALUES ($1, $2) ON CONFLICT (slug) DO NOTHING", slug, p.ID)
+		if err != nil {
+			return err
+		}
+		var owner int64
+		if err := tx.QueryRowContext(ctx, "SELECT product_id FROM product_slugs WHERE slug = $1", slug).Scan(&owner); err != nil {
+			return err
+		}
+		if owner != p.ID {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET slug = $1 WHERE id = $2", slug, p.ID); err != nil {
+			return err
+		}
+		p.Slug = slug
+		return nil
+	}
+	return entity.ErrSlugTaken
+}
+
+// CountSlugs returns how many products customers see have a slug, i.e. can be linked to
+func (r *ProductRepository) CountSlugs(ctx context.Context) (int, error) {
+
+	var n int
+	err := r.db.QueryRowContext(ctx, "SELECT count(*) FROM products p WHERE slug IS NOT NULL AND "+visibleMatch).Scan(&n)
+	return n, err
+}
+
+// EachSlug hands fn the current slugs of the products customers see in id order, skipping offset and stopping after limit
+func (r *ProductRepository) EachSlug(ctx context.Context, offset, limit int, fn func(slug string) error) error {
+
+	rows, err := r.db.QueryContext(ctx, "SELECT slug FROM products p WHERE slug IS NOT NULL AND "+visibleMatch+" ORDER BY id LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return err
+		}
+		if err := fn(slug); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Categories returns the categories that have products customers see, sorted
+func (r *ProductRepository) Categories(ctx context.Context, limit int) ([]string, error) {
+
+	var categories []string
+	err := r.db.SelectContext(ctx, &categories, "SELECT DISTINCT category FROM products p WHERE category <> '' AND "+visibleMatch+" ORDER BY category LIMIT $1", limit)
+	return categories, err
+}
+
+// ProductIDBySlug returns the product that has or had slug, or 0
+func (r *ProductRepository) ProductIDBySlug(ctx context.Context, slug string) (int64, error) {
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, "SELECT product_id FROM product_slugs WHERE slug = $1", slug).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM products WHERE id = $1", id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return entity.ErrComponentInUse
+		}
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// requireAffected turns an update or delete that matched no row into entity.ErrProductNotFound
+func requireAffected(res sql.Result) error {
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entity.ErrProductNotFound
+	}
+	return nil
+}
+
+// mapUniqueViolation reports a duplicate sku as entity.ErrSKUAlreadyExists
+func mapUniqueViolation(err error) error {
+
+	if isUniqueViolation(err) {
+		return entity.ErrSKUAlreadyExists
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
 }
 
 func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*entity.Product, error) {
-	row := r.db.QueryRowContext(ctx, "SELECT id, name, price FROM products WHERE id = $1", id)
+	row := r.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1", id)
 
-	var p entity.Product
-	if err := row.Scan(&p.ID, &p.Name, &p.Price); err != nil {
+	p, err := scanProduct(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &p, nil
+	return p, nil
+}
+
+// GetByIDs returns the products that exist among ids, in no particular order
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []int64) ([]entity.Product, error) {
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]entity.Product, 0, len(ids))
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+
+	return list, rows.Err()
 }
 
-func (r *ProductRepository) GetList(ctx context.Context, limit int) ([]entity.Product, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, name, price FROM products limit $1", limit)
+func (r *ProductRepository) GetList(ctx context.Context, lq entity.ListQuery) ([]entity.Product, error) {
+
+	query := "SELECT " + productColumns + " FROM products p WHERE ($2 = '' OR p.category = $2) AND ($3 OR " + visibleMatch + ")"
+	args := []any{lq.Limit, lq.Category, lq.IncludeUnpublished}
+	if len(lq.Attributes) > 0 {
+		equals, ranges, err := attributeArgs(lq.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND " + attributeMatch(4, 5)
+		args = append(args, equals, ranges)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY p.id LIMIT $1", args...)
 	if err != nil {
 		return []entity.Product{}, err
 	}
+	defer rows.Close()
 
 	var list []entity.Product
 
 	for rows.Next() {
-		var p entity.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 
 			return []entity.Product{}, err
 		}
 
-		list = append(list, p)
+		list = append(list, *p)
 	}
 
 	return list, nil
 }
+
+// GetPage returns up to limit products with an id greater than afterID, ordered by id
+func (r *ProductRepository) GetPage(ctx context.Context, afterID int64, limit int) ([]entity.Product, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products WHERE id > $1 ORDER BY id LIMIT $2", afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.Product
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, *p)
+	}
+
+	return list, rows.Err()
+}
+
+// GetBySKUs returns the products that exist among skus, in no particular order
+func (r *ProductRepository) GetBySKUs(ctx context.Context, skus []string) ([]entity.Product, error) {
+
+	if len(skus) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In("SELECT "+productColumns+" FROM products WHERE sku IN (?)", skus)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+// ExistingSKUs returns which of the given SKUs are already stored
+func (r *ProductRepository) ExistingSKUs(ctx context.Context, skus []string) (map[string]bool, error) {
+
+	existing := make(map[string]bool)
+	if len(skus) == 0 {
+		return existing, nil
+	}
+
+	query, args, err := sqlx.In("SELECT sku FROM products WHERE sku IN (?)", skus)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []string
+	if err := r.db.SelectContext(ctx, &found, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, sku := range found {
+		existing[sku] = true
+	}
+
+	return existing, nil
+}
+
+// UpsertBatch inserts the products in a single statement, updating the ones whose SKU already exists.
+// IDs are set on the given products; SKUs must be unique within the batch.
+func (r *ProductRepository) UpsertBatch(ctx context.Context, products []*entity.Product) (created int, updated int, err error) {
+
+	if len(products) == 0 {
+		return 0, 0, nil
+	}
+
+	skus := make([]string, len(products))
+	values := make([]string, len(products))
+	args := make([]any, 0, len(products)*7)
+	for i, p := range products {
+		skus[i] = p.SKU
+		values[i] = "(?, ?, ?, ?, ?, ?, ?)"
+		args = append(args, p.SKU, p.Name, p.Description, p.Category, p.Price.Decimal(), p.Price.Currency, p.Attributes)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	query, inArgs, err := sqlx.In("SELECT sku, name FROM products WHERE sku IN (?)", skus)
+	if err != nil {
+		return 0, 0, err
+	}
+	var existing []struct {
+		SKU  string `db:"sku"`
+		Name string `db:"name"`
+	}
+	if err := tx.SelectContext(ctx, &existing, tx.Rebind(query), inArgs...); err != nil {
+		return 0, 0, err
+	}
+	names := make(map[string]string, len(existing))
+	for _, e := range existing {
+		names[e.SKU] = e.Name
+	}
+
+	rows, err := tx.QueryxContext(ctx, tx.Rebind(`INSERT INTO products (sku, name, description, category, price, currency, attributes)
+		VALUES `+strings.Join(values, ", ")+`
+		ON CONFLICT (sku) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			category = excluded.category,
+			price = excluded.price,
+			currency = excluded.currency,
+			attributes = excluded.attributes
+		RETURNING id, sku`), args...)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ids := make(map[string]int64, len(products))
+	for rows.Next() {
+		var id int64
+		var sku string
+		if err := rows.Scan(&id, &sku); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		ids[sku] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, p := range products {
+		p.ID = ids[p.SKU]
+		// new and renamed products get the slug of their name
+		if name, ok := names[p.SKU]; !ok || name != p.Name {
+			p.Slug = ""
+			if err := setSlug(ctx, tx, p); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	updated = len(existing)
+	return len(products) - updated, updated, nil
+}
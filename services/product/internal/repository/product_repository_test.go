@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -19,8 +21,39 @@ func migrateDB() (*sqlx.DB, error) {
 
 	_, err = db.Exec(`CREATE TABLE products (
     id integer PRIMARY KEY,
+    sku VARCHAR(255) NOT NULL UNIQUE,
     name VARCHAR(255) NOT NULL,
-    price REAL
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(255) NOT NULL DEFAULT '',
+    kind VARCHAR(16) NOT NULL DEFAULT 'simple',
+    price NUMERIC,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    compare_at_price NUMERIC,
+    attributes TEXT NOT NULL DEFAULT '{}',
+    slug TEXT UNIQUE,
+    seo_title TEXT NOT NULL DEFAULT '',
+    seo_description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'draft',
+    published_at DATETIME,
+    rating_1 integer NOT NULL DEFAULT 0,
+    rating_2 integer NOT NULL DEFAULT 0,
+    rating_3 integer NOT NULL DEFAULT 0,
+    rating_4 integer NOT NULL DEFAULT 0,
+    rating_5 integer NOT NULL DEFAULT 0
+);
+CREATE TABLE product_slugs (
+    slug TEXT PRIMARY KEY,
+    product_id integer NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE product_status_changes (
+    id integer PRIMARY KEY,
+    product_id integer NOT NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    published_at DATETIME,
+    changed_by integer NOT NULL DEFAULT 0,
+    changed_at DATETIME NOT NULL
 );`)
 
 	return db, err
@@ -47,7 +80,7 @@ func (suite *ProductRepositoryTestSuite) SetupSuite() {
 
 func (suite *ProductRepositoryTestSuite) TestCreate() {
 
-	p, _ := entity.NewProduct(1, "Product 1", 2.1)
+	p, _ := entity.NewProduct(1, "SKU-1", "Product 1", "", "", money.Money{Amount: 210, Currency: "USD"})
 
 	repo := NewProductRepository(suite.DB)
 	id, err := repo.Create(context.Background(), p)
@@ -58,7 +91,8 @@ func (suite *ProductRepositoryTestSuite) TestCreate() {
 
 func (suite *ProductRepositoryTestSuite) TestGetById() {
 
-	p, _ := entity.NewProduct(2, "Product 2", 2.1)
+	p, _ := entity.NewProduct(2, "SKU-2", "Product 2", "Second product", "books", money.Money{Amount: 210, Currency: "BRL"})
+	p.Attributes = entity.Attributes{"pages": 320.0, "hardcover": true}
 
 	repo := NewProductRepository(suite.DB)
 	id, err := repo.Create(context.Background(), p)
@@ -70,5 +104,134 @@ func (suite *ProductRepositoryTestSuite) TestGetById() {
 	suite.Nil(err)
 	suite.NotNil(p2)
 	suite.Equal(p.ID, p2.ID)
+	suite.Equal(p.Price, p2.Price)
+	suite.Equal(p.Category, p2.Category)
+	suite.Equal(p.Attributes, p2.Attributes)
+
+}
+
+func (suite *ProductRepositoryTestSuite) TestUpsertBatch() {
+
+	ctx := context.Background()
+	repo := NewProductRepository(suite.DB)
+
+	p, _ := entity.NewProduct(0, "SKU-3", "Product 3", "", "", money.Money{Amount: 100, Currency: "USD"})
+	_, err := repo.Create(ctx, p)
+	suite.Nil(err)
+
+	updated, _ := entity.NewProduct(0, "SKU-3", "Product 3 v2", "", "", money.Money{Amount: 150, Currency: "USD"})
+	created, _ := entity.NewProduct(0, "SKU-4", "Product 4", "", "", money.Money{Amount: 200, Currency: "USD"})
+
+	existing, err := repo.ExistingSKUs(ctx, []string{"SKU-3", "SKU-4"})
+	suite.Nil(err)
+	suite.Equal(map[string]bool{"SKU-3": true}, existing)
+
+	c, u, err := repo.UpsertBatch(ctx, []*entity.Product{updated, created})
+	suite.Nil(err)
+	suite.Equal(1, c)
+	suite.Equal(1, u)
+	suite.Equal(p.ID, updated.ID)
+	suite.NotEmpty(created.ID)
+
+	p2, err := repo.GetByID(ctx, p.ID)
+	suite.Nil(err)
+	suite.Equal("Product 3 v2", p2.Name)
+	suite.Equal(int64(150), p2.Price.Amount)
+}
+
+func (suite *ProductRepositoryTestSuite) TestUpdateAndDelete() {
+
+	ctx := context.Background()
+	repo := NewProductRepository(suite.DB)
+
+	p, _ := entity.NewProduct(0, "SKU-5", "Product 5", "", "", money.Money{Amount: 100, Currency: "USD"})
+	_, err := repo.Create(ctx, p)
+	suite.Nil(err)
+
+	p.Name = "Product 5 v2"
+	suite.Nil(repo.Update(ctx, p))
+
+	p2, err := repo.GetByID(ctx, p.ID)
+	suite.Nil(err)
+	suite.Equal("Product 5 v2", p2.Name)
+
+	suite.Nil(repo.Delete(ctx, p.ID))
+	suite.Equal(entity.ErrProductNotFound, repo.Delete(ctx, p.ID))
+	suite.Equal(entity.ErrProductNotFound, repo.Update(ctx, p))
+}
+
+func (suite *ProductRepositoryTestSuite) TestSlugs() {
+
+	ctx := context.Background()
+	repo := NewProductRepository(suite.DB)
+
+	p, _ := entity.NewProduct(0, "SKU-6", "Blue Mug", "", "", money.Money{Amount: 100, Currency: "USD"})
+	_, err := repo.Create(ctx, p)
+	suite.Nil(err)
+	suite.Equal("blue-mug", p.Slug)
+
+	twin, _ := entity.NewProduct(0, "SKU-7", "Blue Mug", "", "", money.Money{Amount: 100, Currency: "USD"})
+	_, err = repo.Create(ctx, twin)
+	suite.Nil(err)
+	suite.Equal(entity.SuffixSlug("blue-mug", twin.ID), twin.Slug)
+
+	// a new name gets a new slug and the old one still finds the product
+	p.Name, p.Slug = "Navy Mug", ""
+	suite.Nil(repo.Update(ctx, p))
+	suite.Equal("navy-mug", p.Slug)
+
+	id, err := repo.ProductIDBySlug(ctx, "blue-mug")
+	suite.Nil(err)
+	suite.Equal(p.ID, id)
+
+	stored, err := repo.GetByID(ctx, p.ID)
+	suite.Nil(err)
+	suite.Equal("navy-mug", stored.Slug)
+
+	// the old slug stays with p
+	twin.Slug = "blue-mug"
+	suite.Equal(entity.ErrSlugTaken, repo.Update(ctx, twin))
+
+	id, err = repo.ProductIDBySlug(ctx, "no-such-mug")
+	suite.Nil(err)
+	suite.Zero(id)
+}
 
+func (suite *ProductRepositoryTestSuite) TestSetStatus() {
+
+	ctx := context.Background()
+	repo := NewProductRepository(suite.DB)
+
+	p, _ := entity.NewProduct(0, "SKU-8", "Green Mug", "", "", money.Money{Amount: 100, Currency: "USD"})
+	_, err := repo.Create(ctx, p)
+	suite.Nil(err)
+
+	stored, err := repo.GetByID(ctx, p.ID)
+	suite.Nil(err)
+	suite.Equal(entity.ProductDraft, stored.Status)
+	suite.Nil(stored.PublishedAt)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	c, err := entity.NewStatusChange(stored, entity.ProductActive, nil, 7, now)
+	suite.Nil(err)
+	suite.Nil(repo.SetStatus(ctx, c))
+	suite.NotZero(c.ID)
+
+	stored, err = repo.GetByID(ctx, p.ID)
+	suite.Nil(err)
+	suite.Equal(entity.ProductActive, stored.Status)
+	suite.True(now.Equal(*stored.PublishedAt))
+
+	// a change computed from the draft product is stale now
+	suite.Equal(entity.ErrStatusChanged, repo.SetStatus(ctx, c))
+
+	c.ProductID = 999
+	suite.Equal(entity.ErrProductNotFound, repo.SetStatus(ctx, c))
+
+	history, err := repo.ListStatusChanges(ctx, p.ID)
+	suite.Nil(err)
+	suite.Len(history, 1)
+	suite.Equal(entity.ProductDraft, history[0].From)
+	suite.Equal(entity.ProductActive, history[0].To)
+	suite.Equal(int64(7), history[0].ChangedBy)
 }
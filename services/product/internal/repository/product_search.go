@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// priceBucketBounds are the lower bounds, in major currency units, of the price facet buckets
+var priceBucketBounds = []int64{0, 25, 50, 100, 250, 500, 1000}
+
+// priceBucketScales multiplies the bounds for currencies whose major unit is worth far less than a dollar,
+// so that a bucket spans about the same prices in every currency; the others take the bounds as they are
+var priceBucketScales = map[string]int64{
+	"BRL": 5,
+	"MXN": 20,
+	"JPY": 100,
+	"ARS": 1000,
+	"CLP": 1000,
+	"KRW": 1000,
+}
+
+func priceBucketScale(currency string) int64 {
+
+	if scale, ok := priceBucketScales[currency]; ok {
+		return scale
+	}
+	return 1
+}
+
+// priceBucketBound returns bound i of the price buckets in currency
+func priceBucketBound(currency string, i int) (money.Money, error) {
+	return money.Parse(strconv.FormatInt(priceBucketBounds[i]*priceBucketScale(currency), 10), currency)
+}
+
+// searchMatch selects products whose weighted tsvector matches the query ($1, empty matches all), falling back to
+// trigram similarity on the name so that typos still find results. $2..$7 are the filters and $8 lets unpublished
+// products match.
+var searchMatch = `
+	FROM products p, websearch_to_tsquery('simple', $1) q
+	WHERE ($1 = '' OR p.search_vector @@ q OR p.name % $1)
+	  AND ($2 = '' OR p.category = $2)
+	  AND ($3 = '' OR p.currency = $3)
+	  AND ($4::numeric IS NULL OR p.price >= $4::numeric)
+	  AND ($5::numeric IS NULL OR p.price < $5::numeric)
+	  AND ($8 OR ` + visibleMatch + `)
+	  AND ` + attributeMatch(6, 7)
+
+// attributeMatch filters on the attributes column: the parameter numbered equals holds the JSON object the
+// attributes must contain, which the GIN index answers, and ranges a JSON array of {code, min, max}
+// that number attributes must fall within
+func attributeMatch(equals, ranges int) string {
+
+	return fmt.Sprintf(`p.attributes @> $%d::jsonb
+	  AND NOT EXISTS (
+		SELECT 1 FROM jsonb_to_recordset($%d::jsonb) AS f(code text, min numeric, max numeric),
+			LATERAL (SELECT CASE WHEN jsonb_typeof(p.attributes->f.code) = 'number'
+				THEN (p.attributes->f.code)::numeric END AS v) a
+		WHERE a.v IS NULL OR a.v < f.min OR a.v > f.max)`, equals, ranges)
+}
+
+type attributeRange struct {
+	Code string   `json:"code"`
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+}
+
+// attributeArgs encodes filters as the two parameters of attributeMatch
+func attributeArgs(filters []entity.AttributeFilter) (string, string, error) {
+
+	equals := entity.Attributes{}
+	ranges := []attributeRange{}
+	for _, f := range filters {
+		if f.Equals != nil {
+			equals[f.Code] = f.Equals
+		}
+		if f.Min != nil || f.Max != nil {
+			ranges = append(ranges, attributeRange{Code: f.Code, Min: f.Min, Max: f.Max})
+		}
+	}
+
+	e, err := json.Marshal(equals)
+	if err != nil {
+		return "", "", err
+	}
+	r, err := json.Marshal(ranges)
+	if err != nil {
+		return "", "", err
+	}
+	return string(e), string(r), nil
+}
+
+// Search runs a full-text search on name (weight A) and description (weight B). The search_vector
+// column is generated by Postgres, so every insert or update of a product keeps the index current.
+func (r *ProductRepository) Search(ctx context.Context, sq entity.SearchQuery) (*entity.SearchResult, error) {
+
+	args, err := searchArgs(sq)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.SearchResult{}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+productColumns+`,
+		ts_rank_cd(p.search_vector, q) + similarity(p.name, $1) AS score,
+		ts_headline('simple', p.name, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+		ts_headline('simple', p.description, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')`+
+		searchMatch+`
+		ORDER BY score DESC, p.id
+		LIMIT $9 OFFSET $10`, append(args, sq.Limit, sq.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit entity.SearchHit
+		p, err := scanProduct(rows, &hit.Score, &hit.NameHighlight, &hit.DescriptionHighlight)
+		if err != nil {
+			return nil, err
+		}
+		hit.Product = *p
+		result.Hits = append(result.Hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*)`+searchMatch, args...).Scan(&result.Total); err != nil {
+		return nil, err
+	}
+
+	if result.Categories, err = r.categoryFacets(ctx, args); err != nil {
+		return nil, err
+	}
+	if result.PriceBuckets, err = r.priceFacets(ctx, args); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *ProductRepository) categoryFacets(ctx context.Context, args []any) ([]entity.CategoryFacet, error) {
+
+	rows, err := r.db.QueryContext(ctx, `SELECT p.category, count(*)`+searchMatch+`
+		GROUP BY p.category ORDER BY count(*) DESC, p.category`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facets []entity.CategoryFacet
+	for rows.Next() {
+		var f entity.CategoryFacet
+		if err := rows.Scan(&f.Category, &f.Count); err != nil {
+			return nil, err
+		}
+		facets = append(facets, f)
+	}
+	return facets, rows.Err()
+}
+
+func (r *ProductRepository) priceFacets(ctx context.Context, args []any) ([]entity.PriceBucket, error) {
+
+	bounds := make([]string, len(priceBucketBounds))
+	for i, b := range priceBucketBounds {
+		bounds[i] = strconv.FormatInt(b, 10)
+	}
+	var currencies []string
+	var scales []int64
+	for c, scale := range priceBucketScales {
+		currencies = append(currencies, c)
+		scales = append(scales, scale)
+	}
+
+	// prices are brought to the scale of the bounds first; width_bucket returns i for
+	// bounds[i-1] <= price < bounds[i], and len(bounds) past the last bound
+	rows, err := r.db.QueryContext(ctx, `SELECT p.currency, width_bucket(p.price / COALESCE(
+			(SELECT s.scale FROM unnest($9::text[], $10::numeric[]) AS s(currency, scale) WHERE s.currency = p.currency), 1),
+			$11::numeric[]) AS bucket, count(*)`+searchMatch+`
+		GROUP BY p.currency, bucket ORDER BY p.currency, bucket`, append(args, pq.Array(currencies), pq.Array(scales), pq.Array(bounds))...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []entity.PriceBucket
+	for rows.Next() {
+		var currency string
+		var bucket int
+		var count int64
+		if err := rows.Scan(&currency, &bucket, &count); err != nil {
+			return nil, err
+		}
+		if bucket == 0 {
+			continue
+		}
+
+		min, err := priceBucketBound(currency, bucket-1)
+		if err != nil {
+			return nil, err
+		}
+		b := entity.PriceBucket{Min: min, Count: count}
+		if bucket < len(bounds) {
+			max, err := priceBucketBound(currency, bucket)
+			if err != nil {
+				return nil, err
+			}
+			b.Max = &max
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func searchArgs(sq entity.SearchQuery) ([]any, error) {
+
+	var currency string
+	var min, max *string
+
+	if sq.MinPrice != nil {
+		currency = sq.MinPrice.Currency
+		v := sq.MinPrice.Decimal()
+		min = &v
+	}
+	if sq.MaxPrice != nil {
+		currency = sq.MaxPrice.Currency
+		v := sq.MaxPrice.Decimal()
+		max = &v
+	}
+
+	equals, ranges, err := attributeArgs(sq.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{sq.Text, sq.Category, currency, min, max, equals, ranges, sq.IncludeUnpublished}, nil
+}
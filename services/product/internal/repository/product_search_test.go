@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceBucketBound(t *testing.T) {
+
+	for _, tc := range []struct {
+		currency string
+		i        int
+		want     int64
+	}{
+		{"USD", 1, 2500},
+		{"EUR", 6, 100000},
+		{"JPY", 1, 2500},
+		{"KRW", 2, 50000},
+		{"BRL", 3, 50000},
+		{"KWD", 1, 25000},
+	} {
+		m, err := priceBucketBound(tc.currency, tc.i)
+		assert.Nil(t, err, tc.currency)
+		assert.Equal(t, tc.want, m.Amount, tc.currency)
+		assert.Equal(t, tc.currency, m.Currency)
+	}
+}
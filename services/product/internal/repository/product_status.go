@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+// SetStatus applies c to the product and records it in the status history, provided the product still has
+// the status c moves it from; otherwise it fails with entity.ErrStatusChanged
+func (r *ProductRepository) SetStatus(ctx context.Context, c *entity.StatusChange) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE products SET status = $1, published_at = $2 WHERE id = $3 AND status = $4",
+		c.To, c.PublishedAt, c.ProductID, c.From)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", c.ProductID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return entity.ErrProductNotFound
+		}
+		return entity.ErrStatusChanged
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO product_status_changes (product_id, from_status, to_status, published_at, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		c.ProductID, c.From, c.To, c.PublishedAt, c.ChangedBy, c.ChangedAt).Scan(&c.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListStatusChanges returns the status history of a product, latest first
+func (r *ProductRepository) ListStatusChanges(ctx context.Context, productID int64) ([]entity.StatusChange, error) {
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, product_id, from_status, to_status, published_at, changed_by, changed_at
+		FROM product_status_changes WHERE product_id = $1 ORDER BY changed_at DESC, id DESC`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.StatusChange
+	for rows.Next() {
+		var c entity.StatusChange
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.From, &c.To, &c.PublishedAt, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
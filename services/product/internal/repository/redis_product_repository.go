@@ -1,38 +1,247 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
 	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/go-redis/redis/v8"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/cache"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"golang.org/x/sync/singleflight"
 )
 
+const (
+	// productCacheVersion is part of every key; bump it when the cached JSON shape changes so old
+	// entries are ignored instead of failing to decode
+	productCacheVersion = "v8"
+
+	productTTL         = 10 * time.Minute
+	notFoundTTL        = 30 * time.Second
+	productLocalSize   = 10_000
+	productLocalTTL    = 5 * time.Second
+	ttlJitterFraction  = 0.1
+	notFoundCacheValue = "null"
+)
+
+// cachedProduct is what the L1 cache holds; a nil product records an id known not to exist
+type cachedProduct struct {
+	product *entity.Product
+}
+
+// ProductCache is a cache-aside store for products: a short lived in-process LRU in front of Redis.
+// Concurrent misses for the same id share one load, and ids that do not exist are cached briefly too.
 type ProductCache struct {
 	client *redis.Client
+	local  *cache.LRU[int64, cachedProduct]
+	group  singleflight.Group
 }
 
 func NewProductCache(addr string) *ProductCache {
 
 	db := redis.NewClient(&redis.Options{Addr: addr})
 
-	return &ProductCache{client: db}
+	return &ProductCache{
+		client: db,
+		local:  cache.NewLRU[int64, cachedProduct](productLocalSize, productLocalTTL),
+	}
 }
 
-func (c *ProductCache) SetProduct(key string, value interface{}) error {
+// GetOrLoad returns the product from the cache, calling load on a miss and caching its result.
+// A nil product with a nil error means the product does not exist.
+func (c *ProductCache) GetOrLoad(ctx context.Context, id int64, load func(ctx context.Context) (*entity.Product, error)) (*entity.Product, error) {
+
+	if cp, ok := c.local.Get(id); ok {
+		return cp.copy(), nil
+	}
+
+	ch := c.group.DoChan(productKey(id), func() (any, error) {
+
+		// the load is shared, so one caller giving up must not cancel it for the others
+		ctx := context.WithoutCancel(ctx)
 
-	data, _ := json.Marshal(value)
+		p, found, err := c.get(ctx, id)
+		if err != nil {
+			log.Printf("warning: product cache get %d: %v", id, err)
+		}
+		if found {
+			c.local.Add(id, cachedProduct{p})
+			return cachedProduct{p}, nil
+		}
 
-	return c.client.Set(key, data, 10*time.Minute).Err()
+		p, err = load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Set(ctx, id, p); err != nil {
+			log.Printf("warning: product cache set %d: %v", id, err)
+		}
+		return cachedProduct{p}, nil
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		cp := res.Val.(cachedProduct)
+		return cp.copy(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-func (c *ProductCache) GetProduct(key string, dest interface{}) error {
+// GetMany looks ids up in L1 and then with a single MGET. hits maps every cached id to its product,
+// nil for ids known not to exist; misses are the ids the caller has to load.
+func (c *ProductCache) GetMany(ctx context.Context, ids []int64) (hits map[int64]*entity.Product, misses []int64) {
 
-	data, err := c.client.Get(key).Bytes()
+	hits = make(map[int64]*entity.Product, len(ids))
+	var remote []int64
+	for _, id := range ids {
+		if cp, ok := c.local.Get(id); ok {
+			hits[id] = cp.copy()
+			continue
+		}
+		remote = append(remote, id)
+	}
+	if len(remote) == 0 {
+		return hits, nil
+	}
 
+	keys := make([]string, len(remote))
+	for i, id := range remote {
+		keys[i] = productKey(id)
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.Printf("warning: product cache mget: %v", err)
+		return hits, remote
+	}
+
+	for i, id := range remote {
+		p, found, err := decodeProduct(values[i])
+		if err != nil {
+			log.Printf("warning: product cache decode %d: %v", id, err)
+		}
+		if !found {
+			misses = append(misses, id)
+			continue
+		}
+		c.local.Add(id, cachedProduct{p})
+		hits[id] = cachedProduct{p}.copy()
+	}
+	return hits, misses
+}
+
+// SetMany caches loaded products in one round trip; ids missing from products are cached as not found
+func (c *ProductCache) SetMany(ctx context.Context, ids []int64, products map[int64]*entity.Product) error {
+
+	pipe := c.client.Pipeline()
+	for _, id := range ids {
+		p := products[id]
+		c.local.Add(id, cachedProduct{p})
+
+		value, ttl, err := encodeProduct(p)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, productKey(id), value, ttl)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Set caches the product, or records that the id does not exist when p is nil
+func (c *ProductCache) Set(ctx context.Context, id int64, p *entity.Product) error {
+
+	c.local.Add(id, cachedProduct{p})
+
+	value, ttl, err := encodeProduct(p)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(data, dest)
+	return c.client.Set(ctx, productKey(id), value, ttl).Err()
+}
+
+// Delete drops the product from both layers. Other instances keep their L1 copy until it expires.
+func (c *ProductCache) Delete(ctx context.Context, id int64) error {
+
+	c.local.Remove(id)
+	c.group.Forget(productKey(id))
+
+	return c.client.Del(ctx, productKey(id)).Err()
+}
+
+// get reads Redis; found is false on a miss
+func (c *ProductCache) get(ctx context.Context, id int64) (p *entity.Product, found bool, err error) {
+
+	data, err := c.client.Get(ctx, productKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return decodeProduct(data)
+}
+
+// encodeProduct returns the Redis value and its jittered TTL; nil is stored as the not found marker
+func encodeProduct(p *entity.Product) (string, time.Duration, error) {
+
+	if p == nil {
+		return notFoundCacheValue, jitter(notFoundTTL), nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(data), jitter(productTTL), nil
+}
 
+// decodeProduct reads a value returned by GET or MGET; found is false for missing keys
+func decodeProduct(value any) (p *entity.Product, found bool, err error) {
+
+	data, ok := value.(string)
+	if !ok {
+		return nil, false, nil
+	}
+	if data == notFoundCacheValue {
+		return nil, true, nil
+	}
+
+	p = &entity.Product{}
+	if err := json.Unmarshal([]byte(data), p); err != nil {
+		return nil, false, err
+	}
+	return p, true, nil
+}
+
+func (cp cachedProduct) copy() *entity.Product {
+
+	if cp.product == nil {
+		return nil
+	}
+	p := *cp.product
+	return &p
+}
+
+func productKey(id int64) string {
+	return fmt.Sprintf("product:%s:%d", productCacheVersion, id)
+}
+
+// jitter spreads expirations by up to ±10% so entries written together do not expire together
+func jitter(ttl time.Duration) time.Duration {
+
+	spread := int64(float64(ttl) * ttlJitterFraction)
+	if spread <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int64N(2*spread+1)-spread)
 }
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/cache"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// newOfflineCache points at a closed port so every Redis call fails fast and only the L1 layer works
+func newOfflineCache() *ProductCache {
+	return &ProductCache{
+		client: redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond}),
+		local:  cache.NewLRU[int64, cachedProduct](10, time.Minute),
+	}
+}
+
+func TestProductCacheCollapsesConcurrentMisses(t *testing.T) {
+
+	c := newOfflineCache()
+	var loads atomic.Int32
+	release := make(chan struct{})
+
+	load := func(ctx context.Context) (*entity.Product, error) {
+		loads.Add(1)
+		<-release
+		return &entity.Product{ID: 7, Name: "Mug"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := c.GetOrLoad(context.Background(), 7, load)
+			assert.Nil(t, err)
+			assert.Equal(t, "Mug", p.Name)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestProductCacheRemembersMissingProducts(t *testing.T) {
+
+	c := newOfflineCache()
+	var loads atomic.Int32
+	load := func(ctx context.Context) (*entity.Product, error) {
+		loads.Add(1)
+		return nil, nil
+	}
+
+	for range 2 {
+		p, err := c.GetOrLoad(context.Background(), 8, load)
+		assert.Nil(t, err)
+		assert.Nil(t, p)
+	}
+	assert.Equal(t, int32(1), loads.Load())
+
+	c.Delete(context.Background(), 8)
+	c.GetOrLoad(context.Background(), 8, load)
+	assert.Equal(t, int32(2), loads.Load())
+}
+
+func TestJitterStaysWithinTenPercent(t *testing.T) {
+
+	for range 100 {
+		d := jitter(time.Minute)
+		assert.GreaterOrEqual(t, d, 54*time.Second)
+		assert.LessOrEqual(t, d, 66*time.Second)
+	}
+}
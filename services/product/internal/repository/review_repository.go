@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+const reviewColumns = "id, product_id, user_id, rating, title, body, status, verified_purchase, helpful_count, created_at, moderated_at"
+
+// review listing orders
+const (
+	ReviewSortRecent  = "recent"
+	ReviewSortHelpful = "helpful"
+)
+
+type ReviewRepository struct {
+	db *sqlx.DB
+}
+
+func NewReviewRepository(db *sqlx.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, rv *entity.Review) (int64, error) {
+
+	err := r.db.QueryRowContext(ctx, `INSERT INTO reviews (product_id, user_id, rating, title, body, status, verified_purchase, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		rv.ProductID, rv.UserID, rv.Rating, rv.Title, rv.Body, rv.Status, rv.VerifiedPurchase, rv.CreatedAt).Scan(&rv.ID)
+	if isUniqueViolation(err) {
+		return 0, entity.ErrReviewAlreadyExists
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return rv.ID, nil
+}
+
+func (r *ReviewRepository) GetByID(ctx context.Context, id int64) (*entity.Review, error) {
+
+	var rv entity.Review
+	if err := r.db.GetContext(ctx, &rv, "SELECT "+reviewColumns+" FROM reviews WHERE id = $1", id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rv, nil
+}
+
+// ListByProduct pages through the approved reviews of a product
+func (r *ReviewRepository) ListByProduct(ctx context.Context, productID int64, sort string, limit, offset int) ([]entity.Review, error) {
+
+	order := "created_at DESC, id DESC"
+	if sort == ReviewSortHelpful {
+		order = "helpful_count DESC, created_at DESC, id DESC"
+	}
+
+	list := []entity.Review{}
+	err := r.db.SelectContext(ctx, &list, "SELECT "+reviewColumns+" FROM reviews WHERE product_id = $1 AND status = $2 ORDER BY "+order+" LIMIT $3 OFFSET $4",
+		productID, entity.ReviewApproved, limit, offset)
+	return list, err
+}
+
+// ListByStatus pages through reviews in a moderation state, oldest first
+func (r *ReviewRepository) ListByStatus(ctx context.Context, status entity.ReviewStatus, limit, offset int) ([]entity.Review, error) {
+
+	list := []entity.Review{}
+	err := r.db.SelectContext(ctx, &list, "SELECT "+reviewColumns+" FROM reviews WHERE status = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3",
+		status, limit, offset)
+	return list, err
+}
+
+func (r *ReviewRepository) SetStatus(ctx context.Context, id int64, status entity.ReviewStatus, at time.Time) error {
+
+	res, err := r.db.ExecContext(ctx, "UPDATE reviews SET status = $1, moderated_at = $2 WHERE id = $3", status, at, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entity.ErrReviewNotFound
+	}
+	return nil
+}
+
+// AddHelpfulVote counts a user's vote once; added is false when the user had already voted
+func (r *ReviewRepository) AddHelpfulVote(ctx context.Context, reviewID, userID int64) (added bool, err error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO review_votes (review_id, user_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		reviewID, userID, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE reviews SET helpful_count = helpful_count + 1 WHERE id = $1", reviewID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RefreshRating recounts the approved reviews of a product into its rating columns
+func (r *ReviewRepository) RefreshRating(ctx context.Context, productID int64) (entity.RatingSummary, error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.RatingSummary{}, err
+	}
+	defer tx.Rollback()
+
+	// concurrent refreshes of the product queue up here, so each counts what the one before it committed.
+	// SQLite, used by the tests, has no row locks and serializes writers anyway.
+	if tx.DriverName() != "sqlite3" {
+		if _, err := tx.ExecContext(ctx, "SELECT id FROM products WHERE id = $1 FOR UPDATE", productID); err != nil {
+			return entity.RatingSummary{}, err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT rating, COUNT(*) FROM reviews WHERE product_id = $1 AND status = $2 GROUP BY rating",
+		productID, entity.ReviewApproved)
+	if err != nil {
+		return entity.RatingSummary{}, err
+	}
+
+	var histogram [5]int
+	for rows.Next() {
+		var rating, count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			rows.Close()
+			return entity.RatingSummary{}, err
+		}
+		if rating >= 1 && rating <= 5 {
+			histogram[rating-1] = count
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return entity.RatingSummary{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE products SET rating_1 = $1, rating_2 = $2, rating_3 = $3, rating_4 = $4, rating_5 = $5 WHERE id = $6",
+		histogram[0], histogram[1], histogram[2], histogram[3], histogram[4], productID)
+	if err != nil {
+		return entity.RatingSummary{}, err
+	}
+
+	return entity.NewRatingSummary(histogram), tx.Commit()
+}
+
+// RecordPurchase remembers that the user bought the product in a completed order
+func (r *ReviewRepository) RecordPurchase(ctx context.Context, userID, productID, orderID int64) error {
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO purchases (user_id, product_id, order_id, purchased_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+		userID, productID, orderID, time.Now().UTC())
+	return err
+}
+
+func (r *ReviewRepository) HasPurchased(ctx context.Context, userID, productID int64) (bool, error) {
+
+	var n int
+	err := r.db.GetContext(ctx, &n, "SELECT COUNT(*) FROM purchases WHERE user_id = $1 AND product_id = $2", userID, productID)
+	return n > 0, err
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+func migrateReviewDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE products (
+    id integer PRIMARY KEY,
+    rating_1 integer NOT NULL DEFAULT 0,
+    rating_2 integer NOT NULL DEFAULT 0,
+    rating_3 integer NOT NULL DEFAULT 0,
+    rating_4 integer NOT NULL DEFAULT 0,
+    rating_5 integer NOT NULL DEFAULT 0
+);
+CREATE TABLE reviews (
+    id integer PRIMARY KEY,
+    product_id integer NOT NULL,
+    user_id integer NOT NULL,
+    rating integer NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    verified_purchase boolean NOT NULL DEFAULT false,
+    helpful_count integer NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    moderated_at DATETIME,
+    UNIQUE (product_id, user_id)
+);
+CREATE TABLE review_votes (
+    review_id integer NOT NULL,
+    user_id integer NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (review_id, user_id)
+);
+CREATE TABLE purchases (
+    user_id integer NOT NULL,
+    product_id integer NOT NULL,
+    order_id integer NOT NULL,
+    purchased_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, product_id, order_id)
+);
+INSERT INTO products (id) VALUES (1);`)
+
+	return db, err
+}
+
+type ReviewRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestReviewRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReviewRepositoryTestSuite))
+}
+
+func (suite *ReviewRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *ReviewRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migrateReviewDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *ReviewRepositoryTestSuite) TestModerationAndRating() {
+
+	ctx := context.Background()
+	repo := NewReviewRepository(suite.DB)
+
+	for i, rating := range []int{5, 4, 5} {
+		rv, _ := entity.NewReview(1, int64(10+i), rating, "", "Great mug")
+		_, err := repo.Create(ctx, rv)
+		suite.Nil(err)
+		if i < 2 {
+			suite.Nil(repo.SetStatus(ctx, rv.ID, entity.ReviewApproved, time.Now()))
+		}
+	}
+
+	queue, err := repo.ListByStatus(ctx, entity.ReviewPending, 10, 0)
+	suite.Nil(err)
+	suite.Len(queue, 1)
+
+	summary, err := repo.RefreshRating(ctx, 1)
+	suite.Nil(err)
+	suite.Equal(2, summary.Count)
+	suite.Equal(4.5, summary.Average)
+	suite.Equal([5]int{0, 0, 0, 1, 1}, summary.Histogram)
+
+	var stored int
+	suite.Nil(suite.DB.Get(&stored, "SELECT rating_5 FROM products WHERE id = 1"))
+	suite.Equal(1, stored)
+
+	published, err := repo.ListByProduct(ctx, 1, ReviewSortRecent, 10, 0)
+	suite.Nil(err)
+	suite.Len(published, 2)
+}
+
+func (suite *ReviewRepositoryTestSuite) TestHelpfulVotesCountOnce() {
+
+	ctx := context.Background()
+	repo := NewReviewRepository(suite.DB)
+
+	rv, _ := entity.NewReview(2, 99, 3, "", "Fine")
+	_, err := repo.Create(ctx, rv)
+	suite.Nil(err)
+	suite.Nil(repo.SetStatus(ctx, rv.ID, entity.ReviewApproved, time.Now()))
+
+	added, err := repo.AddHelpfulVote(ctx, rv.ID, 7)
+	suite.Nil(err)
+	suite.True(added)
+	added, err = repo.AddHelpfulVote(ctx, rv.ID, 7)
+	suite.Nil(err)
+	suite.False(added)
+
+	rv2, err := repo.GetByID(ctx, rv.ID)
+	suite.Nil(err)
+	suite.Equal(1, rv2.HelpfulCount)
+}
+
+func (suite *ReviewRepositoryTestSuite) TestPurchases() {
+
+	ctx := context.Background()
+	repo := NewReviewRepository(suite.DB)
+
+	suite.Nil(repo.RecordPurchase(ctx, 5, 1, 100))
+	suite.Nil(repo.RecordPurchase(ctx, 5, 1, 100))
+
+	ok, err := repo.HasPurchased(ctx, 5, 1)
+	suite.Nil(err)
+	suite.True(ok)
+
+	ok, err = repo.HasPurchased(ctx, 6, 1)
+	suite.Nil(err)
+	suite.False(ok)
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+var errPriceChanged = errors.New("product price changed while applying a scheduled price")
+
+const scheduledPriceColumns = "id, product_id, price, currency, starts_at, ends_at, status, previous_price, previous_currency, created_at"
+
+// ScheduledPriceRepository stores scheduled price changes. Start and End claim a change by moving its
+// status in the same transaction that changes the product, so several service instances can run the
+// scheduler and each change is applied exactly once.
+type ScheduledPriceRepository struct {
+	db *sqlx.DB
+}
+
+func NewScheduledPriceRepository(db *sqlx.DB) *ScheduledPriceRepository {
+	return &ScheduledPriceRepository{db: db}
+}
+
+func scanScheduledPrice(row scanner) (*entity.ScheduledPrice, error) {
+
+	var sp entity.ScheduledPrice
+	var price, currency string
+	var previousPrice, previousCurrency sql.NullString
+
+	err := row.Scan(&sp.ID, &sp.ProductID, &price, &currency, &sp.StartsAt, &sp.EndsAt, &sp.Status,
+		&previousPrice, &previousCurrency, &sp.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if sp.Price, err = money.Parse(price, currency); err != nil {
+		return nil, err
+	}
+	if previousPrice.Valid {
+		prev, err := money.Parse(previousPrice.String, previousCurrency.String)
+		if err != nil {
+			return nil, err
+		}
+		sp.PreviousPrice = &prev
+	}
+
+	return &sp, nil
+}
+
+func (r *ScheduledPriceRepository) Create(ctx context.Context, sp *entity.ScheduledPrice) (int64, error) {
+
+	err := r.db.QueryRowContext(ctx, `INSERT INTO scheduled_prices (product_id, price, currency, starts_at, ends_at, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		sp.ProductID, sp.Price.Decimal(), sp.Price.Currency, sp.StartsAt, sp.EndsAt, sp.Status, sp.CreatedAt).Scan(&sp.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return sp.ID, nil
+}
+
+func (r *ScheduledPriceRepository) GetByID(ctx context.Context, id int64) (*entity.ScheduledPrice, error) {
+
+	sp, err := scanScheduledPrice(r.db.QueryRowContext(ctx, "SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sp, err
+}
+
+// ListByProduct returns the changes of a product, the next to start first
+func (r *ScheduledPriceRepository) ListByProduct(ctx context.Context, productID int64) ([]entity.ScheduledPrice, error) {
+
+	return r.list(ctx, "SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE product_id = $1 ORDER BY starts_at, id", productID)
+}
+
+// Cancel withdraws a change that has not started yet
+func (r *ScheduledPriceRepository) Cancel(ctx context.Context, id int64) error {
+
+	res, err := r.db.ExecContext(ctx, "UPDATE scheduled_prices SET status = $1 WHERE id = $2 AND status = $3",
+		entity.ScheduledCancelled, id, entity.ScheduledPending)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	sp, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sp == nil {
+		return entity.ErrScheduledPriceNotFound
+	}
+	return entity.ErrScheduledPriceNotPending
+}
+
+// HasOverlappingSale tells whether a pending or live sale of the product overlaps [startsAt, endsAt)
+func (r *ScheduledPriceRepository) HasOverlappingSale(ctx context.Context, productID int64, startsAt, endsAt time.Time) (bool, error) {
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM scheduled_prices
+		WHERE product_id = $1 AND status IN ($2, $3) AND ends_at IS NOT NULL AND starts_at < $4 AND ends_at > $5)`,
+		productID, entity.ScheduledPending, entity.ScheduledActive, endsAt, startsAt).Scan(&exists)
+	return exists, err
+}
+
+// DueToStart returns pending changes whose start has passed, oldest first
+func (r *ScheduledPriceRepository) DueToStart(ctx context.Context, now time.Time, limit int) ([]entity.ScheduledPrice, error) {
+
+	return r.list(ctx, "SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE status = $1 AND starts_at <= $2 ORDER BY starts_at, id LIMIT $3",
+		entity.ScheduledPending, now.UTC(), limit)
+}
+
+// DueToEnd returns live sales whose end has passed, oldest first
+func (r *ScheduledPriceRepository) DueToEnd(ctx context.Context, now time.Time, limit int) ([]entity.ScheduledPrice, error) {
+
+	return r.list(ctx, "SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE status = $1 AND ends_at <= $2 ORDER BY ends_at, id LIMIT $3",
+		entity.ScheduledActive, now.UTC(), limit)
+}
+
+// Start puts a pending change live. It returns a nil change when another instance already started it,
+// or when the change is a sale that ended before it could start, which is only marked as ended.
+func (r *ScheduledPriceRepository) Start(ctx context.Context, sp *entity.ScheduledPrice, now time.Time) (*entity.PriceChange, error) {
+
+	status := entity.ScheduledApplied
+	if sp.IsSale() {
+		status = entity.ScheduledActive
+		if !sp.EndsAt.After(now) {
+			status = entity.ScheduledEnded
+		}
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if claimed, err := claim(ctx, tx, sp.ID, entity.ScheduledPending, status); err != nil || !claimed {
+		return nil, err
+	}
+	if status == entity.ScheduledEnded {
+		return nil, tx.Commit()
+	}
+
+	old, err := basePrice(ctx, tx, sp.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a sale shows the price it replaced, unless that was in another currency
+	var compareAt *string
+	if sp.IsSale() && old.Currency == sp.Price.Currency {
+		v := old.Decimal()
+		compareAt = &v
+	}
+
+	// only if the price is still the one read, otherwise the claim rolls back and the next run retries
+	res, err := tx.ExecContext(ctx, "UPDATE products SET price = $1, currency = $2, compare_at_price = $3 WHERE id = $4 AND price = $5 AND currency = $6",
+		sp.Price.Decimal(), sp.Price.Currency, compareAt, sp.ProductID, old.Decimal(), old.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, errors.Join(errPriceChanged, err)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE scheduled_prices SET previous_price = $1, previous_currency = $2 WHERE id = $3",
+		old.Decimal(), old.Currency, sp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &entity.PriceChange{ProductID: sp.ProductID, Old: old, New: sp.Price}, nil
+}
+
+// End reverts a live sale to the price it replaced. When the price was changed during the sale the new
+// price is kept and only the compare-at price is cleared; the returned change is then nil, as it is when
+// another instance already ended the sale.
+func (r *ScheduledPriceRepository) End(ctx context.Context, sp *entity.ScheduledPrice) (*entity.PriceChange, error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if claimed, err := claim(ctx, tx, sp.ID, entity.ScheduledActive, entity.ScheduledEnded); err != nil || !claimed {
+		return nil, err
+	}
+
+	var change *entity.PriceChange
+	if sp.PreviousPrice != nil {
+		// reverts only while the sale price is still the current one
+		res, err := tx.ExecContext(ctx, "UPDATE products SET price = $1, currency = $2, compare_at_price = NULL WHERE id = $3 AND price = $4 AND currency = $5",
+			sp.PreviousPrice.Decimal(), sp.PreviousPrice.Currency, sp.ProductID, sp.Price.Decimal(), sp.Price.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n > 0 {
+			change = &entity.PriceChange{ProductID: sp.ProductID, Old: sp.Price, New: *sp.PreviousPrice}
+		}
+	}
+	if change == nil {
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET compare_at_price = NULL WHERE id = $1", sp.ProductID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// claim moves a change from one status to another, reporting false when its status was no longer from
+func claim(ctx context.Context, tx *sqlx.Tx, id int64, from, to entity.ScheduledPriceStatus) (bool, error) {
+
+	res, err := tx.ExecContext(ctx, "UPDATE scheduled_prices SET status = $1 WHERE id = $2 AND status = $3", to, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func basePrice(ctx context.Context, tx *sqlx.Tx, productID int64) (money.Money, error) {
+
+	var price, currency string
+	err := tx.QueryRowContext(ctx, "SELECT price, currency FROM products WHERE id = $1", productID).Scan(&price, &currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return money.Money{}, entity.ErrProductNotFound
+	}
+	if err != nil {
+		return money.Money{}, err
+	}
+	return money.Parse(price, currency)
+}
+
+func (r *ScheduledPriceRepository) list(ctx context.Context, query string, args ...any) ([]entity.ScheduledPrice, error) {
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.ScheduledPrice
+	for rows.Next() {
+		sp, err := scanScheduledPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *sp)
+	}
+	return list, rows.Err()
+}
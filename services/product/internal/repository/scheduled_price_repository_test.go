@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+	"github.com/stretchr/testify/suite"
+)
+
+func migrateScheduledPriceDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE products (
+    id integer PRIMARY KEY,
+    price NUMERIC,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    compare_at_price NUMERIC
+);
+CREATE TABLE scheduled_prices (
+    id integer PRIMARY KEY,
+    product_id integer NOT NULL,
+    price NUMERIC NOT NULL,
+    currency CHAR(3) NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    previous_price NUMERIC,
+    previous_currency CHAR(3),
+    created_at DATETIME NOT NULL
+);
+INSERT INTO products (id, price, currency) VALUES (1, 20, 'USD'), (2, 50, 'USD');`)
+
+	return db, err
+}
+
+type ScheduledPriceRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestScheduledPriceRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ScheduledPriceRepositoryTestSuite))
+}
+
+func (suite *ScheduledPriceRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *ScheduledPriceRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migrateScheduledPriceDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *ScheduledPriceRepositoryTestSuite) productPrice(id int64) (string, *string) {
+
+	var price string
+	var compareAt *string
+	suite.Nil(suite.DB.QueryRow("SELECT price, compare_at_price FROM products WHERE id = $1", id).Scan(&price, &compareAt))
+	return price, compareAt
+}
+
+func (suite *ScheduledPriceRepositoryTestSuite) TestSaleStartsAndEnds() {
+
+	ctx := context.Background()
+	repo := NewScheduledPriceRepository(suite.DB)
+
+	now := time.Now().UTC()
+	end := now.Add(time.Hour)
+	sp, err := entity.NewScheduledPrice(1, money.Money{Amount: 1500, Currency: "USD"}, now.Add(-time.Minute), &end, now)
+	suite.Nil(err)
+	_, err = repo.Create(ctx, sp)
+	suite.Nil(err)
+
+	due, err := repo.DueToStart(ctx, now, 10)
+	suite.Nil(err)
+	suite.Len(due, 1)
+
+	change, err := repo.Start(ctx, &due[0], now)
+	suite.Nil(err)
+	suite.Equal(&entity.PriceChange{ProductID: 1, Old: money.Money{Amount: 2000, Currency: "USD"}, New: sp.Price}, change)
+
+	price, compareAt := suite.productPrice(1)
+	suite.Equal("15", price)
+	suite.Equal("20", *compareAt)
+
+	// a second instance that read the same row finds it claimed
+	change, err = repo.Start(ctx, &due[0], now)
+	suite.Nil(err)
+	suite.Nil(change)
+
+	ending, err := repo.DueToEnd(ctx, end, 10)
+	suite.Nil(err)
+	suite.Len(ending, 1)
+	suite.Equal(entity.ScheduledActive, ending[0].Status)
+
+	change, err = repo.End(ctx, &ending[0])
+	suite.Nil(err)
+	suite.Equal(money.Money{Amount: 2000, Currency: "USD"}, change.New)
+
+	price, compareAt = suite.productPrice(1)
+	suite.Equal("20", price)
+	suite.Nil(compareAt)
+
+	stored, err := repo.GetByID(ctx, sp.ID)
+	suite.Nil(err)
+	suite.Equal(entity.ScheduledEnded, stored.Status)
+}
+
+func (suite *ScheduledPriceRepositoryTestSuite) TestCancel() {
+
+	ctx := context.Background()
+	repo := NewScheduledPriceRepository(suite.DB)
+
+	now := time.Now().UTC()
+	sp, _ := entity.NewScheduledPrice(2, money.Money{Amount: 4500, Currency: "USD"}, now.Add(time.Hour), nil, now)
+	_, err := repo.Create(ctx, sp)
+	suite.Nil(err)
+
+	suite.Nil(repo.Cancel(ctx, sp.ID))
+	suite.Equal(entity.ErrScheduledPriceNotPending, repo.Cancel(ctx, sp.ID))
+	suite.Equal(entity.ErrScheduledPriceNotFound, repo.Cancel(ctx, 999))
+
+	list, err := repo.ListByProduct(ctx, 2)
+	suite.Nil(err)
+	suite.Len(list, 1)
+	suite.Equal(entity.ScheduledCancelled, list[0].Status)
+}
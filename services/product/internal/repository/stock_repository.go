@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+type StockRepository struct {
+	db *sqlx.DB
+}
+
+func NewStockRepository(db *sqlx.DB) *StockRepository {
+	return &StockRepository{db: db}
+}
+
+// Get returns a zero level for products that never had stock
+func (r *StockRepository) Get(ctx context.Context, productID int64) (*entity.StockLevel, error) {
+
+	var s entity.StockLevel
+	err := r.db.GetContext(ctx, &s, "SELECT product_id, on_hand, reserved, updated_at FROM stock_levels WHERE product_id = $1", productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &entity.StockLevel{ProductID: productID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Adjust adds delta to the quantity on hand and returns the new level. It fails with
+// entity.ErrInsufficientStock, leaving the level untouched, when the result would be less than reserved.
+func (r *StockRepository) Adjust(ctx context.Context, productID, delta int64) (*entity.StockLevel, error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	_, err = tx.ExecContext(ctx, "INSERT INTO stock_levels (product_id, on_hand, updated_at) VALUES ($1, 0, $2) ON CONFLICT (product_id) DO NOTHING",
+		productID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s := entity.StockLevel{ProductID: productID, UpdatedAt: now}
+	err = tx.QueryRowContext(ctx, "UPDATE stock_levels SET on_hand = on_hand + $1, updated_at = $2 WHERE product_id = $3 AND on_hand + $1 >= reserved RETURNING on_hand, reserved",
+		delta, now, productID).Scan(&s.OnHand, &s.Reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrInsufficientStock
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, tx.Commit()
+}
+
+// AvailableMany returns the quantity available of each product, zero for products that never had stock
+func (r *StockRepository) AvailableMany(ctx context.Context, productIDs []int64) (map[int64]int64, error) {
+
+	available := make(map[int64]int64, len(productIDs))
+	if len(productIDs) == 0 {
+		return available, nil
+	}
+
+	query, args, err := sqlx.In("SELECT product_id, on_hand - reserved FROM stock_levels WHERE product_id IN (?)", productIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, n int64
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		available[id] = n
+	}
+	return available, rows.Err()
+}
+
+// Reserve holds the items for reference, all or none: it fails with entity.ErrInsufficientStock when
+// one of them is not available. Reserving a reference that already holds stock does nothing, so callers
+// can retry.
+func (r *StockRepository) Reserve(ctx context.Context, reference string, items []entity.StockItem) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM stock_reservations WHERE reference = $1)", reference).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	// a fixed lock order keeps concurrent reservations of the same products from deadlocking
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b entity.StockItem) int { return cmp.Compare(a.ProductID, b.ProductID) })
+
+	now := time.Now().UTC()
+	for _, it := range items {
+		res, err := tx.ExecContext(ctx, "UPDATE stock_levels SET reserved = reserved + $1, updated_at = $2 WHERE product_id = $3 AND on_hand - reserved >= $1",
+			it.Quantity, now, it.ProductID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return entity.ErrInsufficientStock
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO stock_reservations (reference, product_id, quantity, created_at) VALUES ($1, $2, $3, $4)",
+			reference, it.ProductID, it.Quantity, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Release returns the stock held for reference; releasing an unknown or released reference does nothing
+func (r *StockRepository) Release(ctx context.Context, reference string) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var items []entity.StockItem
+	rows, err := tx.QueryContext(ctx, "DELETE FROM stock_reservations WHERE reference = $1 RETURNING product_id, quantity", reference)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var it entity.StockItem
+		if err := rows.Scan(&it.ProductID, &it.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, it := range items {
+		_, err := tx.ExecContext(ctx, "UPDATE stock_levels SET reserved = reserved - $1, updated_at = $2 WHERE product_id = $3",
+			it.Quantity, now, it.ProductID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReleaseItems returns part of the stock held for reference, at most what it holds of each product.
+// A release is done once: repeating releaseID for reference does nothing.
+func (r *StockRepository) ReleaseItems(ctx context.Context, reference, releaseID string, items []entity.StockItem) error {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "INSERT INTO stock_releases (reference, release_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		reference, releaseID, now)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return nil
+	}
+
+	for _, it := range items {
+		var held int64
+		err := tx.QueryRowContext(ctx, "SELECT quantity FROM stock_reservations WHERE reference = $1 AND product_id = $2", reference, it.ProductID).Scan(&held)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		qty := min(it.Quantity, held)
+		if qty == held {
+			_, err = tx.ExecContext(ctx, "DELETE FROM stock_reservations WHERE reference = $1 AND product_id = $2", reference, it.ProductID)
+		} else {
+			_, err = tx.ExecContext(ctx, "UPDATE stock_reservations SET quantity = quantity - $1 WHERE reference = $2 AND product_id = $3",
+				qty, reference, it.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE stock_levels SET reserved = reserved - $1, updated_at = $2 WHERE product_id = $3",
+			qty, now, it.ProductID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Commit takes the stock held for reference out of stock, once its goods left: on_hand and reserved both
+// drop by what it holds. It returns how on_hand moved, nothing for an unknown or committed reference.
+func (r *StockRepository) Commit(ctx context.Context, reference string) ([]entity.StockMove, error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var items []entity.StockItem
+	rows, err := tx.QueryContext(ctx, "DELETE FROM stock_reservations WHERE reference = $1 RETURNING product_id, quantity", reference)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var it entity.StockItem
+		if err := rows.Scan(&it.ProductID, &it.Quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	moves := make([]entity.StockMove, len(items))
+	for i, it := range items {
+		m := entity.StockMove{ProductID: it.ProductID}
+		err := tx.QueryRowContext(ctx, "UPDATE stock_levels SET on_hand = on_hand - $1, reserved = reserved - $1, updated_at = $2 WHERE product_id = $3 RETURNING on_hand",
+			it.Quantity, now, it.ProductID).Scan(&m.After)
+		if err != nil {
+			return nil, err
+		}
+		m.Before = m.After + it.Quantity
+		moves[i] = m
+	}
+
+	return moves, tx.Commit()
+}
+
+// Restock adds the items to the quantity on hand, once per reference: repeating a reference does nothing.
+// It returns how on_hand moved, nothing for a repeated reference.
+func (r *StockRepository) Restock(ctx context.Context, reference string, items []entity.StockItem) ([]entity.StockMove, error) {
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx, "INSERT INTO stock_restocks (reference, created_at) VALUES ($1, $2) ON CONFLICT DO NOTHING", reference, now)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, nil
+	}
+
+	moves := make([]entity.StockMove, len(items))
+	for i, it := range items {
+		m := entity.StockMove{ProductID: it.ProductID}
+		err := tx.QueryRowContext(ctx, `INSERT INTO stock_levels (product_id, on_hand, updated_at) VALUES ($1, $2, $3)
+			ON CONFLICT (product_id) DO UPDATE SET on_hand = stock_levels.on_hand + excluded.on_hand, updated_at = excluded.updated_at
+			RETURNING on_hand`,
+			it.ProductID, it.Quantity, now).Scan(&m.After)
+		if err != nil {
+			return nil, err
+		}
+		m.Before = m.After - it.Quantity
+		moves[i] = m
+	}
+
+	return moves, tx.Commit()
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/stretchr/testify/suite"
+)
+
+func migrateStockDB() (*sqlx.DB, error) {
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE stock_levels (
+    product_id integer PRIMARY KEY,
+    on_hand integer NOT NULL DEFAULT 0 CHECK (on_hand >= 0),
+    reserved integer NOT NULL DEFAULT 0 CHECK (reserved >= 0 AND reserved <= on_hand),
+    updated_at DATETIME NOT NULL
+);
+CREATE TABLE stock_reservations (
+    reference VARCHAR(255) NOT NULL,
+    product_id integer NOT NULL,
+    quantity integer NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (reference, product_id)
+);
+CREATE TABLE stock_restocks (
+    reference VARCHAR(255) PRIMARY KEY,
+    created_at DATETIME NOT NULL
+);
+CREATE TABLE stock_releases (
+    reference VARCHAR(255) NOT NULL,
+    release_id VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (reference, release_id)
+);`)
+
+	return db, err
+}
+
+type StockRepositoryTestSuite struct {
+	DB *sqlx.DB
+	suite.Suite
+}
+
+func TestStockRepositorySuite(t *testing.T) {
+	suite.Run(t, new(StockRepositoryTestSuite))
+}
+
+func (suite *StockRepositoryTestSuite) TearDownSuite() {
+	suite.DB.Close()
+}
+
+func (suite *StockRepositoryTestSuite) SetupSuite() {
+	dbConn, err := migrateStockDB()
+	suite.NoError(err)
+	suite.DB = dbConn
+}
+
+func (suite *StockRepositoryTestSuite) TestAdjust() {
+
+	ctx := context.Background()
+	repo := NewStockRepository(suite.DB)
+
+	s, err := repo.Get(ctx, 1)
+	suite.Nil(err)
+	suite.Equal(int64(0), s.OnHand)
+
+	s, err = repo.Adjust(ctx, 1, 5)
+	suite.Nil(err)
+	suite.Equal(int64(5), s.OnHand)
+
+	s, err = repo.Adjust(ctx, 1, -2)
+	suite.Nil(err)
+	suite.Equal(int64(3), s.OnHand)
+
+	_, err = repo.Adjust(ctx, 1, -4)
+	suite.Equal(entity.ErrInsufficientStock, err)
+
+	s, err = repo.Get(ctx, 1)
+	suite.Nil(err)
+	suite.Equal(int64(3), s.OnHand)
+}
+
+func (suite *StockRepositoryTestSuite) TestReserveAndRelease() {
+
+	ctx := context.Background()
+	repo := NewStockRepository(suite.DB)
+
+	_, err := repo.Adjust(ctx, 10, 5)
+	suite.Nil(err)
+	_, err = repo.Adjust(ctx, 11, 1)
+	suite.Nil(err)
+
+	suite.Nil(repo.Reserve(ctx, "order-1", []entity.StockItem{{ProductID: 10, Quantity: 4}, {ProductID: 11, Quantity: 1}}))
+	// a retry holds nothing more
+	suite.Nil(repo.Reserve(ctx, "order-1", []entity.StockItem{{ProductID: 10, Quantity: 4}, {ProductID: 11, Quantity: 1}}))
+
+	available, err := repo.AvailableMany(ctx, []int64{10, 11, 12})
+	suite.Nil(err)
+	suite.Equal(map[int64]int64{10: 1, 11: 0}, available)
+
+	// all or none: product 10 has enough, 11 does not
+	suite.Equal(entity.ErrInsufficientStock, repo.Reserve(ctx, "order-2", []entity.StockItem{{ProductID: 10, Quantity: 1}, {ProductID: 11, Quantity: 1}}))
+	s, _ := repo.Get(ctx, 10)
+	suite.Equal(int64(4), s.Reserved)
+
+	// reserved units cannot be adjusted away
+	_, err = repo.Adjust(ctx, 10, -2)
+	suite.Equal(entity.ErrInsufficientStock, err)
+
+	suite.Nil(repo.Release(ctx, "order-1"))
+	suite.Nil(repo.Release(ctx, "order-1"))
+	s, _ = repo.Get(ctx, 10)
+	suite.Equal(int64(0), s.Reserved)
+	suite.Equal(int64(5), s.Available())
+}
+
+func (suite *StockRepositoryTestSuite) TestReleaseItems() {
+
+	ctx := context.Background()
+	repo := NewStockRepository(suite.DB)
+
+	_, err := repo.Adjust(ctx, 20, 5)
+	suite.Nil(err)
+	_, err = repo.Adjust(ctx, 21, 2)
+	suite.Nil(err)
+	suite.Nil(repo.Reserve(ctx, "order-3", []entity.StockItem{{ProductID: 20, Quantity: 4}, {ProductID: 21, Quantity: 2}}))
+
+	items := []entity.StockItem{{ProductID: 20, Quantity: 1}, {ProductID: 21, Quantity: 2}}
+	suite.Nil(repo.ReleaseItems(ctx, "order-3", "cancellation-1", items))
+	// a retry releases nothing more
+	suite.Nil(repo.ReleaseItems(ctx, "order-3", "cancellation-1", items))
+
+	available, err := repo.AvailableMany(ctx, []int64{20, 21})
+	suite.Nil(err)
+	suite.Equal(map[int64]int64{20: 2, 21: 2}, available)
+
+	// no more than the reference holds
+	suite.Nil(repo.ReleaseItems(ctx, "order-3", "cancellation-2", []entity.StockItem{{ProductID: 20, Quantity: 9}}))
+	s, _ := repo.Get(ctx, 20)
+	suite.Equal(int64(0), s.Reserved)
+
+	suite.Nil(repo.Release(ctx, "order-3"))
+	s, _ = repo.Get(ctx, 20)
+	suite.Equal(int64(0), s.Reserved)
+}
+
+func (suite *StockRepositoryTestSuite) TestCommit() {
+
+	ctx := context.Background()
+	repo := NewStockRepository(suite.DB)
+
+	_, err := repo.Adjust(ctx, 30, 5)
+	suite.Nil(err)
+	suite.Nil(repo.Reserve(ctx, "order-4", []entity.StockItem{{ProductID: 30, Quantity: 3}}))
+	suite.Nil(repo.ReleaseItems(ctx, "order-4", "cancellation-1", []entity.StockItem{{ProductID: 30, Quantity: 1}}))
+
+	// what is left of the reservation ships
+	moves, err := repo.Commit(ctx, "order-4")
+	suite.Nil(err)
+	suite.Equal([]entity.StockMove{{ProductID: 30, Before: 5, After: 3}}, moves)
+	moves, err = repo.Commit(ctx, "order-4")
+	suite.Nil(err)
+	suite.Empty(moves)
+	s, _ := repo.Get(ctx, 30)
+	suite.Equal(int64(3), s.OnHand)
+	suite.Equal(int64(0), s.Reserved)
+
+	// nothing is held anymore
+	suite.Nil(repo.Release(ctx, "order-4"))
+	s, _ = repo.Get(ctx, 30)
+	suite.Equal(int64(3), s.Available())
+
+	// and the stock counted can go down again
+	_, err = repo.Adjust(ctx, 30, -3)
+	suite.Nil(err)
+}
+
+func (suite *StockRepositoryTestSuite) TestRestock() {
+
+	ctx := context.Background()
+	repo := NewStockRepository(suite.DB)
+
+	_, err := repo.Adjust(ctx, 40, 2)
+	suite.Nil(err)
+
+	items := []entity.StockItem{{ProductID: 40, Quantity: 1}, {ProductID: 41, Quantity: 2}}
+	moves, err := repo.Restock(ctx, "return-1", items)
+	suite.Nil(err)
+	suite.Equal([]entity.StockMove{{ProductID: 40, Before: 2, After: 3}, {ProductID: 41, Before: 0, After: 2}}, moves)
+	// a retry adds nothing more
+	moves, err = repo.Restock(ctx, "return-1", items)
+	suite.Nil(err)
+	suite.Empty(moves)
+
+	available, err := repo.AvailableMany(ctx, []int64{40, 41})
+	suite.Nil(err)
+	suite.Equal(map[int64]int64{40: 3, 41: 2}, available)
+}
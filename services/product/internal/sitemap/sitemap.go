@@ -0,0 +1,82 @@
+// Package sitemap writes XML sitemaps and sitemap indexes as described at https://www.sitemaps.org/protocol.html
+package sitemap
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// MaxURLs is the most URLs one sitemap, or sitemaps one index, may list
+const MaxURLs = 50_000
+
+var ErrTooManyURLs = errors.New("sitemap is full")
+
+const (
+	header    = xml.Header + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n"
+	footer    = "</urlset>\n"
+	indexHead = xml.Header + `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n"
+	indexFoot = "</sitemapindex>\n"
+)
+
+// URLSet streams the URLs of one sitemap to w; Close ends the document
+type URLSet struct {
+	w *bufio.Writer
+	n int
+}
+
+func NewURLSet(w io.Writer) (*URLSet, error) {
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(header); err != nil {
+		return nil, err
+	}
+	return &URLSet{w: bw}, nil
+}
+
+// Add lists loc, an absolute URL, and fails with ErrTooManyURLs past MaxURLs
+func (s *URLSet) Add(loc string) error {
+
+	if s.n == MaxURLs {
+		return ErrTooManyURLs
+	}
+	s.n++
+	return writeLoc(s.w, "url", loc)
+}
+
+func (s *URLSet) Close() error {
+
+	if _, err := s.w.WriteString(footer); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// WriteIndex writes a sitemap index listing the sitemaps at locs
+func WriteIndex(w io.Writer, locs []string) error {
+
+	if len(locs) > MaxURLs {
+		return ErrTooManyURLs
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(indexHead)
+	for _, loc := range locs {
+		if err := writeLoc(bw, "sitemap", loc); err != nil {
+			return err
+		}
+	}
+	bw.WriteString(indexFoot)
+	return bw.Flush()
+}
+
+func writeLoc(w *bufio.Writer, element, loc string) error {
+
+	w.WriteString("  <" + element + "><loc>")
+	if err := xml.EscapeText(w, []byte(loc)); err != nil {
+		return err
+	}
+	_, err := w.WriteString("</loc></" + element + ">\n")
+	return err
+}
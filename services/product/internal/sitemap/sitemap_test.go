@@ -0,0 +1,48 @@
+package sitemap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLSet(t *testing.T) {
+
+	var buf bytes.Buffer
+	s, err := NewURLSet(&buf)
+	assert.Nil(t, err)
+
+	assert.Nil(t, s.Add("https://shop.example.com/products/blue-mug"))
+	assert.Nil(t, s.Add("https://shop.example.com/categories/tea%20&%20coffee"))
+	assert.Nil(t, s.Close())
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://shop.example.com/products/blue-mug</loc></url>
+  <url><loc>https://shop.example.com/categories/tea%20&amp;%20coffee</loc></url>
+</urlset>
+`, buf.String())
+}
+
+func TestURLSetIsFull(t *testing.T) {
+
+	s, _ := NewURLSet(io.Discard)
+	for range MaxURLs {
+		assert.Nil(t, s.Add("https://shop.example.com/"))
+	}
+	assert.Equal(t, ErrTooManyURLs, s.Add("https://shop.example.com/"))
+}
+
+func TestWriteIndex(t *testing.T) {
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteIndex(&buf, []string{"https://api.example.com/sitemaps/products-1.xml"}))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://api.example.com/sitemaps/products-1.xml</loc></sitemap>
+</sitemapindex>
+`, buf.String())
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+
+	ctx := context.Background()
+	s, err := NewLocalStore(t.TempDir(), "http://localhost/media/")
+	assert.Nil(t, err)
+
+	assert.Nil(t, s.Put(ctx, "products/1/a.txt", strings.NewReader("hello"), "text/plain"))
+
+	rc, err := s.Get(ctx, "products/1/a.txt")
+	assert.Nil(t, err)
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "http://localhost/media/products/1/a.txt", s.URL("products/1/a.txt"))
+
+	assert.Nil(t, s.Delete(ctx, "products/1/a.txt"))
+	assert.Nil(t, s.Delete(ctx, "products/1/a.txt"))
+
+	_, err = s.Get(ctx, "products/1/a.txt")
+	assert.Equal(t, ErrBlobNotFound, err)
+}
+
+func TestLocalStoreWhenKeyEscapesRoot(t *testing.T) {
+
+	s, _ := NewLocalStore(t.TempDir(), "")
+
+	err := s.Put(context.Background(), "../outside.txt", strings.NewReader("x"), "text/plain")
+
+	assert.Equal(t, ErrInvalidKey, err)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+)
+
+type AttributeDefinitionInput struct {
+	Category string
+	Code     string
+	Label    string
+	Type     entity.AttributeType
+	Unit     string
+	Options  []string
+	Required bool
+}
+
+// CreateAttributeDefinition adds a field to the specification schema of a category. Products already in the
+// category are not checked again, so a new required attribute applies to them on their next update.
+func (uc *ProductUseCase) CreateAttributeDefinition(ctx context.Context, input AttributeDefinitionInput) (*entity.AttributeDefinition, error) {
+
+	d, err := entity.NewAttributeDefinition(input.Category, input.Code, input.Label, input.Type, input.Unit, input.Options, input.Required)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.attributes.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (uc *ProductUseCase) ListAttributeDefinitions(ctx context.Context, category string) ([]entity.AttributeDefinition, error) {
+	return uc.attributes.ListByCategory(ctx, category)
+}
+
+// validateAttributes checks the attribute values of p against the schema of its category
+func (uc *ProductUseCase) validateAttributes(ctx context.Context, p *entity.Product) error {
+
+	defs, err := uc.attributes.ListByCategory(ctx, p.Category)
+	if err != nil {
+		return err
+	}
+	return p.Attributes.Validate(defs)
+}
+
+// resolveFilters types the filter values with the schema of category, which filters require
+func (uc *ProductUseCase) resolveFilters(ctx context.Context, category string, filters []entity.AttributeFilter) ([]entity.AttributeFilter, error) {
+
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	if category == "" {
+		return nil, entity.ErrFilterCategoryRequired
+	}
+
+	defs, err := uc.attributes.ListByCategory(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return entity.ResolveAttributeFilters(defs, filters)
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/product/internal/kafka"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// BundleInput describes the composition of a bundle; components are given by SKU
+type BundleInput struct {
+	Pricing    entity.BundlePricing
+	PercentOff int64
+	Components []entity.BundleComponent
+}
+
+// createBundle stores a bundle product. A percent off bundle stores the price its components
+// make today, in the currency of the given price, for listings; ResolvePrice always recomputes it.
+func (uc *ProductUseCase) createBundle(ctx context.Context, p *entity.Product, input BundleInput) (int64, error) {
+
+	b, err := uc.newBundle(ctx, 0, input)
+	if err != nil {
+		return 0, err
+	}
+	if b.Pricing == entity.BundlePercentOff {
+		if p.Price, err = uc.bundlePrice(ctx, b, entity.PriceContext{Currency: p.Price.Currency}); err != nil {
+			return 0, err
+		}
+	}
+
+	id, err := uc.bundles.Create(ctx, p, b)
+	if err != nil {
+		return 0, err
+	}
+	uc.invalidate(ctx, id)
+	uc.publish(ctx, producer.ProductCreated(p))
+
+	return id, nil
+}
+
+// SetBundle replaces the pricing and the components of a bundle
+func (uc *ProductUseCase) SetBundle(ctx context.Context, productID int64, input BundleInput) (*entity.Bundle, error) {
+
+	p, err := uc.getProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Kind != entity.ProductBundle {
+		return nil, entity.ErrNotABundle
+	}
+
+	b, err := uc.newBundle(ctx, productID, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.bundles.Replace(ctx, b); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, productID)
+
+	return b, nil
+}
+
+func (uc *ProductUseCase) GetBundle(ctx context.Context, productID int64) (*entity.Bundle, error) {
+
+	b, err := uc.bundles.Get(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, entity.ErrNotABundle
+	}
+	return b, nil
+}
+
+// newBundle validates input and looks up its components, which must be existing simple products
+func (uc *ProductUseCase) newBundle(ctx context.Context, productID int64, input BundleInput) (*entity.Bundle, error) {
+
+	b, err := entity.NewBundle(productID, input.Pricing, input.PercentOff, input.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make([]string, len(b.Components))
+	for i, c := range b.Components {
+		skus[i] = c.SKU
+	}
+	found, err := uc.repo.GetBySKUs(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+	bySKU := make(map[string]*entity.Product, len(found))
+	for i := range found {
+		bySKU[found[i].SKU] = &found[i]
+	}
+
+	for i, c := range b.Components {
+		p := bySKU[c.SKU]
+		if p == nil {
+			return nil, entity.ErrComponentNotFound
+		}
+		if p.Kind == entity.ProductBundle {
+			return nil, entity.ErrNestedBundle
+		}
+		b.Components[i].ProductID = p.ID
+	}
+	return b, nil
+}
+
+// bundlePrice sums the prices of the components for pc and takes the bundle discount off
+func (uc *ProductUseCase) bundlePrice(ctx context.Context, b *entity.Bundle, pc entity.PriceContext) (money.Money, error) {
+
+	prices := make([]money.Money, len(b.Components))
+	for i, c := range b.Components {
+		price, err := uc.GetPrice(ctx, c.ProductID, pc)
+		if err != nil {
+			return money.Money{}, err
+		}
+		prices[i] = price
+	}
+	return b.Price(prices)
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/bulk"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/product/internal/kafka"
+	"github.com/raulsilva-tech/e-commerce/services/product/pb/events"
+)
+
+const (
+	importBatchSize = 500
+	exportPageSize  = 500
+)
+
+type ImportRowError struct {
+	Line    int
+	SKU     string
+	Message string
+}
+
+type ImportReport struct {
+	DryRun  bool
+	Created int
+	Updated int
+	Failed  int
+	Errors  []ImportRowError
+}
+
+// ImportProducts upserts products by SKU in batches. Invalid rows are reported and skipped; with dryRun
+// nothing is written and Created/Updated tell what would have happened.
+func (uc *ProductUseCase) ImportProducts(ctx context.Context, src bulk.Source, dryRun bool) (*ImportReport, error) {
+
+	report := &ImportReport{DryRun: dryRun}
+	seen := make(map[string]int)
+	// attribute schemas by category, loaded once per import
+	schemas := make(map[string][]entity.AttributeDefinition)
+	var batch []bulk.Record
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		defer func() { batch = batch[:0] }()
+		return uc.importBatch(ctx, batch, report)
+	}
+
+	for {
+		rec, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if rec.Err != nil {
+			report.fail(rec, rec.Err.Error())
+			continue
+		}
+		if line, ok := seen[rec.Product.SKU]; ok {
+			report.fail(rec, fmt.Sprintf("duplicate sku, first seen on line %d", line))
+			continue
+		}
+		seen[rec.Product.SKU] = rec.Line
+
+		defs, ok := schemas[rec.Product.Category]
+		if !ok {
+			if defs, err = uc.attributes.ListByCategory(ctx, rec.Product.Category); err != nil {
+				return nil, err
+			}
+			schemas[rec.Product.Category] = defs
+		}
+		if err := rec.Product.Attributes.Validate(defs); err != nil {
+			report.fail(rec, err.Error())
+			continue
+		}
+
+		batch = append(batch, rec)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (uc *ProductUseCase) importBatch(ctx context.Context, batch []bulk.Record, report *ImportReport) error {
+
+	products := make([]*entity.Product, len(batch))
+	skus := make([]string, len(batch))
+	for i, rec := range batch {
+		products[i] = rec.Product
+		skus[i] = rec.Product.SKU
+	}
+
+	if report.DryRun {
+		existing, err := uc.repo.ExistingSKUs(ctx, skus)
+		if err != nil {
+			return err
+		}
+		report.Updated += len(existing)
+		report.Created += len(batch) - len(existing)
+		return nil
+	}
+
+	// only used to tell created from updated products in the events
+	existing, err := uc.repo.ExistingSKUs(ctx, skus)
+	if err != nil {
+		return err
+	}
+
+	created, updated, err := uc.repo.UpsertBatch(ctx, products)
+	if err != nil {
+		// the batch is a single statement, so every row in it failed together
+		for _, rec := range batch {
+			report.fail(rec, err.Error())
+		}
+		return nil
+	}
+
+	evs := make([]*events.ProductEvent, len(products))
+	for i, p := range products {
+		uc.invalidate(ctx, p.ID)
+		if existing[p.SKU] {
+			evs[i] = producer.ProductUpdated(p, nil)
+		} else {
+			evs[i] = producer.ProductCreated(p)
+		}
+	}
+	uc.publishBatch(ctx, evs)
+
+	report.Created += created
+	report.Updated += updated
+	return nil
+}
+
+func (r *ImportReport) fail(rec bulk.Record, message string) {
+
+	r.Failed++
+	r.Errors = append(r.Errors, ImportRowError{Line: rec.Line, SKU: rec.SKU, Message: message})
+}
+
+// ExportProducts walks every product in id order and hands it to fn
+func (uc *ProductUseCase) ExportProducts(ctx context.Context, fn func(entity.Product) error) error {
+
+	var afterID int64
+	for {
+		page, err := uc.repo.GetPage(ctx, afterID, exportPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range page {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < exportPageSize {
+			return nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
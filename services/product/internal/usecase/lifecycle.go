@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/product/internal/kafka"
+)
+
+type SetStatusInput struct {
+	Status entity.ProductStatus
+	// PublishedAt schedules the publication of an active product, now when nil
+	PublishedAt *time.Time
+	// ChangedBy is the user id of the staff member, recorded in the status history
+	ChangedBy int64
+}
+
+// SetProductStatus publishes, unpublishes or archives a product and records who did it
+func (uc *ProductUseCase) SetProductStatus(ctx context.Context, productID int64, input SetStatusInput) (*entity.Product, error) {
+
+	p, err := uc.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrProductNotFound
+	}
+
+	c, err := entity.NewStatusChange(p, input.Status, input.PublishedAt, input.ChangedBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.SetStatus(ctx, c); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, productID)
+
+	p.Status, p.PublishedAt = c.To, c.PublishedAt
+	uc.publish(ctx, producer.ProductUpdated(p, []string{"status"}))
+
+	return p, nil
+}
+
+func (uc *ProductUseCase) ListStatusChanges(ctx context.Context, productID int64) ([]entity.StatusChange, error) {
+
+	if err := uc.requireProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListStatusChanges(ctx, productID)
+}
+
+// GetProduct returns entity.ErrProductNotFound for products that do not exist, and unless
+// includeUnpublished, for products customers do not see
+func (uc *ProductUseCase) GetProduct(ctx context.Context, id int64, includeUnpublished bool) (*entity.Product, error) {
+
+	p, err := uc.getProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !includeUnpublished && !p.IsVisible(time.Now()) {
+		return nil, entity.ErrProductNotFound
+	}
+	return p, nil
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/media"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/repository"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/storage"
+)
+
+// MaxMediaSize caps the size of an uploaded image
+const MaxMediaSize = 20 << 20
+
+var ErrMediaTooLarge = errors.New("media file is too large")
+
+type UploadMediaInput struct {
+	ProductID  int64
+	VariantSKU string
+	AltText    string
+	Data       []byte
+}
+
+type MediaUseCase struct {
+	repo     *repository.MediaRepository
+	products *repository.ProductRepository
+	store    storage.BlobStore
+}
+
+func NewMediaUseCase(r *repository.MediaRepository, p *repository.ProductRepository, s storage.BlobStore) *MediaUseCase {
+	return &MediaUseCase{repo: r, products: p, store: s}
+}
+
+// Upload stores the original image and its renditions, then records the media after the existing ones
+func (uc *MediaUseCase) Upload(ctx context.Context, input UploadMediaInput) (*entity.ProductMedia, error) {
+
+	if len(input.Data) > MaxMediaSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	p, err := uc.products.GetByID(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrProductNotFound
+	}
+
+	original, err := media.Inspect(input.Data)
+	if err != nil {
+		return nil, err
+	}
+	renditions, err := media.Generate(input.Data, original)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := mediaPrefix(input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &entity.ProductMedia{
+		ProductID:   input.ProductID,
+		VariantSKU:  input.VariantSKU,
+		AltText:     input.AltText,
+		ContentType: original.ContentType,
+		Width:       original.Width,
+		Height:      original.Height,
+		OriginalKey: prefix + "/original." + original.Format,
+		CreatedAt:   time.Now(),
+	}
+
+	keys := []string{m.OriginalKey}
+	if err := uc.store.Put(ctx, m.OriginalKey, bytes.NewReader(input.Data), original.ContentType); err != nil {
+		return nil, err
+	}
+	for _, r := range renditions {
+		key := fmt.Sprintf("%s/%s.%s", prefix, r.Name, r.Format)
+		keys = append(keys, key)
+		if err := uc.store.Put(ctx, key, bytes.NewReader(r.Data), r.ContentType); err != nil {
+			uc.deleteBlobs(ctx, keys)
+			return nil, err
+		}
+		m.Renditions = append(m.Renditions, entity.MediaRendition{Name: r.Name, Format: r.Format, Width: r.Width, Height: r.Height, Key: key})
+	}
+
+	if _, err := uc.repo.Create(ctx, m); err != nil {
+		uc.deleteBlobs(ctx, keys)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (uc *MediaUseCase) ListMedia(ctx context.Context, productID int64) ([]entity.ProductMedia, error) {
+	return uc.repo.ListByProduct(ctx, productID)
+}
+
+func (uc *MediaUseCase) ListMediaByProducts(ctx context.Context, productIDs []int64) (map[int64][]entity.ProductMedia, error) {
+	return uc.repo.ListByProducts(ctx, productIDs)
+}
+
+func (uc *MediaUseCase) DeleteMedia(ctx context.Context, productID, mediaID int64) error {
+
+	m, err := uc.repo.GetByID(ctx, mediaID)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.ProductID != productID {
+		return entity.ErrMediaNotFound
+	}
+
+	if err := uc.repo.Delete(ctx, mediaID); err != nil {
+		return err
+	}
+	uc.ReleaseBlobs(ctx, []entity.ProductMedia{*m})
+
+	return nil
+}
+
+// ReleaseBlobs removes the stored files of media whose rows are already gone, e.g. after the
+// product was deleted and its media rows cascaded
+func (uc *MediaUseCase) ReleaseBlobs(ctx context.Context, list []entity.ProductMedia) {
+
+	var keys []string
+	for _, m := range list {
+		keys = append(keys, m.OriginalKey)
+		for _, r := range m.Renditions {
+			keys = append(keys, r.Key)
+		}
+	}
+	uc.deleteBlobs(ctx, keys)
+}
+
+// ReorderMedia takes every media id of the product in the new display order
+func (uc *MediaUseCase) ReorderMedia(ctx context.Context, productID int64, ids []int64) error {
+
+	current, err := uc.repo.ListByProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) != len(current) {
+		return entity.ErrMediaOrderMismatch
+	}
+	remaining := make(map[int64]bool, len(current))
+	for _, m := range current {
+		remaining[m.ID] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return entity.ErrMediaOrderMismatch
+		}
+		delete(remaining, id)
+	}
+
+	return uc.repo.Reorder(ctx, productID, ids)
+}
+
+// URL returns the public address of a stored blob
+func (uc *MediaUseCase) URL(key string) string {
+	return uc.store.URL(key)
+}
+
+func (uc *MediaUseCase) deleteBlobs(ctx context.Context, keys []string) {
+
+	for _, key := range keys {
+		if err := uc.store.Delete(ctx, key); err != nil {
+			log.Printf("warning: failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// mediaPrefix returns a unique folder for an upload, e.g. products/42/9f86d081884c7d65
+func mediaPrefix(productID int64) (string, error) {
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("products/%d/%s", productID, hex.EncodeToString(b)), nil
+}
@@ -2,61 +2,445 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
-	"strconv"
+	"maps"
+	"strings"
+	"time"
 
 	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/product/internal/kafka"
 	"github.com/raulsilva-tech/e-commerce/services/product/internal/repository"
+	"github.com/raulsilva-tech/e-commerce/services/product/pb/events"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// MaxBatchSize caps the ids accepted by BatchGetProducts
+	MaxBatchSize = 500
+	// publishTimeout bounds how long a change waits on the event broker
+	publishTimeout = 2 * time.Second
+)
+
+var ErrBatchTooLarge = errors.New("too many ids in batch")
+
+type CreateProductInput struct {
+	SKU         string
+	Name        string
+	Description string
+	Category    string
+	Price       money.Money
+	Attributes  entity.Attributes
+	// Slug is made from the name when empty
+	Slug           string
+	SEOTitle       string
+	SEODescription string
+	// Bundle makes the product a bundle of other products
+	Bundle *BundleInput
+}
+
+// UpdateProductInput holds the fields to change, nil fields are kept
+type UpdateProductInput struct {
+	SKU         *string
+	Name        *string
+	Description *string
+	Category    *string
+	Price       *money.Money
+	// Attributes replaces all the attribute values when set
+	Attributes entity.Attributes
+	// Slug replaces the slug, or when empty makes a new one from the name. Without it a new name gets a new slug.
+	Slug           *string
+	SEOTitle       *string
+	SEODescription *string
+}
+
+type PriceListInput struct {
+	Name          string
+	Currency      string
+	Region        string
+	CustomerGroup string
+	Priority      int
+	StartsAt      time.Time
+	EndsAt        *time.Time
+}
+
 type ProductUseCase struct {
-	repo  *repository.ProductRepository
-	cache *repository.ProductCache
+	repo       *repository.ProductRepository
+	priceLists *repository.PriceListRepository
+	cache      *repository.ProductCache
+	stock      *repository.StockRepository
+	attributes *repository.AttributeRepository
+	bundles    *repository.BundleRepository
+	// scheduledPrices holds future price changes and sales, applied by RunPriceScheduler
+	scheduledPrices *repository.ScheduledPriceRepository
+	rates           *money.RateTable
+	events          producer.Publisher
 }
 
-func NewProductUseCase(r *repository.ProductRepository, c *repository.ProductCache) *ProductUseCase {
-	return &ProductUseCase{repo: r, cache: c}
+// NewProductUseCase builds the product use case; rates is optional and enables converting
+// base prices into currencies that have no price list.
+func NewProductUseCase(r *repository.ProductRepository, pl *repository.PriceListRepository, s *repository.StockRepository, a *repository.AttributeRepository, b *repository.BundleRepository, sp *repository.ScheduledPriceRepository, c *repository.ProductCache, rates *money.RateTable, events producer.Publisher) *ProductUseCase {
+	return &ProductUseCase{repo: r, priceLists: pl, stock: s, attributes: a, bundles: b, scheduledPrices: sp, cache: c, rates: rates, events: events}
 }
 
-func (uc *ProductUseCase) CreateProduct(ctx context.Context, name string, price float64) (int64, error) {
+func (uc *ProductUseCase) CreateProduct(ctx context.Context, input CreateProductInput) (int64, error) {
 
-	p, err := entity.NewProduct(0, name, price)
+	p, err := entity.NewProduct(0, input.SKU, input.Name, input.Description, input.Category, input.Price)
 	if err != nil {
 		return 0, err
 	}
+	p.Attributes = input.Attributes
+	p.Slug, p.SEOTitle, p.SEODescription = input.Slug, input.SEOTitle, input.SEODescription
+	if err := p.Validate(); err != nil {
+		return 0, err
+	}
+	if err := uc.validateAttributes(ctx, p); err != nil {
+		return 0, err
+	}
+	if input.Bundle != nil {
+		return uc.createBundle(ctx, p, *input.Bundle)
+	}
 	id, err := uc.repo.Create(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	// the id may have been looked up, and cached as not found, before it existed
+	uc.invalidate(ctx, id)
+	uc.publish(ctx, producer.ProductCreated(p))
 
-	return id, err
+	return id, nil
 }
 
-func (uc *ProductUseCase) ListProducts(ctx context.Context, limit int) ([]entity.Product, error) {
+func (uc *ProductUseCase) UpdateProduct(ctx context.Context, id int64, input UpdateProductInput) (*entity.Product, error) {
+
+	p, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrProductNotFound
+	}
+	before := *p
+
+	if input.SKU != nil {
+		p.SKU = *input.SKU
+	}
+	if input.Name != nil {
+		p.Name = *input.Name
+	}
+	if input.Description != nil {
+		p.Description = *input.Description
+	}
+	if input.Category != nil {
+		p.Category = *input.Category
+	}
+	if input.Price != nil {
+		p.Price = *input.Price
+	}
+	if input.Attributes != nil {
+		p.Attributes = input.Attributes
+	}
+	if input.Slug != nil {
+		p.Slug = *input.Slug
+	} else if p.Name != before.Name {
+		p.Slug = ""
+	}
+	if input.SEOTitle != nil {
+		p.SEOTitle = *input.SEOTitle
+	}
+	if input.SEODescription != nil {
+		p.SEODescription = *input.SEODescription
+	}
 
-	return uc.repo.GetList(ctx, limit)
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	// also when only the category changed, the values must fit the new category
+	if err := uc.validateAttributes(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, id)
 
+	if changed := changedFields(&before, p); len(changed) > 0 {
+		uc.publish(ctx, producer.ProductUpdated(p, changed))
+	}
+	if before.Price != p.Price {
+		uc.publish(ctx, producer.PriceChanged(id, 0, &before.Price, p.Price))
+	}
+
+	return p, nil
 }
 
-func (uc *ProductUseCase) GetByProductId(ctx context.Context, id int64) (*entity.Product, error) {
+func (uc *ProductUseCase) DeleteProduct(ctx context.Context, id int64) error {
+
+	p, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return entity.ErrProductNotFound
+	}
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.invalidate(ctx, id)
+	uc.publish(ctx, producer.ProductDeleted(id, p.SKU))
+
+	return nil
+}
+
+// publish sends an event after the change is stored; a failure is logged and does not undo the change
+func (uc *ProductUseCase) publish(ctx context.Context, ev *events.ProductEvent) {
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	if err := uc.events.Publish(ctx, ev); err != nil {
+		log.Printf("warning: publish %s for product %d: %v", producer.EventType(ev), ev.ProductId, err)
+	}
+}
+
+// publishBatch sends the events of several changes at once, like publish
+func (uc *ProductUseCase) publishBatch(ctx context.Context, evs []*events.ProductEvent) {
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	if err := uc.events.PublishBatch(ctx, evs); err != nil {
+		log.Printf("warning: publish %d product events: %v", len(evs), err)
+	}
+}
+
+func changedFields(before, after *entity.Product) []string {
+
+	var changed []string
+	if before.SKU != after.SKU {
+		changed = append(changed, "sku")
+	}
+	if before.Name != after.Name {
+		changed = append(changed, "name")
+	}
+	if before.Description != after.Description {
+		changed = append(changed, "description")
+	}
+	if before.Category != after.Category {
+		changed = append(changed, "category")
+	}
+	if before.Price != after.Price {
+		changed = append(changed, "price")
+	}
+	if !maps.Equal(before.Attributes, after.Attributes) {
+		changed = append(changed, "attributes")
+	}
+	if before.Slug != after.Slug {
+		changed = append(changed, "slug")
+	}
+	if before.SEOTitle != after.SEOTitle || before.SEODescription != after.SEODescription {
+		changed = append(changed, "seo")
+	}
+	return changed
+}
+
+func (uc *ProductUseCase) invalidate(ctx context.Context, id int64) {
+
+	if err := uc.cache.Delete(ctx, id); err != nil {
+		log.Printf("warning: product cache delete %d: %v", id, err)
+	}
+}
 
-	var prod *entity.Product
-	if err := uc.cache.GetProduct(strconv.Itoa(int(id)), prod); err == nil {
-		fmt.Println("cache found!")
-		return prod, nil
-	} else {
-		fmt.Println("cache NOT found!")
+func (uc *ProductUseCase) ListProducts(ctx context.Context, lq entity.ListQuery) ([]entity.Product, error) {
+
+	filters, err := uc.resolveFilters(ctx, lq.Category, lq.Attributes)
+	if err != nil {
+		return nil, err
 	}
+	lq.Attributes = filters
+
+	return uc.repo.GetList(ctx, lq)
+}
 
-	prod, err := uc.repo.GetByID(ctx, id)
+func (uc *ProductUseCase) SearchProducts(ctx context.Context, sq entity.SearchQuery) (*entity.SearchResult, error) {
+
+	if sq.Limit <= 0 || sq.Limit > maxSearchLimit {
+		sq.Limit = defaultSearchLimit
+	}
+	if sq.Offset < 0 {
+		sq.Offset = 0
+	}
+	if sq.MinPrice != nil && sq.MaxPrice != nil && sq.MinPrice.Currency != sq.MaxPrice.Currency {
+		return nil, money.ErrCurrencyMismatch
+	}
+	filters, err := uc.resolveFilters(ctx, sq.Category, sq.Attributes)
 	if err != nil {
 		return nil, err
 	}
+	sq.Attributes = filters
+
+	return uc.repo.Search(ctx, sq)
+}
+
+func (uc *ProductUseCase) GetByProductId(ctx context.Context, id int64) (*entity.Product, error) {
+
+	return uc.cache.GetOrLoad(ctx, id, func(ctx context.Context) (*entity.Product, error) {
+		return uc.repo.GetByID(ctx, id)
+	})
+}
+
+// BatchGetProducts returns the products found among ids in request order, skipping duplicates, plus
+// the ids that do not exist, or unless includeUnpublished, that customers do not see. Cached products
+// come from one MGET and the rest from one query.
+func (uc *ProductUseCase) BatchGetProducts(ctx context.Context, ids []int64, includeUnpublished bool) ([]entity.Product, []int64, error) {
+
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchSize {
+		return nil, nil, ErrBatchTooLarge
+	}
+
+	byID, misses := uc.cache.GetMany(ctx, unique)
 
-	if prod != nil {
-		err = uc.cache.SetProduct(strconv.Itoa(int(id)), prod)
+	if len(misses) > 0 {
+		loaded, err := uc.repo.GetByIDs(ctx, misses)
 		if err != nil {
-			log.Println(err, err.Error())
+			return nil, nil, err
+		}
+
+		fresh := make(map[int64]*entity.Product, len(loaded))
+		for i := range loaded {
+			fresh[loaded[i].ID] = &loaded[i]
+		}
+		if err := uc.cache.SetMany(ctx, misses, fresh); err != nil {
+			log.Printf("warning: product cache set many: %v", err)
+		}
+		for _, id := range misses {
+			byID[id] = fresh[id]
 		}
 	}
 
-	return prod, nil
+	now := time.Now()
+	products := make([]entity.Product, 0, len(unique))
+	var missing []int64
+	for _, id := range unique {
+		if p := byID[id]; p != nil && (includeUnpublished || p.IsVisible(now)) {
+			products = append(products, *p)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	return products, missing, nil
+}
+
+func (uc *ProductUseCase) CreatePriceList(ctx context.Context, input PriceListInput) (int64, error) {
+
+	if input.StartsAt.IsZero() {
+		input.StartsAt = time.Now()
+	}
+
+	pl, err := entity.NewPriceList(0, input.Name, input.Currency, input.Region, input.CustomerGroup, input.Priority, input.StartsAt, input.EndsAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return uc.priceLists.Create(ctx, pl)
+}
+
+func (uc *ProductUseCase) SetPriceListPrice(ctx context.Context, priceListID, productID int64, price money.Money) error {
+
+	pl, err := uc.priceLists.GetByID(ctx, priceListID)
+	if err != nil {
+		return err
+	}
+	if pl == nil {
+		return entity.ErrPriceListNotFound
+	}
+	if price.Currency != pl.Currency {
+		return money.ErrCurrencyMismatch
+	}
+	if price.IsNegative() {
+		return entity.ErrNegativePrice
+	}
+
+	if err := uc.priceLists.SetPrice(ctx, priceListID, productID, price); err != nil {
+		return err
+	}
+	uc.publish(ctx, producer.PriceChanged(productID, priceListID, nil, price))
+
+	return nil
+}
+
+// GetPrice resolves the price of a product for the caller's currency, region and customer group at a given time
+func (uc *ProductUseCase) GetPrice(ctx context.Context, productID int64, pc entity.PriceContext) (money.Money, error) {
+
+	p, err := uc.GetByProductId(ctx, productID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if p == nil {
+		return money.Money{}, entity.ErrProductNotFound
+	}
+
+	return uc.ResolvePrice(ctx, p, pc)
+}
+
+// ResolvePrice picks, in order: the best matching price list in the requested currency, the base price
+// when it is already in that currency, or the base price converted with the local rate table.
+// A percent off bundle costs the resolved prices of its components less the discount.
+func (uc *ProductUseCase) ResolvePrice(ctx context.Context, p *entity.Product, pc entity.PriceContext) (money.Money, error) {
+
+	if p.Kind == entity.ProductBundle {
+		b, err := uc.GetBundle(ctx, p.ID)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if b.Pricing == entity.BundlePercentOff {
+			if pc.Currency == "" {
+				pc.Currency = p.Price.Currency
+			}
+			return uc.bundlePrice(ctx, b, pc)
+		}
+	}
+
+	if pc.Currency == "" {
+		return p.Price, nil
+	}
+	pc.Currency = strings.ToUpper(pc.Currency)
+	pc.Region = strings.ToUpper(pc.Region)
+	if pc.At.IsZero() {
+		pc.At = time.Now()
+	}
+
+	price, err := uc.priceLists.FindPrice(ctx, p.ID, pc)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if price != nil {
+		return *price, nil
+	}
+
+	if p.Price.Currency == pc.Currency {
+		return p.Price, nil
+	}
+
+	if uc.rates == nil {
+		return money.Money{}, entity.ErrPriceNotAvailable
+	}
+
+	converted, err := uc.rates.Convert(p.Price, pc.Currency)
+	if errors.Is(err, money.ErrRateNotFound) {
+		return money.Money{}, entity.ErrPriceNotAvailable
+	}
+	return converted, err
 }
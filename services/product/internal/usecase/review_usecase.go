@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/repository"
+)
+
+const (
+	defaultReviewLimit = 20
+	maxReviewLimit     = 100
+)
+
+type SubmitReviewInput struct {
+	ProductID int64
+	UserID    int64
+	Rating    int
+	Title     string
+	Body      string
+}
+
+type ReviewUseCase struct {
+	repo     *repository.ReviewRepository
+	products *repository.ProductRepository
+	cache    *repository.ProductCache
+}
+
+func NewReviewUseCase(r *repository.ReviewRepository, p *repository.ProductRepository, c *repository.ProductCache) *ReviewUseCase {
+	return &ReviewUseCase{repo: r, products: p, cache: c}
+}
+
+// SubmitReview accepts reviews from customers who bought the product; they wait for moderation
+func (uc *ReviewUseCase) SubmitReview(ctx context.Context, input SubmitReviewInput) (*entity.Review, error) {
+
+	rv, err := entity.NewReview(input.ProductID, input.UserID, input.Rating, input.Title, input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := uc.products.GetByID(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrProductNotFound
+	}
+
+	purchased, err := uc.repo.HasPurchased(ctx, input.UserID, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !purchased {
+		return nil, entity.ErrPurchaseRequired
+	}
+	rv.VerifiedPurchase = true
+
+	if _, err := uc.repo.Create(ctx, rv); err != nil {
+		return nil, err
+	}
+
+	return rv, nil
+}
+
+// ListReviews pages through the published reviews of a product, sorted by "recent" or "helpful";
+// more tells whether another page follows
+func (uc *ReviewUseCase) ListReviews(ctx context.Context, productID int64, sort string, limit, offset int) (list []entity.Review, more bool, err error) {
+
+	limit, offset = reviewPage(limit, offset)
+	if sort != repository.ReviewSortHelpful {
+		sort = repository.ReviewSortRecent
+	}
+
+	// one extra row tells whether there is a next page
+	list, err = uc.repo.ListByProduct(ctx, productID, sort, limit+1, offset)
+	if err != nil {
+		return nil, false, err
+	}
+	return trimPage(list, limit)
+}
+
+// ModerationQueue lists reviews in a moderation state, oldest first; pending by default
+func (uc *ReviewUseCase) ModerationQueue(ctx context.Context, status entity.ReviewStatus, limit, offset int) (list []entity.Review, more bool, err error) {
+
+	if status == "" {
+		status = entity.ReviewPending
+	}
+	if !status.Valid() {
+		return nil, false, entity.ErrInvalidReviewStatus
+	}
+	limit, offset = reviewPage(limit, offset)
+
+	list, err = uc.repo.ListByStatus(ctx, status, limit+1, offset)
+	if err != nil {
+		return nil, false, err
+	}
+	return trimPage(list, limit)
+}
+
+// ModerateReview moves a review to a new state and recounts the product rating when publication changes
+func (uc *ReviewUseCase) ModerateReview(ctx context.Context, id int64, status entity.ReviewStatus) (*entity.Review, error) {
+
+	if !status.Valid() {
+		return nil, entity.ErrInvalidReviewStatus
+	}
+
+	rv, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rv == nil {
+		return nil, entity.ErrReviewNotFound
+	}
+
+	now := time.Now()
+	if err := uc.repo.SetStatus(ctx, id, status, now); err != nil {
+		return nil, err
+	}
+
+	wasPublished := rv.Status == entity.ReviewApproved
+	rv.Status, rv.ModeratedAt = status, &now
+
+	if wasPublished != (status == entity.ReviewApproved) {
+		if _, err := uc.repo.RefreshRating(ctx, rv.ProductID); err != nil {
+			return nil, err
+		}
+		if err := uc.cache.Delete(ctx, rv.ProductID); err != nil {
+			log.Printf("warning: product cache delete %d: %v", rv.ProductID, err)
+		}
+	}
+
+	return rv, nil
+}
+
+// VoteHelpful counts one vote per user on a published review; voting again is a no-op
+func (uc *ReviewUseCase) VoteHelpful(ctx context.Context, reviewID, userID int64) error {
+
+	rv, err := uc.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if rv == nil {
+		return entity.ErrReviewNotFound
+	}
+	if rv.Status != entity.ReviewApproved {
+		return entity.ErrReviewNotPublished
+	}
+	if rv.UserID == userID {
+		return entity.ErrCannotVoteOwnReview
+	}
+
+	_, err = uc.repo.AddHelpfulVote(ctx, reviewID, userID)
+	return err
+}
+
+// RecordPurchase is called by the order service for every product of a completed order
+func (uc *ReviewUseCase) RecordPurchase(ctx context.Context, userID, productID, orderID int64) error {
+	return uc.repo.RecordPurchase(ctx, userID, productID, orderID)
+}
+
+func trimPage(list []entity.Review, limit int) ([]entity.Review, bool, error) {
+
+	if len(list) > limit {
+		return list[:limit], true, nil
+	}
+	return list, false, nil
+}
+
+func reviewPage(limit, offset int) (int, int) {
+
+	if limit <= 0 || limit > maxReviewLimit {
+		limit = defaultReviewLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
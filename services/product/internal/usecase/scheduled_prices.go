@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/product/internal/kafka"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// schedulerBatchSize caps the changes started, and the sales ended, in one scheduler run
+const schedulerBatchSize = 100
+
+type SchedulePriceInput struct {
+	Price    money.Money
+	StartsAt time.Time
+	// EndsAt makes the change a sale, reverted when it ends
+	EndsAt *time.Time
+}
+
+// SchedulePrice schedules a change of the base price of a product; sales of a product must not overlap
+func (uc *ProductUseCase) SchedulePrice(ctx context.Context, productID int64, input SchedulePriceInput) (*entity.ScheduledPrice, error) {
+
+	if err := uc.requireProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if input.StartsAt.IsZero() {
+		input.StartsAt = now
+	}
+	sp, err := entity.NewScheduledPrice(productID, input.Price, input.StartsAt, input.EndsAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if sp.IsSale() {
+		overlaps, err := uc.scheduledPrices.HasOverlappingSale(ctx, productID, sp.StartsAt, *sp.EndsAt)
+		if err != nil {
+			return nil, err
+		}
+		if overlaps {
+			return nil, entity.ErrSaleOverlaps
+		}
+	}
+
+	if _, err := uc.scheduledPrices.Create(ctx, sp); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+func (uc *ProductUseCase) ListScheduledPrices(ctx context.Context, productID int64) ([]entity.ScheduledPrice, error) {
+
+	if err := uc.requireProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.scheduledPrices.ListByProduct(ctx, productID)
+}
+
+func (uc *ProductUseCase) CancelScheduledPrice(ctx context.Context, id int64) error {
+	return uc.scheduledPrices.Cancel(ctx, id)
+}
+
+// ApplyScheduledPrices ends the sales and starts the changes that are due at now. Sales end first, so a
+// sale followed directly by another one reverts before the next starts.
+func (uc *ProductUseCase) ApplyScheduledPrices(ctx context.Context, now time.Time) error {
+
+	ending, err := uc.scheduledPrices.DueToEnd(ctx, now, schedulerBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range ending {
+		change, err := uc.scheduledPrices.End(ctx, &ending[i])
+		if err != nil {
+			log.Printf("warning: end scheduled price %d: %v", ending[i].ID, err)
+			continue
+		}
+		uc.priceChanged(ctx, ending[i].ProductID, change)
+	}
+
+	starting, err := uc.scheduledPrices.DueToStart(ctx, now, schedulerBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range starting {
+		change, err := uc.scheduledPrices.Start(ctx, &starting[i], now)
+		if err != nil {
+			log.Printf("warning: start scheduled price %d: %v", starting[i].ID, err)
+			continue
+		}
+		uc.priceChanged(ctx, starting[i].ProductID, change)
+	}
+
+	return nil
+}
+
+// RunPriceScheduler applies scheduled prices every interval until ctx is done. The schedule lives in the
+// database, so changes that fell due while no instance was running are applied on the first run.
+func (uc *ProductUseCase) RunPriceScheduler(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := uc.ApplyScheduledPrices(ctx, time.Now()); err != nil {
+			log.Printf("warning: price scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// priceChanged drops the cached product, whose compare-at price may have changed even without a price change
+func (uc *ProductUseCase) priceChanged(ctx context.Context, productID int64, change *entity.PriceChange) {
+
+	uc.invalidate(ctx, productID)
+	if change != nil {
+		uc.publish(ctx, producer.PriceChanged(productID, 0, &change.Old, change.New))
+	}
+}
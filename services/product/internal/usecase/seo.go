@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/sitemap"
+)
+
+// GetProductBySlug finds a product by its current slug or one it had; the returned product has the
+// current slug, which callers redirect to when it differs from the one asked for. Visibility is as in GetProduct.
+func (uc *ProductUseCase) GetProductBySlug(ctx context.Context, slug string, includeUnpublished bool) (*entity.Product, error) {
+
+	id, err := uc.repo.ProductIDBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, entity.ErrProductNotFound
+	}
+	return uc.GetProduct(ctx, id, includeUnpublished)
+}
+
+// SitemapProductPages returns how many sitemaps it takes to list every product customers see
+func (uc *ProductUseCase) SitemapProductPages(ctx context.Context) (int, error) {
+
+	n, err := uc.repo.CountSlugs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return (n + sitemap.MaxURLs - 1) / sitemap.MaxURLs, nil
+}
+
+// EachSitemapProduct hands fn the slugs listed by sitemap page, counted from 1
+func (uc *ProductUseCase) EachSitemapProduct(ctx context.Context, page int, fn func(slug string) error) error {
+	return uc.repo.EachSlug(ctx, (page-1)*sitemap.MaxURLs, sitemap.MaxURLs, fn)
+}
+
+// SitemapCategories returns the categories to list, as many as fit one sitemap
+func (uc *ProductUseCase) SitemapCategories(ctx context.Context) ([]string, error) {
+	return uc.repo.Categories(ctx, sitemap.MaxURLs)
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	producer "github.com/raulsilva-tech/e-commerce/services/product/internal/kafka"
+	"github.com/raulsilva-tech/e-commerce/services/product/pb/events"
+)
+
+func (uc *ProductUseCase) GetStock(ctx context.Context, productID int64) (*entity.StockLevel, error) {
+
+	if err := uc.requireStocked(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.stock.Get(ctx, productID)
+}
+
+// AdjustStock adds delta, which may be negative, to the quantity on hand
+func (uc *ProductUseCase) AdjustStock(ctx context.Context, productID, delta int64) (*entity.StockLevel, error) {
+
+	if err := uc.requireStocked(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	s, err := uc.stock.Adjust(ctx, productID, delta)
+	if err != nil {
+		return nil, err
+	}
+	if delta != 0 {
+		uc.publish(ctx, producer.StockLevelChanged(productID, s.OnHand-delta, s.OnHand))
+	}
+
+	return s, nil
+}
+
+// GetAvailability returns how many units can be sold: what is on hand and not reserved, or for a bundle
+// how many bundles its components can make
+func (uc *ProductUseCase) GetAvailability(ctx context.Context, productID int64) (int64, error) {
+
+	p, err := uc.getProduct(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.Kind != entity.ProductBundle {
+		s, err := uc.stock.Get(ctx, productID)
+		if err != nil {
+			return 0, err
+		}
+		return s.Available(), nil
+	}
+
+	b, err := uc.GetBundle(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]int64, len(b.Components))
+	for i, c := range b.Components {
+		ids[i] = c.ProductID
+	}
+	byID, err := uc.stock.AvailableMany(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	available := make([]int64, len(ids))
+	for i, id := range ids {
+		available[i] = byID[id]
+	}
+	return b.Available(available), nil
+}
+
+// ReserveStock holds the items for reference, e.g. an order, all or none. Bundles reserve their components.
+// Retrying with the same reference holds nothing more.
+func (uc *ProductUseCase) ReserveStock(ctx context.Context, reference string, items []entity.StockItem) error {
+
+	expanded, err := uc.expandBundles(ctx, items)
+	if err != nil {
+		return err
+	}
+	return uc.stock.Reserve(ctx, reference, expanded)
+}
+
+// ReleaseStock returns what reference holds; it does nothing for unknown or released references
+func (uc *ProductUseCase) ReleaseStock(ctx context.Context, reference string) error {
+	return uc.stock.Release(ctx, reference)
+}
+
+// ReleaseStockItems returns part of what reference holds, e.g. the cancelled lines of an order. Bundles
+// release their components. releaseID names the release: repeating one returns nothing more.
+func (uc *ProductUseCase) ReleaseStockItems(ctx context.Context, reference, releaseID string, items []entity.StockItem) error {
+
+	if releaseID == "" {
+		return entity.ErrReleaseIdIsRequired
+	}
+	expanded, err := uc.expandBundles(ctx, items)
+	if err != nil {
+		return err
+	}
+	return uc.stock.ReleaseItems(ctx, reference, releaseID, expanded)
+}
+
+// CommitStock takes what reference holds out of stock once its goods left, e.g. a shipped order. It does
+// nothing for unknown or committed references.
+func (uc *ProductUseCase) CommitStock(ctx context.Context, reference string) error {
+
+	moves, err := uc.stock.Commit(ctx, reference)
+	if err != nil {
+		return err
+	}
+	uc.publishStockMoves(ctx, moves)
+	return nil
+}
+
+// RestockItems puts goods back on hand, e.g. those of a return. Bundles restock their components.
+// reference names the restock: repeating one adds nothing more.
+func (uc *ProductUseCase) RestockItems(ctx context.Context, reference string, items []entity.StockItem) error {
+
+	if reference == "" {
+		return entity.ErrReferenceIsRequired
+	}
+	expanded, err := uc.expandBundles(ctx, items)
+	if err != nil {
+		return err
+	}
+	moves, err := uc.stock.Restock(ctx, reference, expanded)
+	if err != nil {
+		return err
+	}
+	uc.publishStockMoves(ctx, moves)
+	return nil
+}
+
+// publishStockMoves announces the quantities on hand moves changed, as AdjustStock does
+func (uc *ProductUseCase) publishStockMoves(ctx context.Context, moves []entity.StockMove) {
+
+	var evs []*events.ProductEvent
+	for _, m := range moves {
+		if m.Before != m.After {
+			evs = append(evs, producer.StockLevelChanged(m.ProductID, m.Before, m.After))
+		}
+	}
+	if len(evs) > 0 {
+		uc.publishBatch(ctx, evs)
+	}
+}
+
+// expandBundles replaces bundles by their components, adding up the quantities of products that
+// appear more than once
+func (uc *ProductUseCase) expandBundles(ctx context.Context, items []entity.StockItem) ([]entity.StockItem, error) {
+
+	quantities := make(map[int64]int64)
+	var order []int64
+	add := func(productID, qty int64) {
+		if _, ok := quantities[productID]; !ok {
+			order = append(order, productID)
+		}
+		quantities[productID] += qty
+	}
+
+	for _, it := range items {
+		if it.Quantity <= 0 {
+			return nil, entity.ErrInvalidReservationItem
+		}
+		p, err := uc.getProduct(ctx, it.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if p.Kind != entity.ProductBundle {
+			add(p.ID, it.Quantity)
+			continue
+		}
+
+		b, err := uc.GetBundle(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range b.Components {
+			add(c.ProductID, c.Quantity*it.Quantity)
+		}
+	}
+
+	expanded := make([]entity.StockItem, len(order))
+	for i, id := range order {
+		expanded[i] = entity.StockItem{ProductID: id, Quantity: quantities[id]}
+	}
+	return expanded, nil
+}
+
+func (uc *ProductUseCase) requireProduct(ctx context.Context, productID int64) error {
+
+	_, err := uc.getProduct(ctx, productID)
+	return err
+}
+
+// requireStocked accepts products whose stock is counted; a bundle's comes from its components
+func (uc *ProductUseCase) requireStocked(ctx context.Context, productID int64) error {
+
+	p, err := uc.getProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if p.Kind == entity.ProductBundle {
+		return entity.ErrBundleStockIsComputed
+	}
+	return nil
+}
+
+func (uc *ProductUseCase) getProduct(ctx context.Context, productID int64) (*entity.Product, error) {
+
+	p, err := uc.GetByProductId(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, entity.ErrProductNotFound
+	}
+	return p, nil
+}
@@ -0,0 +1,115 @@
+package webserver
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+)
+
+type CreateAttributeDefinitionDTO struct {
+	Category string   `json:"category"`
+	Code     string   `json:"code"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Unit     string   `json:"unit"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+func (s *Server) CreateAttributeDefinition(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	var dto CreateAttributeDefinitionDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	d, err := s.productUseCase.CreateAttributeDefinition(r.Context(), usecase.AttributeDefinitionInput{
+		Category: dto.Category,
+		Code:     dto.Code,
+		Label:    dto.Label,
+		Type:     entity.AttributeType(dto.Type),
+		Unit:     dto.Unit,
+		Options:  dto.Options,
+		Required: dto.Required,
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, d)
+}
+
+// ListAttributeDefinitions returns the specification schema of a category
+func (s *Server) ListAttributeDefinitions(w http.ResponseWriter, r *http.Request) {
+
+	defs, err := s.productUseCase.ListAttributeDefinitions(r.Context(), mux.Vars(r)["category"])
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	if defs == nil {
+		defs = []entity.AttributeDefinition{}
+	}
+
+	writeJSONWithETag(w, r, defs)
+}
+
+// attributeFilters reads ?attr.<code>=<value>, ?attr.<code>.min= and ?attr.<code>.max=
+func attributeFilters(q url.Values) ([]entity.AttributeFilter, error) {
+
+	byCode := make(map[string]*entity.AttributeFilter)
+	filter := func(code string) *entity.AttributeFilter {
+		f, ok := byCode[code]
+		if !ok {
+			f = &entity.AttributeFilter{Code: code}
+			byCode[code] = f
+		}
+		return f
+	}
+
+	for key, values := range q {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		bound := ""
+		if code, b, found := strings.Cut(name, "."); found {
+			name, bound = code, b
+		}
+		switch bound {
+		case "":
+			filter(name).Equals = values[0]
+		case "min", "max":
+			v, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				return nil, entity.ErrInvalidAttributeValue
+			}
+			if bound == "min" {
+				filter(name).Min = &v
+			} else {
+				filter(name).Max = &v
+			}
+		default:
+			return nil, entity.ErrInvalidAttributeValue
+		}
+	}
+
+	filters := make([]entity.AttributeFilter, 0, len(byCode))
+	for _, code := range slices.Sorted(maps.Keys(byCode)) {
+		filters = append(filters, *byCode[code])
+	}
+	return filters, nil
+}
@@ -0,0 +1,69 @@
+package webserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/auth"
+)
+
+type principalKey struct{}
+
+// Principal is the caller identified by the access token issued by the auth service
+type Principal = auth.Principal
+
+// PrincipalFromContext returns the caller set by jwtMiddleware, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// ---- JWT Middleware
+func (s *Server) jwtMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, msg := s.authenticate(r)
+		if msg != "" {
+			writeError(w, http.StatusUnauthorized, msg)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalKey{}, p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// optionalJWT identifies the caller when a token is sent, which lets storefront routes show staff more;
+// requests without one go through anonymously
+func (s *Server) optionalJWT(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s.jwtMiddleware(next).ServeHTTP(w, r)
+	})
+}
+
+// authenticate reads the bearer token, returning why it was rejected
+func (s *Server) authenticate(r *http.Request) (Principal, string) {
+	return auth.ParseBearer(s.cfg.JWTSecret, r.Header.Get("Authorization"))
+}
+
+// isStaff tells whether the caller identified by jwtMiddleware or optionalJWT is staff
+func isStaff(r *http.Request) bool {
+	p, ok := PrincipalFromContext(r.Context())
+	return ok && p.IsStaff()
+}
+
+// requireStaff must run after jwtMiddleware
+func (s *Server) requireStaff(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok || !p.IsStaff() {
+			writeError(w, http.StatusForbidden, "staff only")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,125 @@
+package webserver
+
+import (
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/bulk"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+)
+
+// maxImportSize caps the size of an uploaded import file
+const maxImportSize = 64 << 20
+
+// ImportProducts accepts a CSV or JSON Lines file, either as the raw body or as the "file" field of a
+// multipart form. The format comes from ?format=, the file extension or the content type.
+// ?dry_run=true validates without writing.
+func (s *Server) ImportProducts(w http.ResponseWriter, r *http.Request) {
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	defer r.Body.Close()
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	formatHint := r.URL.Query().Get("format")
+
+	var body io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+			return
+		}
+		defer file.Close()
+		body = file
+		if formatHint == "" {
+			formatHint = filepath.Ext(header.Filename)
+		}
+	}
+	if formatHint == "" {
+		formatHint = mediaType
+	}
+
+	format, err := bulk.ParseFormat(formatHint)
+	if err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	src, err := bulk.NewReader(body, format)
+	if err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	report, err := s.productUseCase.ImportProducts(r.Context(), src, dryRun)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "error: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toImportReportDTO(report))
+}
+
+// ExportProducts streams every product as CSV (default) or JSON Lines (?format=jsonl)
+func (s *Server) ExportProducts(w http.ResponseWriter, r *http.Request) {
+
+	format := bulk.CSV
+	if v := r.URL.Query().Get("format"); v != "" {
+		f, err := bulk.ParseFormat(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format = f
+	}
+
+	contentType := "text/csv"
+	if format == bulk.JSONL {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=products."+string(format))
+
+	bw, _ := bulk.NewWriter(w, format)
+	if err := s.productUseCase.ExportProducts(r.Context(), bw.Write); err != nil {
+		// headers are already sent, the truncated body is all we can signal
+		log.Printf("export products: %v", err)
+		return
+	}
+	bw.Flush()
+}
+
+type ImportRowErrorDTO struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportReportDTO struct {
+	DryRun  bool                `json:"dry_run"`
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Failed  int                 `json:"failed"`
+	Errors  []ImportRowErrorDTO `json:"errors"`
+}
+
+func toImportReportDTO(report *usecase.ImportReport) ImportReportDTO {
+
+	dto := ImportReportDTO{
+		DryRun:  report.DryRun,
+		Created: report.Created,
+		Updated: report.Updated,
+		Failed:  report.Failed,
+		Errors:  []ImportRowErrorDTO{},
+	}
+	for _, e := range report.Errors {
+		dto.Errors = append(dto.Errors, ImportRowErrorDTO{Line: e.Line, SKU: e.SKU, Message: e.Message})
+	}
+	return dto
+}
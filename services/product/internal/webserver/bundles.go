@@ -0,0 +1,79 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+)
+
+// BundleDTO is e.g. {"pricing": "percent_off", "percent_off": 10, "components": [{"sku": "MUG-1", "quantity": 2}]}
+type BundleDTO struct {
+	Pricing    entity.BundlePricing `json:"pricing"`
+	PercentOff int64                `json:"percent_off"`
+	Components []struct {
+		SKU      string `json:"sku"`
+		Quantity int64  `json:"quantity"`
+	} `json:"components"`
+}
+
+func (dto *BundleDTO) toInput() *usecase.BundleInput {
+
+	if dto == nil {
+		return nil
+	}
+	input := &usecase.BundleInput{
+		Pricing:    dto.Pricing,
+		PercentOff: dto.PercentOff,
+		Components: make([]entity.BundleComponent, len(dto.Components)),
+	}
+	for i, c := range dto.Components {
+		input.Components[i] = entity.BundleComponent{SKU: c.SKU, Quantity: c.Quantity}
+	}
+	return input
+}
+
+func (s *Server) GetBundle(w http.ResponseWriter, r *http.Request) {
+
+	p, ok := s.visibleProduct(w, r)
+	if !ok {
+		return
+	}
+
+	b, err := s.productUseCase.GetBundle(r.Context(), p.ID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, b)
+}
+
+// SetBundle replaces the pricing and the components of a bundle
+func (s *Server) SetBundle(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var dto BundleDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	b, err := s.productUseCase.SetBundle(r.Context(), productID, *dto.toInput())
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, b)
+}
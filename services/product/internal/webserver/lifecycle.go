@@ -0,0 +1,70 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+)
+
+// SetStatusDTO is e.g. {"status": "active", "published_at": "2025-11-28T09:00:00Z"}; without published_at
+// an active product is published right away
+type SetStatusDTO struct {
+	Status      entity.ProductStatus `json:"status"`
+	PublishedAt *time.Time           `json:"published_at"`
+}
+
+// SetProductStatus publishes, unpublishes or archives a product; the staff member is recorded in its status history
+func (s *Server) SetProductStatus(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var dto SetStatusDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	principal, _ := PrincipalFromContext(r.Context())
+	p, err := s.productUseCase.SetProductStatus(r.Context(), productID, usecase.SetStatusInput{
+		Status:      dto.Status,
+		PublishedAt: dto.PublishedAt,
+		ChangedBy:   principal.UserID,
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	s.writeProduct(w, r, p)
+}
+
+func (s *Server) ListStatusChanges(w http.ResponseWriter, r *http.Request) {
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	changes, err := s.productUseCase.ListStatusChanges(r.Context(), productID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	if changes == nil {
+		changes = []entity.StatusChange{}
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
@@ -0,0 +1,169 @@
+package webserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+)
+
+type MediaRenditionDTO struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+type MediaDTO struct {
+	ID          int64               `json:"id"`
+	ProductID   int64               `json:"product_id"`
+	VariantSKU  string              `json:"variant_sku,omitempty"`
+	Position    int                 `json:"position"`
+	AltText     string              `json:"alt_text"`
+	ContentType string              `json:"content_type"`
+	Width       int                 `json:"width"`
+	Height      int                 `json:"height"`
+	URL         string              `json:"url"`
+	Renditions  []MediaRenditionDTO `json:"renditions"`
+}
+
+// UploadMedia takes a multipart form with the image in "file" and optional "alt_text" and "variant_sku"
+func (s *Server) UploadMedia(w http.ResponseWriter, r *http.Request) {
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	// room for the form fields on top of the file
+	r.Body = http.MaxBytesReader(w, r.Body, usecase.MaxMediaSize+1<<20)
+	defer r.Body.Close()
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	m, err := s.mediaUseCase.Upload(r.Context(), usecase.UploadMediaInput{
+		ProductID:  productID,
+		VariantSKU: r.FormValue("variant_sku"),
+		AltText:    r.FormValue("alt_text"),
+		Data:       data,
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.toMediaDTO(m))
+}
+
+func (s *Server) ListMedia(w http.ResponseWriter, r *http.Request) {
+
+	p, ok := s.visibleProduct(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := s.mediaUseCase.ListMedia(r.Context(), p.ID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := make([]MediaDTO, 0, len(list))
+	for i := range list {
+		res = append(res, s.toMediaDTO(&list[i]))
+	}
+
+	writeJSONWithETag(w, r, res)
+}
+
+// ReorderMedia takes {"ids": [...]} with every media id of the product in display order
+func (s *Server) ReorderMedia(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var payload struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	if err := s.mediaUseCase.ReorderMedia(r.Context(), productID, payload.IDs); err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	mediaID, err := strconv.ParseInt(vars["mediaId"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid media id")
+		return
+	}
+
+	if err := s.mediaUseCase.DeleteMedia(r.Context(), productID, mediaID); err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) toMediaDTO(m *entity.ProductMedia) MediaDTO {
+
+	dto := MediaDTO{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		VariantSKU:  m.VariantSKU,
+		Position:    m.Position,
+		AltText:     m.AltText,
+		ContentType: m.ContentType,
+		Width:       m.Width,
+		Height:      m.Height,
+		URL:         s.mediaUseCase.URL(m.OriginalKey),
+		Renditions:  []MediaRenditionDTO{},
+	}
+	for _, r := range m.Renditions {
+		dto.Renditions = append(dto.Renditions, MediaRenditionDTO{
+			Name:   r.Name,
+			Format: r.Format,
+			Width:  r.Width,
+			Height: r.Height,
+			URL:    s.mediaUseCase.URL(r.Key),
+		})
+	}
+	return dto
+}
@@ -0,0 +1,386 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+type ProductDTO struct {
+	ID          int64  `json:"id"`
+	SKU         string `json:"sku"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	// Kind is "simple" or "bundle"; GET /products/{id}/bundle has the components of a bundle
+	Kind  entity.ProductKind `json:"kind"`
+	Price PriceDTO           `json:"price"`
+	// CompareAtPrice is the regular price while a sale is live
+	CompareAtPrice *PriceDTO            `json:"compare_at_price,omitempty"`
+	Rating         entity.RatingSummary `json:"rating"`
+	Attributes     entity.Attributes    `json:"attributes"`
+	Media          []MediaDTO           `json:"media"`
+	Slug           string               `json:"slug"`
+	SEOTitle       string               `json:"seo_title"`
+	SEODescription string               `json:"seo_description"`
+	Status         entity.ProductStatus `json:"status"`
+	PublishedAt    *time.Time           `json:"published_at,omitempty"`
+}
+
+// PriceDTO is a price on the wire, read and written alike: the amount in major units, e.g.
+// {"amount": "12.50", "currency": "USD"}, so a fetched price can be sent back as is
+type PriceDTO struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func toPriceDTO(m money.Money) PriceDTO {
+	return PriceDTO{Amount: m.Decimal(), Currency: m.Currency}
+}
+
+func toPriceDTOPtr(m *money.Money) *PriceDTO {
+
+	if m == nil {
+		return nil
+	}
+	dto := toPriceDTO(*m)
+	return &dto
+}
+
+type CreateProductDTO struct {
+	SKU         string   `json:"sku"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Price       PriceDTO `json:"price"`
+	// values keyed by attribute code, e.g. {"screen_size": 55, "panel": "oled"}
+	Attributes entity.Attributes `json:"attributes"`
+	// Slug is made from the name when empty
+	Slug           string `json:"slug"`
+	SEOTitle       string `json:"seo_title"`
+	SEODescription string `json:"seo_description"`
+	// Bundle makes the product a bundle of other products
+	Bundle *BundleDTO `json:"bundle,omitempty"`
+}
+
+// UpdateProductDTO carries only the fields to change
+type UpdateProductDTO struct {
+	SKU         *string   `json:"sku"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Category    *string   `json:"category"`
+	Price       *PriceDTO `json:"price"`
+	// replaces all the attributes
+	Attributes entity.Attributes `json:"attributes"`
+	// an empty slug is made again from the name; without one, a new name gets a new slug
+	Slug           *string `json:"slug"`
+	SEOTitle       *string `json:"seo_title"`
+	SEODescription *string `json:"seo_description"`
+}
+
+// ListProducts takes ?limit=, ?category=, the attribute filters ?attr.<code>=, ?attr.<code>.min= and
+// ?attr.<code>.max=, which need a category, and the price context ?currency=, ?region= and ?customer_group=
+func (s *Server) ListProducts(w http.ResponseWriter, r *http.Request) {
+
+	limit := defaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, maxListLimit)
+	}
+
+	filters, err := attributeFilters(r.URL.Query())
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	list, err := s.productUseCase.ListProducts(r.Context(), entity.ListQuery{
+		Category:           r.URL.Query().Get("category"),
+		Attributes:         filters,
+		Limit:              limit,
+		IncludeUnpublished: isStaff(r),
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	ids := make([]int64, len(list))
+	for i, p := range list {
+		ids[i] = p.ID
+	}
+	media, err := s.mediaUseCase.ListMediaByProducts(r.Context(), ids)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	pc := priceContext(r)
+	res := make([]ProductDTO, 0, len(list))
+
+	for i := range list {
+		p := &list[i]
+
+		price, err := s.productUseCase.ResolvePrice(r.Context(), p, pc)
+		if errors.Is(err, entity.ErrPriceNotAvailable) {
+			// not sold in the caller's currency
+			continue
+		}
+		if err != nil {
+			writeUseCaseError(w, err)
+			return
+		}
+
+		res = append(res, s.toProductDTO(p, price, media[p.ID]))
+	}
+
+	writeJSONWithETag(w, r, res)
+}
+
+func (s *Server) GetProduct(w http.ResponseWriter, r *http.Request) {
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	p, err := s.productUseCase.GetProduct(r.Context(), id, isStaff(r))
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	s.writeProduct(w, r, p)
+}
+
+// visibleProduct loads the product of the path, answering 404 for one the caller does not see, like GetProduct
+func (s *Server) visibleProduct(w http.ResponseWriter, r *http.Request) (*entity.Product, bool) {
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return nil, false
+	}
+
+	p, err := s.productUseCase.GetProduct(r.Context(), productID, isStaff(r))
+	if err != nil {
+		writeUseCaseError(w, err)
+		return nil, false
+	}
+	return p, true
+}
+
+// GetProductBySlug answers like GetProduct, or for a slug the product had, redirects to its current slug
+func (s *Server) GetProductBySlug(w http.ResponseWriter, r *http.Request) {
+
+	slug := mux.Vars(r)["slug"]
+
+	p, err := s.productUseCase.GetProductBySlug(r.Context(), slug, isStaff(r))
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	if p.Slug != slug {
+		target := url.URL{Path: "/products/by-slug/" + p.Slug, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	s.writeProduct(w, r, p)
+}
+
+func (s *Server) writeProduct(w http.ResponseWriter, r *http.Request, p *entity.Product) {
+
+	price, err := s.productUseCase.ResolvePrice(r.Context(), p, priceContext(r))
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	media, err := s.mediaUseCase.ListMedia(r.Context(), p.ID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSONWithETag(w, r, s.toProductDTO(p, price, media))
+}
+
+func (s *Server) CreateProduct(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	var dto CreateProductDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	price, err := money.Parse(dto.Price.Amount, dto.Price.Currency)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	id, err := s.productUseCase.CreateProduct(r.Context(), usecase.CreateProductInput{
+		SKU:            dto.SKU,
+		Name:           dto.Name,
+		Description:    dto.Description,
+		Category:       dto.Category,
+		Price:          price,
+		Attributes:     dto.Attributes,
+		Slug:           dto.Slug,
+		SEOTitle:       dto.SEOTitle,
+		SEODescription: dto.SEODescription,
+		Bundle:         dto.Bundle.toInput(),
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	p, err := s.productUseCase.GetByProductId(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/products/"+strconv.FormatInt(id, 10))
+	writeJSON(w, http.StatusCreated, s.toProductDTO(p, p.Price, nil))
+}
+
+func (s *Server) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var dto UpdateProductDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	input := usecase.UpdateProductInput{
+		SKU:            dto.SKU,
+		Name:           dto.Name,
+		Description:    dto.Description,
+		Category:       dto.Category,
+		Attributes:     dto.Attributes,
+		Slug:           dto.Slug,
+		SEOTitle:       dto.SEOTitle,
+		SEODescription: dto.SEODescription,
+	}
+	if dto.Price != nil {
+		price, err := money.Parse(dto.Price.Amount, dto.Price.Currency)
+		if err != nil {
+			writeUseCaseError(w, err)
+			return
+		}
+		input.Price = &price
+	}
+
+	p, err := s.productUseCase.UpdateProduct(r.Context(), id, input)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	media, err := s.mediaUseCase.ListMedia(r.Context(), p.ID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.toProductDTO(p, p.Price, media))
+}
+
+// DeleteProduct removes the product; its media rows go with it and the stored files are released afterwards
+func (s *Server) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	media, err := s.mediaUseCase.ListMedia(r.Context(), id)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	if err := s.productUseCase.DeleteProduct(r.Context(), id); err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	s.mediaUseCase.ReleaseBlobs(r.Context(), media)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func priceContext(r *http.Request) entity.PriceContext {
+
+	q := r.URL.Query()
+	return entity.PriceContext{
+		Currency:      q.Get("currency"),
+		Region:        q.Get("region"),
+		CustomerGroup: q.Get("customer_group"),
+		At:            time.Now(),
+	}
+}
+
+func (s *Server) toProductDTO(p *entity.Product, price money.Money, media []entity.ProductMedia) ProductDTO {
+
+	dto := ProductDTO{
+		ID:             p.ID,
+		SKU:            p.SKU,
+		Name:           p.Name,
+		Description:    p.Description,
+		Category:       p.Category,
+		Kind:           p.Kind,
+		Price:          toPriceDTO(price),
+		Rating:         p.Rating,
+		Attributes:     p.Attributes,
+		Media:          make([]MediaDTO, 0, len(media)),
+		Slug:           p.Slug,
+		SEOTitle:       p.SEOTitle,
+		SEODescription: p.SEODescription,
+		Status:         p.Status,
+		PublishedAt:    p.PublishedAt,
+	}
+	if dto.Attributes == nil {
+		dto.Attributes = entity.Attributes{}
+	}
+	// the compare-at price goes with the base price, not with list or converted prices
+	if p.CompareAtPrice != nil && price == p.Price {
+		dto.CompareAtPrice = toPriceDTOPtr(p.CompareAtPrice)
+	}
+	for i := range media {
+		dto.Media = append(dto.Media, s.toMediaDTO(&media[i]))
+	}
+	return dto
+}
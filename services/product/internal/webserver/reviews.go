@@ -0,0 +1,172 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+)
+
+type SubmitReviewDTO struct {
+	Rating int    `json:"rating"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+type ReviewListDTO struct {
+	Reviews       []entity.Review      `json:"reviews"`
+	Rating        entity.RatingSummary `json:"rating"`
+	NextPageToken string               `json:"next_page_token,omitempty"`
+}
+
+// ListReviews takes ?sort=recent|helpful, ?limit= and ?page_token= from a previous response
+func (s *Server) ListReviews(w http.ResponseWriter, r *http.Request) {
+
+	limit, offset, ok := page(w, r)
+	if !ok {
+		return
+	}
+
+	p, ok := s.visibleProduct(w, r)
+	if !ok {
+		return
+	}
+
+	list, more, err := s.reviewUseCase.ListReviews(r.Context(), p.ID, r.URL.Query().Get("sort"), limit, offset)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := ReviewListDTO{Reviews: list, Rating: p.Rating}
+	if more {
+		res.NextPageToken = strconv.Itoa(offset + len(list))
+	}
+
+	writeJSONWithETag(w, r, res)
+}
+
+func (s *Server) SubmitReview(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	caller, _ := PrincipalFromContext(r.Context())
+
+	var dto SubmitReviewDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	rv, err := s.reviewUseCase.SubmitReview(r.Context(), usecase.SubmitReviewInput{
+		ProductID: productID,
+		UserID:    caller.UserID,
+		Rating:    dto.Rating,
+		Title:     dto.Title,
+		Body:      dto.Body,
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rv)
+}
+
+func (s *Server) VoteReviewHelpful(w http.ResponseWriter, r *http.Request) {
+
+	reviewID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+	caller, _ := PrincipalFromContext(r.Context())
+
+	if err := s.reviewUseCase.VoteHelpful(r.Context(), reviewID, caller.UserID); err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReviewQueue lists reviews awaiting moderation, or in the state given by ?status=
+func (s *Server) ReviewQueue(w http.ResponseWriter, r *http.Request) {
+
+	limit, offset, ok := page(w, r)
+	if !ok {
+		return
+	}
+
+	list, more, err := s.reviewUseCase.ModerationQueue(r.Context(), entity.ReviewStatus(r.URL.Query().Get("status")), limit, offset)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	res := ReviewListDTO{Reviews: list}
+	if more {
+		res.NextPageToken = strconv.Itoa(offset + len(list))
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ModerateReview takes {"status": "approved"} or {"status": "rejected"}
+func (s *Server) ModerateReview(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	reviewID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	var payload struct {
+		Status entity.ReviewStatus `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	rv, err := s.reviewUseCase.ModerateReview(r.Context(), reviewID, payload.Status)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rv)
+}
+
+// page reads ?limit= and ?page_token=, answering 400 when they are not numbers
+func page(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if v := q.Get("page_token"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid page token")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
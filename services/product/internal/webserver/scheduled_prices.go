@@ -0,0 +1,119 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+// SchedulePriceDTO schedules a base price change; with ends_at it is a sale, e.g.
+// {"price": {"amount": "9.99", "currency": "USD"}, "starts_at": "2025-11-28T00:00:00Z", "ends_at": "2025-12-01T00:00:00Z"}
+type SchedulePriceDTO struct {
+	Price    PriceDTO   `json:"price"`
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+type ScheduledPriceDTO struct {
+	ID            int64                       `json:"id"`
+	ProductID     int64                       `json:"product_id"`
+	Price         PriceDTO                    `json:"price"`
+	StartsAt      time.Time                   `json:"starts_at"`
+	EndsAt        *time.Time                  `json:"ends_at,omitempty"`
+	Status        entity.ScheduledPriceStatus `json:"status"`
+	PreviousPrice *PriceDTO                   `json:"previous_price,omitempty"`
+	CreatedAt     time.Time                   `json:"created_at"`
+}
+
+func toScheduledPriceDTO(sp *entity.ScheduledPrice) ScheduledPriceDTO {
+
+	return ScheduledPriceDTO{
+		ID:            sp.ID,
+		ProductID:     sp.ProductID,
+		Price:         toPriceDTO(sp.Price),
+		StartsAt:      sp.StartsAt,
+		EndsAt:        sp.EndsAt,
+		Status:        sp.Status,
+		PreviousPrice: toPriceDTOPtr(sp.PreviousPrice),
+		CreatedAt:     sp.CreatedAt,
+	}
+}
+
+func (s *Server) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var dto SchedulePriceDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	price, err := money.Parse(dto.Price.Amount, dto.Price.Currency)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	sp, err := s.productUseCase.SchedulePrice(r.Context(), productID, usecase.SchedulePriceInput{
+		Price:    price,
+		StartsAt: dto.StartsAt,
+		EndsAt:   dto.EndsAt,
+	})
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toScheduledPriceDTO(sp))
+}
+
+func (s *Server) ListScheduledPrices(w http.ResponseWriter, r *http.Request) {
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	list, err := s.productUseCase.ListScheduledPrices(r.Context(), productID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	res := make([]ScheduledPriceDTO, len(list))
+	for i := range list {
+		res[i] = toScheduledPriceDTO(&list[i])
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// CancelScheduledPrice withdraws a change that has not started; live sales run until they end
+func (s *Server) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid scheduled price id")
+		return
+	}
+
+	if err := s.productUseCase.CancelScheduledPrice(r.Context(), id); err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
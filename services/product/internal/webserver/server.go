@@ -0,0 +1,231 @@
+package webserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/config"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/entity"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/media"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/usecase"
+	"github.com/raulsilva-tech/e-commerce/services/product/pkg/money"
+)
+
+type Server struct {
+	cfg            config.Config
+	productUseCase usecase.ProductUseCase
+	mediaUseCase   usecase.MediaUseCase
+	reviewUseCase  usecase.ReviewUseCase
+}
+
+func NewServer(cfg config.Config, uc usecase.ProductUseCase, media usecase.MediaUseCase, reviews usecase.ReviewUseCase) (http.Handler, error) {
+
+	s := &Server{
+		cfg:            cfg,
+		productUseCase: uc,
+		mediaUseCase:   media,
+		reviewUseCase:  reviews,
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/health", s.healthHandler).Methods("GET")
+
+	// storefront
+	// staff tokens also see draft, archived and not yet published products
+	r.Handle("/products", s.optionalJWT(http.HandlerFunc(s.ListProducts))).Methods("GET")
+	r.Handle("/products/{id:[0-9]+}", s.optionalJWT(http.HandlerFunc(s.GetProduct))).Methods("GET")
+	r.Handle("/products/by-slug/{slug}", s.optionalJWT(http.HandlerFunc(s.GetProductBySlug))).Methods("GET")
+	r.Handle("/products/{id:[0-9]+}/media", s.optionalJWT(http.HandlerFunc(s.ListMedia))).Methods("GET")
+	r.Handle("/products/{id:[0-9]+}/reviews", s.optionalJWT(http.HandlerFunc(s.ListReviews))).Methods("GET")
+	r.Handle("/products/{id:[0-9]+}/bundle", s.optionalJWT(http.HandlerFunc(s.GetBundle))).Methods("GET")
+	r.HandleFunc("/sitemap.xml", s.SitemapIndex).Methods("GET")
+	r.HandleFunc("/sitemaps/categories.xml", s.CategorySitemap).Methods("GET")
+	r.HandleFunc("/sitemaps/products-{page:[0-9]+}.xml", s.ProductSitemap).Methods("GET")
+	r.Handle("/products/{id:[0-9]+}/availability", s.optionalJWT(http.HandlerFunc(s.GetAvailability))).Methods("GET")
+	r.HandleFunc("/categories/{category}/attributes", s.ListAttributeDefinitions).Methods("GET")
+
+	// signed in customers
+	r.Handle("/products/{id:[0-9]+}/reviews", s.jwtMiddleware(http.HandlerFunc(s.SubmitReview))).Methods("POST")
+	r.Handle("/reviews/{id:[0-9]+}/helpful", s.jwtMiddleware(http.HandlerFunc(s.VoteReviewHelpful))).Methods("POST")
+
+	// admin, staff tokens only
+	admin := func(h http.HandlerFunc) http.Handler {
+		return s.jwtMiddleware(s.requireStaff(h))
+	}
+	r.Handle("/products", admin(s.CreateProduct)).Methods("POST")
+	r.Handle("/products/{id:[0-9]+}", admin(s.UpdateProduct)).Methods("PATCH")
+	r.Handle("/products/{id:[0-9]+}", admin(s.DeleteProduct)).Methods("DELETE")
+	r.Handle("/products/{id:[0-9]+}/status", admin(s.SetProductStatus)).Methods("PUT")
+	r.Handle("/products/{id:[0-9]+}/status-history", admin(s.ListStatusChanges)).Methods("GET")
+
+	r.Handle("/products/import", admin(s.ImportProducts)).Methods("POST")
+	r.Handle("/products/export", admin(s.ExportProducts)).Methods("GET")
+
+	r.Handle("/products/{id:[0-9]+}/stock", admin(s.GetStock)).Methods("GET")
+	r.Handle("/products/{id:[0-9]+}/stock/adjustments", admin(s.AdjustStock)).Methods("POST")
+	r.Handle("/products/{id:[0-9]+}/bundle", admin(s.SetBundle)).Methods("PUT")
+
+	r.Handle("/products/{id:[0-9]+}/scheduled-prices", admin(s.SchedulePrice)).Methods("POST")
+	r.Handle("/products/{id:[0-9]+}/scheduled-prices", admin(s.ListScheduledPrices)).Methods("GET")
+	r.Handle("/scheduled-prices/{id:[0-9]+}", admin(s.CancelScheduledPrice)).Methods("DELETE")
+
+	r.Handle("/products/{id:[0-9]+}/media", admin(s.UploadMedia)).Methods("POST")
+	r.Handle("/products/{id:[0-9]+}/media/order", admin(s.ReorderMedia)).Methods("PUT")
+	r.Handle("/products/{id:[0-9]+}/media/{mediaId:[0-9]+}", admin(s.DeleteMedia)).Methods("DELETE")
+
+	r.Handle("/attributes", admin(s.CreateAttributeDefinition)).Methods("POST")
+
+	r.Handle("/reviews", admin(s.ReviewQueue)).Methods("GET")
+	r.Handle("/reviews/{id:[0-9]+}/status", admin(s.ModerateReview)).Methods("PUT")
+
+	// files of the local blob store
+	if s.cfg.MediaDir != "" {
+		fs := http.FileServer(http.Dir(s.cfg.MediaDir))
+		r.PathPrefix("/media/").Handler(http.StripPrefix("/media/", fs))
+	}
+
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "route not found")
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	})
+
+	return r, nil
+}
+
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// ---------------- Responses ----------------
+
+type ErrorBodyDTO struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorDTO is the envelope of every error response: {"error": {"code": "not_found", "message": "..."}}
+type ErrorDTO struct {
+	Error ErrorBodyDTO `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+
+	code := strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	writeJSON(w, status, ErrorDTO{Error: ErrorBodyDTO{Code: code, Message: message}})
+}
+
+// writeJSONWithETag answers 304 when the client already holds the representation named in If-None-Match
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v any) {
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+func etagMatches(header, etag string) bool {
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeUseCaseError maps domain errors to HTTP statuses, hiding unexpected errors behind a 500
+func writeUseCaseError(w http.ResponseWriter, err error) {
+
+	switch {
+	case errors.Is(err, entity.ErrProductNotFound),
+		errors.Is(err, entity.ErrMediaNotFound),
+		errors.Is(err, entity.ErrReviewNotFound),
+		errors.Is(err, entity.ErrScheduledPriceNotFound),
+		errors.Is(err, entity.ErrNotABundle),
+		errors.Is(err, entity.ErrReviewNotPublished),
+		errors.Is(err, entity.ErrPriceNotAvailable):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrSKUAlreadyExists),
+		errors.Is(err, entity.ErrInsufficientStock),
+		errors.Is(err, entity.ErrReviewAlreadyExists),
+		errors.Is(err, entity.ErrAttributeAlreadyExists),
+		errors.Is(err, entity.ErrScheduledPriceNotPending),
+		errors.Is(err, entity.ErrSaleOverlaps),
+		errors.Is(err, entity.ErrComponentInUse),
+		errors.Is(err, entity.ErrSlugTaken),
+		errors.Is(err, entity.ErrStatusChanged),
+		errors.Is(err, entity.ErrBundleStockIsComputed):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrPurchaseRequired), errors.Is(err, entity.ErrCannotVoteOwnReview):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, entity.ErrInvalidRating),
+		errors.Is(err, entity.ErrReviewBodyIsRequired),
+		errors.Is(err, entity.ErrInvalidReviewStatus),
+		errors.Is(err, entity.ErrSKUIsRequired),
+		errors.Is(err, entity.ErrNameIsRequired),
+		errors.Is(err, entity.ErrNegativePrice),
+		errors.Is(err, entity.ErrMediaOrderMismatch),
+		errors.Is(err, entity.ErrInvalidAttributeCode),
+		errors.Is(err, entity.ErrInvalidAttributeType),
+		errors.Is(err, entity.ErrAttributeOptionsRequired),
+		errors.Is(err, entity.ErrUnknownAttribute),
+		errors.Is(err, entity.ErrInvalidAttributeValue),
+		errors.Is(err, entity.ErrAttributeRequired),
+		errors.Is(err, entity.ErrFilterCategoryRequired),
+		errors.Is(err, entity.ErrInvalidSaleWindow),
+		errors.Is(err, entity.ErrSaleAlreadyEnded),
+		errors.Is(err, entity.ErrInvalidSlug),
+		errors.Is(err, entity.ErrInvalidProductStatus),
+		errors.Is(err, entity.ErrPublishNeedsActive),
+		errors.Is(err, entity.ErrSEOTitleTooLong),
+		errors.Is(err, entity.ErrSEODescriptionTooLong),
+		errors.Is(err, entity.ErrBundleNeedsComponents),
+		errors.Is(err, entity.ErrInvalidComponentQty),
+		errors.Is(err, entity.ErrDuplicateComponent),
+		errors.Is(err, entity.ErrNestedBundle),
+		errors.Is(err, entity.ErrInvalidBundlePricing),
+		errors.Is(err, entity.ErrInvalidPercentOff),
+		errors.Is(err, entity.ErrComponentNotFound),
+		errors.Is(err, money.ErrInvalidCurrency),
+		errors.Is(err, money.ErrInvalidAmount),
+		errors.Is(err, money.ErrCurrencyMismatch):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrMediaTooLarge), errors.Is(err, media.ErrImageTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, media.ErrUnsupportedImage):
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+	default:
+		log.Printf("error: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
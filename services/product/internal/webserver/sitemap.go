@@ -0,0 +1,94 @@
+package webserver
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/raulsilva-tech/e-commerce/services/product/internal/sitemap"
+)
+
+// SitemapIndex lists the category sitemap and as many product sitemaps as it takes to hold every product
+func (s *Server) SitemapIndex(w http.ResponseWriter, r *http.Request) {
+
+	pages, err := s.productUseCase.SitemapProductPages(r.Context())
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	base := strings.TrimRight(s.cfg.SitemapURL, "/")
+	locs := []string{base + "/categories.xml"}
+	for page := 1; page <= pages; page++ {
+		locs = append(locs, fmt.Sprintf("%s/products-%d.xml", base, page))
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	if err := sitemap.WriteIndex(w, locs); err != nil {
+		log.Printf("sitemap index: %v", err)
+	}
+}
+
+func (s *Server) CategorySitemap(w http.ResponseWriter, r *http.Request) {
+
+	categories, err := s.productUseCase.SitemapCategories(r.Context())
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	set, err := sitemap.NewURLSet(w)
+	if err != nil {
+		return
+	}
+	for _, c := range categories {
+		if err := set.Add(s.siteURL("categories", c)); err != nil {
+			log.Printf("category sitemap: %v", err)
+			return
+		}
+	}
+	set.Close()
+}
+
+// ProductSitemap streams the product links of one page of the index
+func (s *Server) ProductSitemap(w http.ResponseWriter, r *http.Request) {
+
+	page, err := strconv.Atoi(mux.Vars(r)["page"])
+	if err != nil || page < 1 {
+		writeError(w, http.StatusNotFound, "sitemap not found")
+		return
+	}
+	pages, err := s.productUseCase.SitemapProductPages(r.Context())
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+	if page > pages {
+		writeError(w, http.StatusNotFound, "sitemap not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	set, err := sitemap.NewURLSet(w)
+	if err != nil {
+		return
+	}
+	err = s.productUseCase.EachSitemapProduct(r.Context(), page, func(slug string) error {
+		return set.Add(s.siteURL("products", slug))
+	})
+	if err != nil {
+		// headers are already sent, the truncated body is all we can signal
+		log.Printf("product sitemap %d: %v", page, err)
+		return
+	}
+	set.Close()
+}
+
+func (s *Server) siteURL(section, name string) string {
+	return strings.TrimRight(s.cfg.SiteURL, "/") + "/" + section + "/" + url.PathEscape(name)
+}
@@ -0,0 +1,71 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *Server) GetStock(w http.ResponseWriter, r *http.Request) {
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	level, err := s.productUseCase.GetStock(r.Context(), productID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, level)
+}
+
+// AdjustStock takes {"delta": n}; a negative delta that would leave less than zero on hand is rejected with 409
+func (s *Server) AdjustStock(w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+
+	productID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	var payload struct {
+		Delta int64 `json:"delta"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid input data: "+err.Error())
+		return
+	}
+
+	level, err := s.productUseCase.AdjustStock(r.Context(), productID, payload.Delta)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, level)
+}
+
+// GetAvailability returns {"available": n}, the units that can be sold now; for a bundle, how many its components make
+func (s *Server) GetAvailability(w http.ResponseWriter, r *http.Request) {
+
+	p, ok := s.visibleProduct(w, r)
+	if !ok {
+		return
+	}
+
+	available, err := s.productUseCase.GetAvailability(r.Context(), p.ID)
+	if err != nil {
+		writeUseCaseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int64{"available": available})
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS attribute_definitions(
+    id SERIAL PRIMARY KEY,
+    category TEXT NOT NULL,
+    code TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL CHECK (type IN ('string', 'number', 'enum', 'bool')),
+    unit TEXT NOT NULL DEFAULT '',
+    -- allowed values of enum attributes
+    options JSONB NOT NULL DEFAULT '[]',
+    required BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE (category, code)
+);
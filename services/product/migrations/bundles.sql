@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS bundles(
+    product_id integer PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    pricing TEXT NOT NULL CHECK (pricing IN ('fixed', 'percent_off')),
+    percent_off integer NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100)
+);
+
+CREATE TABLE IF NOT EXISTS bundle_components(
+    bundle_id integer NOT NULL REFERENCES bundles(product_id) ON DELETE CASCADE,
+    -- a product cannot be deleted while a bundle uses it
+    component_id integer NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    position integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (bundle_id, component_id)
+);
+
+CREATE INDEX IF NOT EXISTS bundle_components_component_idx ON bundle_components (component_id);
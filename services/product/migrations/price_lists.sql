@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS price_lists(
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    customer_group TEXT NOT NULL DEFAULT '',
+    priority integer NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    ends_at TIMESTAMP WITH TIME ZONE,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS price_lists_lookup_idx ON price_lists (currency, region, customer_group);
+
+CREATE TABLE IF NOT EXISTS price_list_items(
+    price_list_id integer NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price NUMERIC(19,4) NOT NULL CHECK (price >= 0),
+    PRIMARY KEY (price_list_id, product_id)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS product_media(
+    id SERIAL PRIMARY KEY,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_sku TEXT NOT NULL DEFAULT '',
+    position integer NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    content_type TEXT NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    original_key TEXT NOT NULL,
+    renditions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS product_media_product_idx ON product_media (product_id, position);
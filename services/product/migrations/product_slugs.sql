@@ -0,0 +1,9 @@
+-- every slug a product has had, its current one included; a slug is never given to another product,
+-- so links to an old slug redirect to the product's current one
+CREATE TABLE IF NOT EXISTS product_slugs(
+    slug TEXT PRIMARY KEY,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS product_slugs_product_idx ON product_slugs (product_id);
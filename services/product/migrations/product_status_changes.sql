@@ -0,0 +1,13 @@
+-- audit of product status changes: who published, unpublished or archived a product and when
+CREATE TABLE IF NOT EXISTS product_status_changes(
+    id SERIAL PRIMARY KEY,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    -- user id of the staff member from the auth service, 0 for internal callers
+    changed_by integer NOT NULL DEFAULT 0,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS product_status_changes_product_idx ON product_status_changes (product_id, changed_at);
@@ -1,5 +1,42 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE IF NOT EXISTS products(
     id SERIAL PRIMARY KEY,
+    sku TEXT NOT NULL UNIQUE,
     name TEXT NOT NULL,
-    price DOUBLE PRECISION
-);
\ No newline at end of file
+    description TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    -- bundles take their stock, and with percent_off pricing their price, from bundle_components
+    kind TEXT NOT NULL DEFAULT 'simple' CHECK (kind IN ('simple', 'bundle')),
+    price NUMERIC(19,4) NOT NULL DEFAULT 0 CHECK (price >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    -- regular price shown next to a live sale price, set and cleared by the price scheduler
+    compare_at_price NUMERIC(19,4) CHECK (compare_at_price >= 0),
+    -- number of approved reviews per star rating, kept by the review moderation flow
+    rating_1 integer NOT NULL DEFAULT 0,
+    rating_2 integer NOT NULL DEFAULT 0,
+    rating_3 integer NOT NULL DEFAULT 0,
+    rating_4 integer NOT NULL DEFAULT 0,
+    rating_5 integer NOT NULL DEFAULT 0,
+    -- specification values keyed by attribute code, validated against attribute_definitions of the category
+    attributes JSONB NOT NULL DEFAULT '{}',
+    -- current URL slug, set in the transaction that inserts the product; product_slugs keeps the ones it had
+    slug TEXT UNIQUE,
+    seo_title TEXT NOT NULL DEFAULT '',
+    seo_description TEXT NOT NULL DEFAULT '',
+    -- customers only see active products from published_at on; new products start as drafts
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'active', 'archived')),
+    published_at TIMESTAMP WITH TIME ZONE,
+    -- kept current by Postgres on every insert/update, name weighs more than description
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', name), 'A') ||
+        setweight(to_tsvector('simple', description), 'B')
+    ) STORED
+);
+
+CREATE INDEX IF NOT EXISTS products_search_idx ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS products_name_trgm_idx ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS products_category_idx ON products (category);
+CREATE INDEX IF NOT EXISTS products_status_idx ON products (status, published_at);
+-- jsonb_path_ops answers the @> containment used by attribute filters with a smaller index
+CREATE INDEX IF NOT EXISTS products_attributes_idx ON products USING GIN (attributes jsonb_path_ops);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS reviews(
+    id SERIAL PRIMARY KEY,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id bigint NOT NULL,
+    rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    verified_purchase boolean NOT NULL DEFAULT false,
+    helpful_count integer NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    moderated_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (product_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_product_idx ON reviews (product_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS reviews_moderation_idx ON reviews (status, created_at);
+
+CREATE TABLE IF NOT EXISTS review_votes(
+    review_id integer NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id bigint NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (review_id, user_id)
+);
+
+-- completed orders reported by the order service, used to verify reviewers bought the product
+CREATE TABLE IF NOT EXISTS purchases(
+    user_id bigint NOT NULL,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    order_id bigint NOT NULL,
+    purchased_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, product_id, order_id)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS scheduled_prices(
+    id SERIAL PRIMARY KEY,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price NUMERIC(19,4) NOT NULL CHECK (price >= 0),
+    currency CHAR(3) NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- set for sales, which are reverted when they end
+    ends_at TIMESTAMP WITH TIME ZONE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'applied', 'ended', 'cancelled')),
+    -- the base price replaced when the change went live
+    previous_price NUMERIC(19,4),
+    previous_currency CHAR(3),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_prices_product_idx ON scheduled_prices (product_id);
+-- the scheduler polls these
+CREATE INDEX IF NOT EXISTS scheduled_prices_starts_idx ON scheduled_prices (starts_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS scheduled_prices_ends_idx ON scheduled_prices (ends_at) WHERE status = 'active';
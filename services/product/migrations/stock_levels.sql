@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS stock_levels(
+    product_id integer PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    on_hand integer NOT NULL DEFAULT 0 CHECK (on_hand >= 0),
+    -- held for orders, part of on_hand until shipped
+    reserved integer NOT NULL DEFAULT 0 CHECK (reserved >= 0 AND reserved <= on_hand),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- one row per reference, e.g. an order, and product; lets a reservation be released exactly once
+CREATE TABLE IF NOT EXISTS stock_reservations(
+    reference TEXT NOT NULL,
+    product_id integer NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (reference, product_id)
+);
+
+-- goods put back on hand per reference, e.g. a return; a retried restock finds its row
+CREATE TABLE IF NOT EXISTS stock_restocks(
+    reference TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- partial releases done per reference, e.g. the cancelled lines of an order; a retried release finds its row
+CREATE TABLE IF NOT EXISTS stock_releases(
+    reference TEXT NOT NULL,
+    release_id TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (reference, release_id)
+);
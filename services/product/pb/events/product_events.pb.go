@@ -0,0 +1,663 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v4.25.1
+// source: proto/product_events.proto
+
+// Events published by the product service on the "products" topic. Messages are keyed by product id,
+// so every event of a product lands on the same partition in the order it happened. Breaking changes
+// go to a new package version (product.events.v2); within v1 fields are only ever added.
+
+package events
+
+import (
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ProductEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// unique per event, lets consumers drop redeliveries
+	EventId    string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	ProductId  int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ProductEvent_ProductCreated
+	//	*ProductEvent_ProductUpdated
+	//	*ProductEvent_PriceChanged
+	//	*ProductEvent_ProductDeleted
+	//	*ProductEvent_StockLevelChanged
+	Payload       isProductEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductEvent) Reset() {
+	*x = ProductEvent{}
+	mi := &file_proto_product_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductEvent) ProtoMessage() {}
+
+func (x *ProductEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductEvent.ProtoReflect.Descriptor instead.
+func (*ProductEvent) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProductEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ProductEvent) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetPayload() isProductEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetProductCreated() *ProductCreated {
+	if x != nil {
+		if x, ok := x.Payload.(*ProductEvent_ProductCreated); ok {
+			return x.ProductCreated
+		}
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetProductUpdated() *ProductUpdated {
+	if x != nil {
+		if x, ok := x.Payload.(*ProductEvent_ProductUpdated); ok {
+			return x.ProductUpdated
+		}
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetPriceChanged() *PriceChanged {
+	if x != nil {
+		if x, ok := x.Payload.(*ProductEvent_PriceChanged); ok {
+			return x.PriceChanged
+		}
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetProductDeleted() *ProductDeleted {
+	if x != nil {
+		if x, ok := x.Payload.(*ProductEvent_ProductDeleted); ok {
+			return x.ProductDeleted
+		}
+	}
+	return nil
+}
+
+func (x *ProductEvent) GetStockLevelChanged() *StockLevelChanged {
+	if x != nil {
+		if x, ok := x.Payload.(*ProductEvent_StockLevelChanged); ok {
+			return x.StockLevelChanged
+		}
+	}
+	return nil
+}
+
+type isProductEvent_Payload interface {
+	isProductEvent_Payload()
+}
+
+type ProductEvent_ProductCreated struct {
+	ProductCreated *ProductCreated `protobuf:"bytes,10,opt,name=product_created,json=productCreated,proto3,oneof"`
+}
+
+type ProductEvent_ProductUpdated struct {
+	ProductUpdated *ProductUpdated `protobuf:"bytes,11,opt,name=product_updated,json=productUpdated,proto3,oneof"`
+}
+
+type ProductEvent_PriceChanged struct {
+	PriceChanged *PriceChanged `protobuf:"bytes,12,opt,name=price_changed,json=priceChanged,proto3,oneof"`
+}
+
+type ProductEvent_ProductDeleted struct {
+	ProductDeleted *ProductDeleted `protobuf:"bytes,13,opt,name=product_deleted,json=productDeleted,proto3,oneof"`
+}
+
+type ProductEvent_StockLevelChanged struct {
+	StockLevelChanged *StockLevelChanged `protobuf:"bytes,14,opt,name=stock_level_changed,json=stockLevelChanged,proto3,oneof"`
+}
+
+func (*ProductEvent_ProductCreated) isProductEvent_Payload() {}
+
+func (*ProductEvent_ProductUpdated) isProductEvent_Payload() {}
+
+func (*ProductEvent_PriceChanged) isProductEvent_Payload() {}
+
+func (*ProductEvent_ProductDeleted) isProductEvent_Payload() {}
+
+func (*ProductEvent_StockLevelChanged) isProductEvent_Payload() {}
+
+// ProductSnapshot is the state of a product after the change
+type ProductSnapshot struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Sku         string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Category    string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	Price       *pb.Money              `protobuf:"bytes,5,opt,name=price,proto3" json:"price,omitempty"`
+	Attributes  *structpb.Struct       `protobuf:"bytes,6,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	// "draft", "active" or "archived"; consumers showing products to customers also check published_at
+	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	PublishedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductSnapshot) Reset() {
+	*x = ProductSnapshot{}
+	mi := &file_proto_product_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductSnapshot) ProtoMessage() {}
+
+func (x *ProductSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductSnapshot.ProtoReflect.Descriptor instead.
+func (*ProductSnapshot) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProductSnapshot) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ProductSnapshot) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProductSnapshot) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ProductSnapshot) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ProductSnapshot) GetPrice() *pb.Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *ProductSnapshot) GetAttributes() *structpb.Struct {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *ProductSnapshot) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProductSnapshot) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+type ProductCreated struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *ProductSnapshot       `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductCreated) Reset() {
+	*x = ProductCreated{}
+	mi := &file_proto_product_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductCreated) ProtoMessage() {}
+
+func (x *ProductCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductCreated.ProtoReflect.Descriptor instead.
+func (*ProductCreated) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProductCreated) GetProduct() *ProductSnapshot {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type ProductUpdated struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Product *ProductSnapshot       `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	// names of the fields that changed, e.g. "name", "price"; empty when unknown, as for bulk imports
+	ChangedFields []string `protobuf:"bytes,2,rep,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductUpdated) Reset() {
+	*x = ProductUpdated{}
+	mi := &file_proto_product_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductUpdated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductUpdated) ProtoMessage() {}
+
+func (x *ProductUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductUpdated.ProtoReflect.Descriptor instead.
+func (*ProductUpdated) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProductUpdated) GetProduct() *ProductSnapshot {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *ProductUpdated) GetChangedFields() []string {
+	if x != nil {
+		return x.ChangedFields
+	}
+	return nil
+}
+
+// PriceChanged is sent for the base price and for price list prices
+type PriceChanged struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 for the base price
+	PriceListId int64 `protobuf:"varint,1,opt,name=price_list_id,json=priceListId,proto3" json:"price_list_id,omitempty"`
+	// unset when the previous price is unknown or there was none
+	OldPrice      *pb.Money `protobuf:"bytes,2,opt,name=old_price,json=oldPrice,proto3" json:"old_price,omitempty"`
+	NewPrice      *pb.Money `protobuf:"bytes,3,opt,name=new_price,json=newPrice,proto3" json:"new_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceChanged) Reset() {
+	*x = PriceChanged{}
+	mi := &file_proto_product_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceChanged) ProtoMessage() {}
+
+func (x *PriceChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceChanged.ProtoReflect.Descriptor instead.
+func (*PriceChanged) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PriceChanged) GetPriceListId() int64 {
+	if x != nil {
+		return x.PriceListId
+	}
+	return 0
+}
+
+func (x *PriceChanged) GetOldPrice() *pb.Money {
+	if x != nil {
+		return x.OldPrice
+	}
+	return nil
+}
+
+func (x *PriceChanged) GetNewPrice() *pb.Money {
+	if x != nil {
+		return x.NewPrice
+	}
+	return nil
+}
+
+type ProductDeleted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sku           string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductDeleted) Reset() {
+	*x = ProductDeleted{}
+	mi := &file_proto_product_events_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductDeleted) ProtoMessage() {}
+
+func (x *ProductDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductDeleted.ProtoReflect.Descriptor instead.
+func (*ProductDeleted) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProductDeleted) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type StockLevelChanged struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PreviousOnHand int64                  `protobuf:"varint,1,opt,name=previous_on_hand,json=previousOnHand,proto3" json:"previous_on_hand,omitempty"`
+	OnHand         int64                  `protobuf:"varint,2,opt,name=on_hand,json=onHand,proto3" json:"on_hand,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StockLevelChanged) Reset() {
+	*x = StockLevelChanged{}
+	mi := &file_proto_product_events_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockLevelChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockLevelChanged) ProtoMessage() {}
+
+func (x *StockLevelChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_events_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockLevelChanged.ProtoReflect.Descriptor instead.
+func (*StockLevelChanged) Descriptor() ([]byte, []int) {
+	return file_proto_product_events_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StockLevelChanged) GetPreviousOnHand() int64 {
+	if x != nil {
+		return x.PreviousOnHand
+	}
+	return 0
+}
+
+func (x *StockLevelChanged) GetOnHand() int64 {
+	if x != nil {
+		return x.OnHand
+	}
+	return 0
+}
+
+var File_proto_product_events_proto protoreflect.FileDescriptor
+
+const file_proto_product_events_proto_rawDesc = "" +
+	"\n" +
+	"\x1aproto/product_events.proto\x12\x11product.events.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x13proto/product.proto\"\x9a\x04\n" +
+	"\fProductEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12L\n" +
+	"\x0fproduct_created\x18\n" +
+	" \x01(\v2!.product.events.v1.ProductCreatedH\x00R\x0eproductCreated\x12L\n" +
+	"\x0fproduct_updated\x18\v \x01(\v2!.product.events.v1.ProductUpdatedH\x00R\x0eproductUpdated\x12F\n" +
+	"\rprice_changed\x18\f \x01(\v2\x1f.product.events.v1.PriceChangedH\x00R\fpriceChanged\x12L\n" +
+	"\x0fproduct_deleted\x18\r \x01(\v2!.product.events.v1.ProductDeletedH\x00R\x0eproductDeleted\x12V\n" +
+	"\x13stock_level_changed\x18\x0e \x01(\v2$.product.events.v1.StockLevelChangedH\x00R\x11stockLevelChangedB\t\n" +
+	"\apayload\"\xab\x02\n" +
+	"\x0fProductSnapshot\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\x12$\n" +
+	"\x05price\x18\x05 \x01(\v2\x0e.product.MoneyR\x05price\x127\n" +
+	"\n" +
+	"attributes\x18\x06 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"attributes\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12=\n" +
+	"\fpublished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\"N\n" +
+	"\x0eProductCreated\x12<\n" +
+	"\aproduct\x18\x01 \x01(\v2\".product.events.v1.ProductSnapshotR\aproduct\"u\n" +
+	"\x0eProductUpdated\x12<\n" +
+	"\aproduct\x18\x01 \x01(\v2\".product.events.v1.ProductSnapshotR\aproduct\x12%\n" +
+	"\x0echanged_fields\x18\x02 \x03(\tR\rchangedFields\"\x8c\x01\n" +
+	"\fPriceChanged\x12\"\n" +
+	"\rprice_list_id\x18\x01 \x01(\x03R\vpriceListId\x12+\n" +
+	"\told_price\x18\x02 \x01(\v2\x0e.product.MoneyR\boldPrice\x12+\n" +
+	"\tnew_price\x18\x03 \x01(\v2\x0e.product.MoneyR\bnewPrice\"\"\n" +
+	"\x0eProductDeleted\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\"V\n" +
+	"\x11StockLevelChanged\x12(\n" +
+	"\x10previous_on_hand\x18\x01 \x01(\x03R\x0epreviousOnHand\x12\x17\n" +
+	"\aon_hand\x18\x02 \x01(\x03R\x06onHandBAZ?github.com/raulsilva-tech/e-commerce/services/product/pb/eventsb\x06proto3"
+
+var (
+	file_proto_product_events_proto_rawDescOnce sync.Once
+	file_proto_product_events_proto_rawDescData []byte
+)
+
+func file_proto_product_events_proto_rawDescGZIP() []byte {
+	file_proto_product_events_proto_rawDescOnce.Do(func() {
+		file_proto_product_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_product_events_proto_rawDesc), len(file_proto_product_events_proto_rawDesc)))
+	})
+	return file_proto_product_events_proto_rawDescData
+}
+
+var file_proto_product_events_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_product_events_proto_goTypes = []any{
+	(*ProductEvent)(nil),          // 0: product.events.v1.ProductEvent
+	(*ProductSnapshot)(nil),       // 1: product.events.v1.ProductSnapshot
+	(*ProductCreated)(nil),        // 2: product.events.v1.ProductCreated
+	(*ProductUpdated)(nil),        // 3: product.events.v1.ProductUpdated
+	(*PriceChanged)(nil),          // 4: product.events.v1.PriceChanged
+	(*ProductDeleted)(nil),        // 5: product.events.v1.ProductDeleted
+	(*StockLevelChanged)(nil),     // 6: product.events.v1.StockLevelChanged
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+	(*pb.Money)(nil),              // 8: product.Money
+	(*structpb.Struct)(nil),       // 9: google.protobuf.Struct
+}
+var file_proto_product_events_proto_depIdxs = []int32{
+	7,  // 0: product.events.v1.ProductEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	2,  // 1: product.events.v1.ProductEvent.product_created:type_name -> product.events.v1.ProductCreated
+	3,  // 2: product.events.v1.ProductEvent.product_updated:type_name -> product.events.v1.ProductUpdated
+	4,  // 3: product.events.v1.ProductEvent.price_changed:type_name -> product.events.v1.PriceChanged
+	5,  // 4: product.events.v1.ProductEvent.product_deleted:type_name -> product.events.v1.ProductDeleted
+	6,  // 5: product.events.v1.ProductEvent.stock_level_changed:type_name -> product.events.v1.StockLevelChanged
+	8,  // 6: product.events.v1.ProductSnapshot.price:type_name -> product.Money
+	9,  // 7: product.events.v1.ProductSnapshot.attributes:type_name -> google.protobuf.Struct
+	7,  // 8: product.events.v1.ProductSnapshot.published_at:type_name -> google.protobuf.Timestamp
+	1,  // 9: product.events.v1.ProductCreated.product:type_name -> product.events.v1.ProductSnapshot
+	1,  // 10: product.events.v1.ProductUpdated.product:type_name -> product.events.v1.ProductSnapshot
+	8,  // 11: product.events.v1.PriceChanged.old_price:type_name -> product.Money
+	8,  // 12: product.events.v1.PriceChanged.new_price:type_name -> product.Money
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_proto_product_events_proto_init() }
+func file_proto_product_events_proto_init() {
+	if File_proto_product_events_proto != nil {
+		return
+	}
+	file_proto_product_events_proto_msgTypes[0].OneofWrappers = []any{
+		(*ProductEvent_ProductCreated)(nil),
+		(*ProductEvent_ProductUpdated)(nil),
+		(*ProductEvent_PriceChanged)(nil),
+		(*ProductEvent_ProductDeleted)(nil),
+		(*ProductEvent_StockLevelChanged)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_product_events_proto_rawDesc), len(file_proto_product_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_product_events_proto_goTypes,
+		DependencyIndexes: file_proto_product_events_proto_depIdxs,
+		MessageInfos:      file_proto_product_events_proto_msgTypes,
+	}.Build()
+	File_proto_product_events_proto = out.File
+	file_proto_product_events_proto_goTypes = nil
+	file_proto_product_events_proto_depIdxs = nil
+}
@@ -0,0 +1,262 @@
+// Package client is the Go client other services use to call the product service over gRPC.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+const (
+	defaultPoolSize    = 4
+	defaultTimeout     = 2 * time.Second
+	defaultMaxAttempts = 3
+)
+
+// Options tunes the client; zero values use the defaults
+type Options struct {
+	// PoolSize is the number of connections calls are spread across
+	PoolSize int
+	// Timeout is the deadline of a call including its retries, unless ctx has an earlier one
+	Timeout time.Duration
+	// MaxAttempts bounds the tries of a call that fails with UNAVAILABLE
+	MaxAttempts int
+	// Token returns the bearer token sent with each call, e.g. a service token; calls go without one when nil
+	Token func(ctx context.Context) (string, error)
+}
+
+// Client keeps a pool of connections open for the life of the process; it is safe for concurrent use
+type Client struct {
+	conns   []*grpc.ClientConn
+	stubs   []pb.ProductServiceClient
+	next    atomic.Uint64
+	timeout time.Duration
+}
+
+func New(target string, opts Options) (*Client, error) {
+
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = defaultPoolSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(retryServiceConfig(opts.MaxAttempts)),
+	}
+	if opts.Token != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(tokenCredentials(opts.Token)))
+	}
+
+	c := &Client{timeout: opts.Timeout}
+	for range opts.PoolSize {
+		conn, err := grpc.NewClient(target, dialOpts...)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.conns = append(c.conns, conn)
+		c.stubs = append(c.stubs, pb.NewProductServiceClient(conn))
+	}
+
+	return c, nil
+}
+
+// tokenCredentials sends the token in the "authorization" metadata, as the server expects
+type tokenCredentials func(ctx context.Context) (string, error)
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+
+	tok, err := t(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + tok}, nil
+}
+
+// RequireTransportSecurity is false, the services talk over the internal network in plaintext
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// retryServiceConfig lets grpc retry calls the server never started handling, with backoff
+func retryServiceConfig(maxAttempts int) string {
+	return fmt.Sprintf(`{
+	"methodConfig": [{
+		"name": [{"service": "product.ProductService"}],
+		"retryPolicy": {
+			"maxAttempts": %d,
+			"initialBackoff": "0.05s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`, maxAttempts)
+}
+
+// GetProduct returns ErrProductNotFound when the product does not exist or has no price in pc's currency
+func (c *Client) GetProduct(ctx context.Context, id int64, pc *pb.PriceContext) (*pb.GetProductResponse, error) {
+
+	products, _, err := c.BatchGetProducts(ctx, []int64{id}, pc)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, ErrProductNotFound
+	}
+	return products[0], nil
+}
+
+// BatchGetProducts fetches products in one call, returning those found and the ids that were not
+func (c *Client) BatchGetProducts(ctx context.Context, ids []int64, pc *pb.PriceContext) ([]*pb.GetProductResponse, []int64, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := &pb.BatchGetProductsRequest{Ids: make([]string, len(ids)), PriceContext: pc}
+	for i, id := range ids {
+		req.Ids[i] = strconv.FormatInt(id, 10)
+	}
+
+	resp, err := c.stub().BatchGetProducts(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missing := make([]int64, 0, len(resp.MissingIds))
+	for _, raw := range resp.MissingIds {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("product service returned invalid id %q", raw)
+		}
+		missing = append(missing, id)
+	}
+
+	return resp.Products, missing, nil
+}
+
+// RecordPurchase tells the product service the user bought the products, which lets them review them.
+// The server takes it from services only: Options.Token must return a service token.
+func (c *Client) RecordPurchase(ctx context.Context, userID, orderID int64, productIDs []int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := &pb.RecordPurchaseRequest{
+		UserId:     strconv.FormatInt(userID, 10),
+		OrderId:    strconv.FormatInt(orderID, 10),
+		ProductIds: make([]string, len(productIDs)),
+	}
+	for i, id := range productIDs {
+		req.ProductIds[i] = strconv.FormatInt(id, 10)
+	}
+
+	_, err := c.stub().RecordPurchase(ctx, req)
+	return err
+}
+
+// ReserveStock holds quantity of each product for reference, all or none, and returns ErrInsufficientStock
+// when one is short. Calling it again with the same reference holds nothing more, so it is safe to retry.
+func (c *Client) ReserveStock(ctx context.Context, reference string, quantities map[int64]int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := &pb.ReserveStockRequest{Reference: reference}
+	for id, qty := range quantities {
+		req.Items = append(req.Items, &pb.StockItem{ProductId: strconv.FormatInt(id, 10), Quantity: qty})
+	}
+
+	_, err := c.stub().ReserveStock(ctx, req)
+	if status.Code(err) == codes.FailedPrecondition {
+		return ErrInsufficientStock
+	}
+	return err
+}
+
+// ReleaseStock gives back what reference holds; releasing twice is harmless
+func (c *Client) ReleaseStock(ctx context.Context, reference string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	_, err := c.stub().ReleaseStock(ctx, &pb.ReleaseStockRequest{Reference: reference})
+	return err
+}
+
+// ReleaseStockItems gives back part of what reference holds, quantity of each product. releaseID names
+// the release, so retrying it gives back nothing more.
+func (c *Client) ReleaseStockItems(ctx context.Context, reference, releaseID string, quantities map[int64]int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := &pb.ReleaseStockRequest{Reference: reference, ReleaseId: releaseID}
+	for id, qty := range quantities {
+		req.Items = append(req.Items, &pb.StockItem{ProductId: strconv.FormatInt(id, 10), Quantity: qty})
+	}
+
+	_, err := c.stub().ReleaseStock(ctx, req)
+	return err
+}
+
+// CommitStock takes what reference holds out of stock once its goods left, e.g. a shipped order; committing
+// twice is harmless
+func (c *Client) CommitStock(ctx context.Context, reference string) error {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	_, err := c.stub().CommitStock(ctx, &pb.CommitStockRequest{Reference: reference})
+	return err
+}
+
+// RestockItems puts quantity of each product back on hand, e.g. the goods of a return. reference names the
+// restock, so retrying it adds nothing more.
+func (c *Client) RestockItems(ctx context.Context, reference string, quantities map[int64]int64) error {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req := &pb.RestockItemsRequest{Reference: reference}
+	for id, qty := range quantities {
+		req.Items = append(req.Items, &pb.StockItem{ProductId: strconv.FormatInt(id, 10), Quantity: qty})
+	}
+
+	_, err := c.stub().RestockItems(ctx, req)
+	return err
+}
+
+func (c *Client) Close() error {
+
+	var errs []error
+	for _, conn := range c.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// stub picks the next connection of the pool round robin
+func (c *Client) stub() pb.ProductServiceClient {
+	return c.stubs[c.next.Add(1)%uint64(len(c.stubs))]
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	pb "github.com/raulsilva-tech/e-commerce/services/product/pb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeProductServer struct {
+	pb.UnimplementedProductServiceServer
+	calls       atomic.Int32
+	unavailable int32
+}
+
+func (f *fakeProductServer) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+
+	if f.calls.Add(1) <= f.unavailable {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+
+	resp := &pb.BatchGetProductsResponse{}
+	for _, id := range req.Ids {
+		if id == "1" {
+			resp.Products = append(resp.Products, &pb.GetProductResponse{Id: id, Name: "Mug"})
+		} else {
+			resp.MissingIds = append(resp.MissingIds, id)
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeProductServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+
+	for _, it := range req.Items {
+		if it.Quantity > 5 {
+			return nil, status.Error(codes.FailedPrecondition, "insufficient stock")
+		}
+	}
+	return &pb.ReserveStockResponse{}, nil
+}
+
+func (f *fakeProductServer) RecordPurchase(ctx context.Context, req *pb.RecordPurchaseRequest) (*pb.RecordPurchaseResponse, error) {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) == 0 || values[0] != "Bearer service-token" {
+		return nil, status.Error(codes.Unauthenticated, "missing auth")
+	}
+	return &pb.RecordPurchaseResponse{}, nil
+}
+
+func startServer(t *testing.T, f *fakeProductServer) string {
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	srv := grpc.NewServer()
+	pb.RegisterProductServiceServer(srv, f)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestBatchGetProducts(t *testing.T) {
+
+	c, err := New(startServer(t, &fakeProductServer{}), Options{PoolSize: 2})
+	assert.Nil(t, err)
+	defer c.Close()
+
+	products, missing, err := c.BatchGetProducts(context.Background(), []int64{1, 2}, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, "Mug", products[0].Name)
+	assert.Equal(t, []int64{2}, missing)
+}
+
+func TestGetProductNotFound(t *testing.T) {
+
+	c, _ := New(startServer(t, &fakeProductServer{}), Options{})
+	defer c.Close()
+
+	_, err := c.GetProduct(context.Background(), 2, nil)
+
+	assert.Equal(t, ErrProductNotFound, err)
+}
+
+func TestRetriesUnavailable(t *testing.T) {
+
+	f := &fakeProductServer{unavailable: 2}
+	c, _ := New(startServer(t, f), Options{MaxAttempts: 3})
+	defer c.Close()
+
+	p, err := c.GetProduct(context.Background(), 1, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Mug", p.Name)
+	assert.Equal(t, int32(3), f.calls.Load())
+}
+
+func TestReserveStockInsufficient(t *testing.T) {
+
+	c, _ := New(startServer(t, &fakeProductServer{}), Options{})
+	defer c.Close()
+
+	assert.Nil(t, c.ReserveStock(context.Background(), "order-1", map[int64]int64{1: 5}))
+	assert.Equal(t, ErrInsufficientStock, c.ReserveStock(context.Background(), "order-2", map[int64]int64{1: 6}))
+}
+
+func TestToken(t *testing.T) {
+
+	addr := startServer(t, &fakeProductServer{})
+
+	c, _ := New(addr, Options{Token: func(ctx context.Context) (string, error) { return "service-token", nil }})
+	defer c.Close()
+	assert.Nil(t, c.RecordPurchase(context.Background(), 7, 3, []int64{1}))
+
+	anonymous, _ := New(addr, Options{})
+	defer anonymous.Close()
+	err := anonymous.RecordPurchase(context.Background(), 7, 3, []int64{1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,104 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateTable converts amounts between currencies using rates quoted against a base currency,
+// e.g. base USD with EUR 0.92 means 1 USD = 0.92 EUR
+type RateTable struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+type rateFile struct {
+	Base  string            `json:"base"`
+	Rates map[string]string `json:"rates"`
+}
+
+// LoadRates reads a JSON rate table like {"base":"USD","rates":{"EUR":"0.92","BRL":"5.05"}}
+func LoadRates(r io.Reader) (*RateTable, error) {
+
+	var f rateFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	base := strings.ToUpper(f.Base)
+	if _, ok := exponents[base]; !ok {
+		return nil, ErrInvalidCurrency
+	}
+
+	t := &RateTable{base: base, rates: map[string]*big.Rat{base: big.NewRat(1, 1)}}
+	for currency, value := range f.Rates {
+		currency = strings.ToUpper(currency)
+		if _, ok := exponents[currency]; !ok {
+			return nil, ErrInvalidCurrency
+		}
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, ErrInvalidAmount
+		}
+		t.rates[currency] = rate
+	}
+
+	return t, nil
+}
+
+func LoadRatesFile(path string) (*RateTable, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadRates(f)
+}
+
+// Convert exchanges m into the target currency, rounding half away from zero to its minor unit
+func (t *RateTable) Convert(m Money, to string) (Money, error) {
+
+	to = strings.ToUpper(to)
+	if m.Currency == to {
+		return m, nil
+	}
+
+	from, ok := t.rates[m.Currency]
+	if !ok {
+		return Money{}, ErrRateNotFound
+	}
+	target, ok := t.rates[to]
+	if !ok {
+		return Money{}, ErrRateNotFound
+	}
+
+	// minor(to) = minor(from) / 10^exp(from) / rate(from) * rate(to) * 10^exp(to)
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Quo(v, new(big.Rat).SetInt64(pow10(exponents[m.Currency])))
+	v.Quo(v, from)
+	v.Mul(v, target)
+	v.Mul(v, new(big.Rat).SetInt64(pow10(exponents[to])))
+
+	return Money{Amount: round(v), Currency: to}, nil
+}
+
+func round(v *big.Rat) int64 {
+
+	num := new(big.Int).Abs(v.Num())
+	q, r := new(big.Int).QuoRem(num, v.Denom(), new(big.Int))
+	if new(big.Int).Mul(r, big.NewInt(2)).Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
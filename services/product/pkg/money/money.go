@@ -0,0 +1,193 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// exponents holds the number of minor unit digits of the supported ISO 4217 currencies
+var exponents = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"BRL": 2,
+	"GBP": 2,
+	"CAD": 2,
+	"AUD": 2,
+	"CHF": 2,
+	"MXN": 2,
+	"ARS": 2,
+	"CLP": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+}
+
+// Money is an amount expressed in the minor units (e.g. cents) of an ISO 4217 currency
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) (Money, error) {
+
+	m := Money{Amount: amount, Currency: strings.ToUpper(currency)}
+
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+// Parse reads a decimal string such as "12.34" as an amount of the given currency
+func Parse(value, currency string) (Money, error) {
+
+	currency = strings.ToUpper(currency)
+	exp, ok := exponents[currency]
+	if !ok {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	intPart, fracPart, _ := strings.Cut(value, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	// digits beyond the currency precision must be zeros, e.g. NUMERIC(19,4) "12.3400"
+	if len(fracPart) > exp {
+		if strings.Trim(fracPart[exp:], "0") != "" {
+			return Money{}, ErrInvalidAmount
+		}
+		fracPart = fracPart[:exp]
+	}
+	fracPart += strings.Repeat("0", exp-len(fracPart))
+
+	amount, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// FromUnitsNanos builds a Money from the units/nanos pair used by google.type.Money
+func FromUnitsNanos(currency string, units int64, nanos int32) (Money, error) {
+
+	currency = strings.ToUpper(currency)
+	exp, ok := exponents[currency]
+	if !ok {
+		return Money{}, ErrInvalidCurrency
+	}
+	if (units > 0 && nanos < 0) || (units < 0 && nanos > 0) || nanos <= -1e9 || nanos >= 1e9 {
+		return Money{}, ErrInvalidAmount
+	}
+
+	scale := pow10(exp)
+	nanosPerMinor := int32(1e9 / scale)
+	if nanos%nanosPerMinor != 0 {
+		return Money{}, ErrInvalidAmount
+	}
+	if units > math.MaxInt64/scale || units < math.MinInt64/scale {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Amount: units*scale + int64(nanos/nanosPerMinor), Currency: currency}, nil
+}
+
+// UnitsNanos splits the amount into the units/nanos pair used by google.type.Money
+func (m Money) UnitsNanos() (int64, int32) {
+
+	scale := pow10(exponents[m.Currency])
+	units := m.Amount / scale
+	nanos := int32((m.Amount % scale) * (1e9 / scale))
+
+	return units, nanos
+}
+
+func (m Money) Validate() error {
+
+	if _, ok := exponents[m.Currency]; !ok {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Add(o Money) (Money, error) {
+
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Mul(qty int64) Money {
+	return Money{Amount: m.Amount * qty, Currency: m.Currency}
+}
+
+// PercentOff takes pct percent off the amount, rounding half away from zero to the minor unit
+func (m Money) PercentOff(pct int64) Money {
+	return Money{Amount: round(big.NewRat(m.Amount*(100-pct), 100)), Currency: m.Currency}
+}
+
+// Decimal formats the amount in major units, e.g. 1234 USD -> "12.34"
+func (m Money) Decimal() string {
+
+	exp := exponents[m.Currency]
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if exp == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+
+	scale := pow10(exp)
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, exp, amount%scale)
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func pow10(exp int) int64 {
+
+	v := int64(1)
+	for i := 0; i < exp; i++ {
+		v *= 10
+	}
+	return v
+}
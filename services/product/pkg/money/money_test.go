@@ -0,0 +1,90 @@
+package money
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+
+	m, err := Parse("12.34", "usd")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1234), m.Amount)
+	assert.Equal(t, "USD", m.Currency)
+}
+
+func TestParseNumericScale(t *testing.T) {
+
+	m, err := Parse("12.3400", "BRL")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1234), m.Amount)
+
+	_, err = Parse("12.345", "BRL")
+	assert.Equal(t, ErrInvalidAmount, err)
+}
+
+func TestParseWhenCurrencyIsInvalid(t *testing.T) {
+
+	_, err := Parse("1", "XXX")
+
+	assert.Equal(t, ErrInvalidCurrency, err)
+}
+
+func TestDecimal(t *testing.T) {
+
+	assert.Equal(t, "0.05", Money{Amount: 5, Currency: "USD"}.Decimal())
+	assert.Equal(t, "-12.34", Money{Amount: -1234, Currency: "EUR"}.Decimal())
+	assert.Equal(t, "500", Money{Amount: 500, Currency: "JPY"}.Decimal())
+	assert.Equal(t, "1.005", Money{Amount: 1005, Currency: "KWD"}.Decimal())
+}
+
+func TestUnitsNanos(t *testing.T) {
+
+	m := Money{Amount: 1234, Currency: "USD"}
+
+	units, nanos := m.UnitsNanos()
+	assert.Equal(t, int64(12), units)
+	assert.Equal(t, int32(340000000), nanos)
+
+	m2, err := FromUnitsNanos("USD", units, nanos)
+	assert.Nil(t, err)
+	assert.Equal(t, m, m2)
+}
+
+func TestAddWhenCurrenciesDiffer(t *testing.T) {
+
+	_, err := Money{Amount: 1, Currency: "USD"}.Add(Money{Amount: 1, Currency: "EUR"})
+
+	assert.Equal(t, ErrCurrencyMismatch, err)
+}
+
+func TestPercentOff(t *testing.T) {
+
+	assert.Equal(t, Money{Amount: 1349, Currency: "USD"}, Money{Amount: 1499, Currency: "USD"}.PercentOff(10))
+	assert.Equal(t, Money{Amount: 74, Currency: "JPY"}, Money{Amount: 99, Currency: "JPY"}.PercentOff(25))
+}
+
+func TestConvert(t *testing.T) {
+
+	rates, err := LoadRates(strings.NewReader(`{"base":"USD","rates":{"EUR":"0.92","BRL":"5.05","JPY":"150"}}`))
+	assert.Nil(t, err)
+
+	eur, err := rates.Convert(Money{Amount: 1000, Currency: "USD"}, "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 920, Currency: "EUR"}, eur)
+
+	jpy, err := rates.Convert(Money{Amount: 1999, Currency: "USD"}, "JPY")
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 2999, Currency: "JPY"}, jpy)
+
+	brl, err := rates.Convert(Money{Amount: 920, Currency: "EUR"}, "BRL")
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 5050, Currency: "BRL"}, brl)
+
+	_, err = rates.Convert(Money{Amount: 1, Currency: "USD"}, "GBP")
+	assert.Equal(t, ErrRateNotFound, err)
+}